@@ -1,6 +1,11 @@
 package cmd
 
 import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"github.com/harakeishi/depsee/pkg/depsee"
 	"github.com/spf13/cobra"
 )
@@ -12,6 +17,27 @@ var (
 	targetPackages         string
 	excludePackages        string
 	excludeDirs            string
+	typeCheck              bool
+	collapseMethods        bool
+	includeGlobals         bool
+	includeExternal        bool
+	includeStdlib          bool
+	externalInstability    bool
+	includeTests           bool
+	goos                   string
+	goarch                 string
+	buildTags              string
+	platforms              string
+	generated              string
+	goroutineNodes         bool
+	weighted               bool
+	weights                string
+	workers                int
+	noCache                bool
+	output                 string
+	edgePositions          bool
+	watchMode              bool
+	watchInterval          time.Duration
 )
 
 // analyzeCmd はanalyzeサブコマンドを表します
@@ -31,6 +57,23 @@ var analyzeCmd = &cobra.Command{
   depsee analyze -e test,mock ./src                 # 特定パッケージを除外
   depsee analyze -d testdata,vendor ./src           # 特定ディレクトリを除外
   depsee analyze -s ./src                           # SDP違反をハイライト
+  depsee analyze --type-check ./src                 # go/typesで型チェックして依存関係を解決
+  depsee analyze --collapse-methods ./src           # メソッドをレシーバ型に畳み込んで表示
+  depsee analyze --include-globals ./src            # パッケージレベルの変数・定数への読み書きを表示
+  depsee analyze --include-external ./src           # サードパーティモジュールへの依存をモジュールごとに表示
+  depsee analyze --include-stdlib ./src             # 標準ライブラリのパッケージへの依存も表示
+  depsee analyze --include-tests ./src              # テストを別の層として解析し、テストから参照されていないノードを表示
+  depsee analyze --goos windows --tags integration ./src  # windows向け・integrationタグ付きでビルドされるファイルのみ解析
+  depsee analyze --platforms linux/amd64,windows/amd64 ./src  # 複数プラットフォームを解析し、一部にのみ存在するノードを区別
+  depsee analyze --generated collapse ./src        # 生成コードをパッケージごとに1つのノードにまとめて表示
+  depsee analyze --goroutine-nodes ./src            # ループを含むgoroutineを起動元の関数とは別のノードとして表示
+  depsee analyze --weighted ./src                   # 依存の種類と出現回数で重み付けした不安定度も表示
+  depsee analyze --weights field=5,body_call=0.5 ./src  # 重みを指定して重み付き不安定度を表示
+  depsee analyze --workers 4 ./src                  # 4並列でパース・解析
+  depsee analyze --no-cache ./src                   # キャッシュを使わずに全ファイルを解析
+  depsee analyze -o deps.md ./src                   # Mermaid相関図をMarkdownファイルに出力
+  depsee analyze --edge-positions -o deps.md ./src  # 相関図の下にエッジの出現位置（file:line）の表を出力
+  depsee analyze --watch -o deps.md ./src           # ファイルの変更を監視して出力を更新し続ける
   depsee analyze -p -s -e test ./src                # 複数オプション組み合わせ`,
 	Args: cobra.ExactArgs(1),
 	RunE: runAnalyze,
@@ -45,6 +88,27 @@ func init() {
 	analyzeCmd.Flags().StringVarP(&targetPackages, "target-packages", "t", "", "解析対象とするパッケージ名をカンマ区切りで指定（例: main,cmd）。指定しない場合は全パッケージが対象")
 	analyzeCmd.Flags().StringVarP(&excludePackages, "exclude-packages", "e", "", "解析対象から除外するパッケージ名をカンマ区切りで指定（例: test,mock,vendor）")
 	analyzeCmd.Flags().StringVarP(&excludeDirs, "exclude-dirs", "d", "", "解析対象から除外するディレクトリパスをカンマ区切りで指定（例: testdata,vendor,third_party）")
+	analyzeCmd.Flags().BoolVar(&typeCheck, "type-check", false, "go/typesで型チェックを行い、識別子を型オブジェクトに解決してから依存関係を抽出")
+	analyzeCmd.Flags().BoolVar(&collapseMethods, "collapse-methods", false, "メソッドノードをレシーバ型ノードに畳み込み、型単位の粗い依存関係として表示")
+	analyzeCmd.Flags().BoolVar(&includeGlobals, "include-globals", false, "パッケージレベルの変数・定数をノードとし、関数からの読み書きを依存関係として表示")
+	analyzeCmd.Flags().BoolVar(&includeExternal, "include-external", false, "サードパーティモジュールへの参照を、モジュールごとにまとめた外部依存ノードとして表示（不安定度の算出には含めない）")
+	analyzeCmd.Flags().BoolVar(&includeStdlib, "include-stdlib", false, "標準ライブラリのパッケージへの参照も外部依存ノードとして表示。指定すると --include-external が有効になる")
+	analyzeCmd.Flags().BoolVar(&externalInstability, "external-instability", false, "外部依存ノードを不安定度・SDP違反の算出に含める")
+	analyzeCmd.Flags().BoolVar(&includeTests, "include-tests", false, "テストファイル（_test.go）と外部テストパッケージ（pkg_test）もテストの層として解析し、テストから直接参照されていない本番コードのノードを表示（テストは不安定度の算出には含めない）")
+	analyzeCmd.Flags().StringVar(&goos, "goos", "", "ビルド制約（//go:build 行とファイル名の接尾辞）の評価に使うGOOS（指定しない場合は環境変数GOOSまたは実行環境の値）")
+	analyzeCmd.Flags().StringVar(&goarch, "goarch", "", "ビルド制約の評価に使うGOARCH（指定しない場合は環境変数GOARCHまたは実行環境の値）")
+	analyzeCmd.Flags().StringVar(&buildTags, "tags", "", "ビルド制約の評価で有効とする追加のビルドタグをカンマ区切りで指定（例: integration,netgo）")
+	analyzeCmd.Flags().StringVar(&platforms, "platforms", "", "解析するプラットフォームをGOOS/GOARCHのカンマ区切りで指定（例: linux/amd64,windows/amd64）。いずれかでビルドされるファイルを解析し、一部のプラットフォームにのみ存在するノードにそのプラットフォームを表示する。--goos・--goarchより優先")
+	analyzeCmd.Flags().StringVar(&generated, "generated", depsee.GeneratedKeep, "生成コード（\"// Code generated ... DO NOT EDIT.\" のヘッダを持つファイル）の扱い。keep: そのまま表示、exclude: 解析対象から除外、collapse: パッケージごとに1つのノードにまとめる、mute: Mermaid相関図で控えめなスタイルで表示")
+	analyzeCmd.Flags().BoolVar(&goroutineNodes, "goroutine-nodes", false, "ループやselectを含むgoroutineの関数リテラル（go func() { for { ... } }()）を起動元の関数とは別のノードとし、起動箇所を表示。関数リテラルの中の依存関係は通常は外側の関数・メソッドのものとして扱う")
+	analyzeCmd.Flags().BoolVar(&weighted, "weighted", false, "依存の種類ごとの重みと出現回数から算出した重み付き不安定度を通常の不安定度と併せて表示")
+	analyzeCmd.Flags().StringVar(&weights, "weights", "", "重み付き不安定度の重みを kind=weight のカンマ区切りで上書き（例: field=5,body_call=0.5）。指定すると --weighted が有効になる。kind: underlying, embedding（埋め込み）, field, signature, receiver, constraint, implementation, instantiation, body_call, cross_package, func_value, type_usage, spawn, package, read, write, external")
+	analyzeCmd.Flags().IntVar(&workers, "workers", 0, "パース・解析・依存関係抽出を並列に実行するワーカー数（0の場合はGOMAXPROCS）。結果はワーカー数によらず同一")
+	analyzeCmd.Flags().BoolVar(&noCache, "no-cache", false, "解析結果のキャッシュ（ユーザーのキャッシュディレクトリのdepsee配下）を使用・更新せずに全ファイルを解析")
+	analyzeCmd.Flags().StringVarP(&output, "output", "o", "", "Mermaid相関図を書き込むファイル（拡張子が.mdの場合はmermaidコードブロックで囲む）")
+	analyzeCmd.Flags().BoolVar(&edgePositions, "edge-positions", false, "各エッジの依存の出現位置を出力。Mermaid相関図ではエッジの直後のコメント（file:line:col）、Markdownの出力ファイル（-o *.md）では相関図の下の表（file:line）として出力")
+	analyzeCmd.Flags().BoolVar(&watchMode, "watch", false, "解析対象ディレクトリを監視し、.goファイルの変更のたびに再解析して出力を更新（Ctrl+Cで終了）")
+	analyzeCmd.Flags().DurationVar(&watchInterval, "watch-interval", depsee.DefaultWatchInterval, "--watch でファイルの変更を確認する間隔")
 }
 
 // runAnalyze はanalyzeコマンドの実行ロジック
@@ -57,11 +121,37 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 		TargetPackages:         targetPackages,
 		ExcludePackages:        excludePackages,
 		ExcludeDirs:            excludeDirs,
+		TypeCheck:              typeCheck,
+		CollapseMethods:        collapseMethods,
+		IncludeGlobals:         includeGlobals,
+		IncludeExternal:        includeExternal,
+		IncludeStdlib:          includeStdlib,
+		ExternalInstability:    externalInstability,
+		IncludeTests:           includeTests,
+		GOOS:                   goos,
+		GOARCH:                 goarch,
+		Tags:                   buildTags,
+		Platforms:              platforms,
+		Generated:              generated,
+		GoroutineNodes:         goroutineNodes,
+		Weighted:               weighted,
+		Weights:                weights,
+		Workers:                workers,
+		NoCache:                noCache,
+		Output:                 output,
+		EdgePositions:          edgePositions,
+		Version:                cacheVersion(),
 		LogLevel:               GetLogLevel(),
 		LogFormat:              GetLogFormat(),
 	}
 
 	// Depseeインスタンスを作成して実行
 	app := depsee.New()
+	if watchMode {
+		// Ctrl+Cまたはterminateで監視を終了する
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return app.Watch(ctx, config, watchInterval)
+	}
 	return app.Analyze(config)
 }
@@ -1,7 +1,12 @@
 package cmd
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
 
 	"github.com/spf13/cobra"
 )
@@ -17,6 +22,42 @@ func GetBuildInfo() (string, string, string) {
 	return GetVersion(), buildCommit, buildDate
 }
 
+// cacheVersion は解析キャッシュのキーに含めるバージョン文字列を返します
+// 同じバージョン・コミットでもビルドが異なれば解析結果が変わりうるため、実行ファイルのハッシュを含めます
+// 実行ファイルを読み込めない場合は、ビルド情報のVCSリビジョンと未コミットの変更の有無を含めます
+func cacheVersion() string {
+	v := GetVersion() + "+" + buildCommit
+	if hash, err := executableHash(); err == nil {
+		return v + "+" + hash
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" || setting.Key == "vcs.modified" {
+				v += "+" + setting.Key + "=" + setting.Value
+			}
+		}
+	}
+	return v
+}
+
+// executableHash は実行中のファイルのSHA-256を16進文字列で返します
+func executableHash() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(exe)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 // versionCmd はversionサブコマンドを表します
 var versionCmd = &cobra.Command{
 	Use:   "version",
@@ -3,7 +3,6 @@ package analyzer
 import (
 	"fmt"
 	"go/ast"
-	"go/parser"
 	"go/token"
 	"os"
 	"path/filepath"
@@ -11,9 +10,11 @@ import (
 	"strings"
 
 	"github.com/harakeishi/depsee/internal/analyzer/extraction"
+	"github.com/harakeishi/depsee/internal/cache"
 	"github.com/harakeishi/depsee/internal/errors"
 	"github.com/harakeishi/depsee/internal/logger"
 	"github.com/harakeishi/depsee/internal/types"
+	"github.com/harakeishi/depsee/internal/utils"
 )
 
 // Result は解析結果を格納する構造体です。
@@ -24,10 +25,13 @@ type Result = types.Result
 type DependencyInfo = types.DependencyInfo
 type StructInfo = types.StructInfo
 type InterfaceInfo = types.InterfaceInfo
+type TypeInfo = types.TypeInfo
 type FuncInfo = types.FuncInfo
 type FieldInfo = types.FieldInfo
 type PackageInfo = types.PackageInfo
 type ImportInfo = types.ImportInfo
+type ValueInfo = types.ValueInfo
+type GoroutineInfo = types.GoroutineInfo
 
 // Filters は解析対象をフィルタリングするための条件を定義する構造体です。
 // 特定のパッケージのみを対象にしたり、特定のパッケージやディレクトリを除外したりできます。
@@ -37,14 +41,39 @@ type Filters struct {
 	ExcludeDirs     []string // 解析から除外するディレクトリパス一覧
 }
 
+// Options は解析方法を切り替えるための設定を定義する構造体です。
+type Options struct {
+	TypeCheck        bool       // go/typesで型チェックし、識別子を型オブジェクトに解決してから依存関係を抽出する
+	IncludeGlobals   bool       // パッケージレベルの変数・定数をノードとし、関数本体からの読み書きを依存関係として抽出する
+	IncludeExternal  bool       // サードパーティモジュールへの参照を、モジュールごとにまとめた外部依存ノードへの依存関係として抽出する
+	IncludeStdlib    bool       // IncludeExternal指定時に、標準ライブラリのパッケージへの参照も外部依存ノードへの依存関係として抽出する
+	IncludeTests     bool       // テストファイル（_test.go）と外部テストパッケージ（pkg_test）も解析し、テストから本番コードへの依存関係を抽出する
+	GOOS             string     // ビルド制約の評価に使うGOOS（空の場合は環境変数GOOSまたはホストの値）
+	GOARCH           string     // ビルド制約の評価に使うGOARCH（空の場合は環境変数GOARCHまたはホストの値）
+	BuildTags        []string   // ビルド制約の評価で有効とする追加のビルドタグ（goコマンドの-tagsに相当）
+	Platforms        []Platform // 指定した全てのプラットフォームでビルド制約を評価し、いずれかでビルド対象となるファイルを解析する（GOOS・GOARCHより優先）
+	ExcludeGenerated bool       // 生成コード（"// Code generated ... DO NOT EDIT." のヘッダを持つファイル）を解析対象から除外する
+	GoroutineNodes   bool       // ループを含むgoroutineの関数リテラル（go func() { for { ... } }()）を起動元の関数とは別のノードとする
+	Workers          int        // パース・解析・依存関係抽出を並列に実行するワーカー数（0以下の場合はGOMAXPROCS）
+	CacheDir         string     // ファイル・パッケージ単位の解析結果を保存するディレクトリ（空の場合はキャッシュしない）
+	Version          string     // キャッシュのキーに含めるdepseeのバージョン（変わると以前のキャッシュは使われない）
+}
+
 // GoAnalyzer はGo言語の静的解析を行う具象実装です。
 // Analyzerインターフェースを実装し、Go言語のソースコードから
 // 構造体、インターフェース、関数の情報と依存関係を抽出します。
 type GoAnalyzer struct {
-	Filters   Filters  // 解析に適用するフィルタ条件
-	filesPath []string // 解析対象のGoファイルパス一覧
-	targetDir string   // 解析対象のルートディレクトリ
-	Result    *Result  // 解析結果を格納する構造体
+	Filters     Filters                 // 解析に適用するフィルタ条件
+	Options     Options                 // 解析方法の設定
+	filesPath   []string                // 解析対象のGoファイルパス一覧
+	platforms   map[string][]string     // 一部のプラットフォームでのみビルド対象となるファイルのパス -> そのプラットフォーム一覧（Platforms指定時のみ）
+	generated   map[string]bool         // 生成コードのファイルのパス
+	targetDir   string                  // 解析対象のルートディレクトリ
+	store       *extraction.FileStore   // フィルタ・解析・依存関係抽出で共有するパース済みファイル
+	parsedFiles []extraction.ParsedFile // 解析対象のパース済みファイル一覧（依存関係抽出で再利用）
+	resolvers   []*TypeResolver         // 型チェックモードでビルドコンテキストごとに使用した型解決器（型チェックしない場合はnil）
+	cache       *cache.Cache            // 解析結果の永続キャッシュ（キャッシュしない場合はnil）
+	Result      *Result                 // 解析結果を格納する構造体
 }
 
 // New は新しいGoAnalyzerインスタンスを作成します。
@@ -68,6 +97,12 @@ func (ga *GoAnalyzer) SetFilters(filters Filters) {
 	ga.Filters = filters
 }
 
+// SetOptions は解析方法の設定を行います。
+// 型チェックモードの有効化などが可能です。
+func (ga *GoAnalyzer) SetOptions(options Options) {
+	ga.Options = options
+}
+
 // ExportResult は解析結果を取得します。
 // 解析で抽出された全ての情報（構造体、インターフェース、関数、依存関係等）を
 // 含むResultオブジェクトを返します。
@@ -76,11 +111,23 @@ func (ga *GoAnalyzer) ExportResult() *Result {
 }
 
 // ListTartgetFiles は指定されたディレクトリから解析対象のGoファイルをリストアップします。
-// ディレクトリを再帰的に探索し、設定されたフィルタ条件に基づいて
-// 解析対象となる.goファイル（テストファイル除く）を抽出します。
+// ディレクトリを再帰的に探索し、ビルド制約と設定されたフィルタ条件に基づいて
+// 解析対象となる.goファイル（IncludeTestsが指定されていない場合はテストファイルを除く）を抽出します。
+// ビルド制約はgoコマンドと同様に //go:build 行とファイル名の接尾辞（_linux・_amd64等）から評価します。
+// 同じGoAnalyzerで再度呼び出した場合、前回から変更されていないファイルのパース結果を再利用します。
 func (ga *GoAnalyzer) ListTartgetFiles(dir string) error {
 	ga.targetDir = dir // ディレクトリを記録
 	ga.filesPath = []string{}
+	ga.platforms = nil
+	ga.generated = nil
+	// go.modが変更されている可能性があるため、モジュール情報は毎回読み直す
+	utils.ClearModuleCache()
+	if ga.store == nil {
+		ga.store = extraction.NewFileStore()
+	} else if dropped := ga.store.Refresh(); len(dropped) > 0 {
+		// 2回目以降のリストアップでは、前回から変更されたファイルだけを再パースする
+		logger.Debug("変更されたファイルのパース結果を破棄", "files", dropped)
+	}
 
 	// ディレクトリの存在確認
 	if _, err := os.Stat(dir); err != nil {
@@ -88,45 +135,104 @@ func (ga *GoAnalyzer) ListTartgetFiles(dir string) error {
 		return errors.NewAnalysisError(dir, err)
 	}
 
-	// ディレクトリ再帰探索
-	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			logger.Warn("ファイル読み込みエラー", "path", path, "error", err)
-			return nil // エラーを収集して処理を続行
-		}
-		if strings.HasSuffix(path, ".go") && !strings.HasSuffix(path, "_test.go") {
-			logger.Debug("Goファイル発見", "file", path)
-			// filterを適用
-			include, err := ga.Filters.shouldIncludeFile(path)
+	// ディレクトリ再帰探索（go.workのメンバーのうち解析対象ディレクトリの外にあるモジュールも探索する）
+	contexts := ga.Options.buildContexts()
+	var candidates []string
+	for _, root := range TargetRoots(dir) {
+		err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
 			if err != nil {
-				logger.Warn("ファイルフィルタ適用失敗", "path", path, "error", err)
+				logger.Warn("ファイル読み込みエラー", "path", path, "error", err)
 				return nil // エラーを収集して処理を続行
 			}
-			if include {
-				ga.filesPath = append(ga.filesPath, path)
+			if strings.HasSuffix(path, ".go") && (ga.Options.IncludeTests || !extraction.IsTestFile(path)) {
+				logger.Debug("Goファイル発見", "file", path)
+				platforms, err := matchPlatforms(path, contexts)
+				if err != nil {
+					logger.Warn("ビルド制約の評価失敗", "path", path, "error", err)
+					return nil
+				}
+				if len(platforms) == 0 {
+					logger.Debug("ビルド制約により除外", "file", path)
+					return nil
+				}
+				if len(platforms) < len(contexts) {
+					if ga.platforms == nil {
+						ga.platforms = make(map[string][]string)
+					}
+					ga.platforms[path] = platforms
+				}
+				candidates = append(candidates, path)
 			}
+			return nil
+		})
+		if err != nil {
+			logger.Error("ディレクトリ探索失敗", "dir", root, "error", err)
+			return errors.NewAnalysisError(root, err)
 		}
-		return nil
-	})
-	if err != nil {
-		logger.Error("ディレクトリ探索失敗", "dir", dir, "error", err)
-		return errors.NewAnalysisError(dir, err)
+	}
+
+	// 候補ファイルを並列にパースしてから、探索順にfilterを適用
+	ga.store.ParseAll(candidates, ga.Options.Workers)
+	for _, path := range candidates {
+		include, err := ga.Filters.shouldIncludeFile(path, ga.store)
+		if err != nil {
+			logger.Warn("ファイルフィルタ適用失敗", "path", path, "error", err)
+			continue // エラーを収集して処理を続行
+		}
+		if !include {
+			continue
+		}
+		// 生成コードのヘッダを持つファイルを検出（protobuf、モック、sqlc等）
+		if pf, err := ga.store.Parse(path); err == nil && ast.IsGenerated(pf.File) {
+			if ga.Options.ExcludeGenerated {
+				logger.Debug("生成コードのため除外", "file", path)
+				continue
+			}
+			if ga.generated == nil {
+				ga.generated = make(map[string]bool)
+			}
+			ga.generated[path] = true
+		}
+		ga.filesPath = append(ga.filesPath, path)
 	}
 
 	return nil
 }
 
-// shouldIncludeFile は指定されたファイルがフィルタ条件に適合するかどうかを判定します。
-// パッケージ名をパースし、対象パッケージ、除外パッケージ、除外ディレクトリの条件をチェックします。
-func (f Filters) shouldIncludeFile(path string) (bool, error) {
-	fset := token.NewFileSet()
+// TargetRoots は探索するディレクトリの一覧を返します。
+// 解析対象ディレクトリにgo.workがある場合は、ワークスペースの全メンバーのモジュールを解析対象とするため、
+// 解析対象ディレクトリの外にあるメンバー（use ../other 等）のディレクトリも返します。
+func TargetRoots(dir string) []string {
+	roots := []string{dir}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return roots
+	}
+	ws := utils.FindWorkspace(dir)
+	if ws == nil || ws.Dir != absDir {
+		return roots
+	}
+	for _, member := range ws.Modules {
+		rel, err := filepath.Rel(absDir, member.Dir)
+		if err != nil || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))) {
+			continue // 解析対象ディレクトリ内のメンバーは探索済み
+		}
+		logger.Debug("ワークスペースのメンバーを探索", "module", member.Path, "dir", member.Dir)
+		roots = append(roots, filepath.Join(dir, rel))
+	}
+	return roots
+}
 
-	file, err := parser.ParseFile(fset, path, nil, parser.PackageClauseOnly)
+// shouldIncludeFile は指定されたファイルがフィルタ条件に適合するかどうかを判定します。
+// パッケージ名を取得し、対象パッケージ、除外パッケージ、除外ディレクトリの条件をチェックします。
+// ファイルは共有のFileStoreでパースされ、解析・依存関係抽出でもそのまま再利用されます。
+func (f Filters) shouldIncludeFile(path string, store *extraction.FileStore) (bool, error) {
+	pf, err := store.Parse(path)
 	if err != nil {
 		logger.Warn("ファイルパース失敗", "path", path, "error", err)
 		return false, err
 	}
-	packageName := file.Name.Name
+	packageName := pf.File.Name.Name
 	// ターゲットパッケージのチェック(もし含まれていなかったら早期リターン)
 	if !slices.Contains(f.TargetPackages, packageName) && len(f.TargetPackages) > 0 {
 		return false, nil
@@ -137,7 +243,7 @@ func (f Filters) shouldIncludeFile(path string) (bool, error) {
 	}
 
 	// 除外ディレクトリのチェック(もし含まれていたら早期リターン)
-	if len(f.ExcludeDirs) > 0 && len(f.ExcludeDirs) > 0 {
+	if len(f.ExcludeDirs) > 0 {
 		for _, excludeDir := range f.ExcludeDirs {
 			rel, err := filepath.Rel(excludeDir, path)
 			if err != nil {
@@ -161,42 +267,142 @@ func (ga *GoAnalyzer) Analyze() error {
 		return errors.NewAnalysisError("解析対象のファイルが存在しません", nil)
 	}
 	ga.Result = &Result{}
-	fset := token.NewFileSet()
+	ga.parsedFiles = nil
+	ga.resolvers = nil
+	ga.cache = nil
+	if ga.store == nil {
+		ga.store = extraction.NewFileStore()
+	}
+	if ga.Options.CacheDir != "" {
+		ga.cache = ga.newCache()
+	}
+	fset := ga.store.FileSet()
 	errorCollector := errors.NewErrorCollector()
 
-	for _, file := range ga.filesPath {
-		// 解析処理
-		fmt.Println(file)
-		f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	// ファイルごとの解析をワーカーで並列に実行し、結果はファイル順に格納する
+	// （フィルタ適用時にパース済みのファイルは再パースせず、内容が変わっていないファイルはキャッシュを使う）
+	fragments := make([]*Result, len(ga.filesPath))
+	parsed := make([]*extraction.ParsedFile, len(ga.filesPath))
+	parseErrors := make([]error, len(ga.filesPath))
+	utils.ParallelFor(len(ga.filesPath), utils.Workers(ga.Options.Workers), func(i int) {
+		file := ga.filesPath[i]
+		pf, err := ga.store.Parse(file)
 		if err != nil {
+			parseErrors[i] = err
+			return
+		}
+		fragment, ok := ga.loadFileResult(pf)
+		if !ok {
+			f, pkgPath := pf.File, pf.PackagePath
+			fragment = &Result{}
+			analyzeFile(f, fset, file, pkgPath, fragment)
+			if ga.Options.IncludeGlobals {
+				fragment.Values = extractValues(f, fset, file, f.Name.Name, pkgPath)
+			}
+			if ga.Options.GoroutineNodes {
+				fragment.Goroutines = extractGoroutines(f, fset, file, pkgPath)
+			}
+			ga.storeFileResult(pf, fragment)
+		}
+		fragments[i], parsed[i] = fragment, pf
+	})
+
+	// ファイル順にマージすることで逐次実行と同一の結果を得る
+	for i, file := range ga.filesPath {
+		fmt.Println(file)
+		if err := parseErrors[i]; err != nil {
 			logger.Warn("ファイルパース失敗", "file", file, "error", err)
 			errorCollector.Add(errors.NewAnalysisError(file, err))
 			continue // パースエラーがあっても他のファイルは処理を続行
 		}
-		analyzeFile(f, fset, file, ga.Result)
+		mergeResult(ga.Result, fragments[i])
+		ga.parsedFiles = append(ga.parsedFiles, *parsed[i])
+	}
+
+	// 別ファイルで定義されたメソッドを構造体に関連付け
+	attachMethods(ga.Result)
+
+	// 複数のプラットフォームを解析した場合は、一部のプラットフォームにのみ存在するノードを区別できるようにファイルのプラットフォームを記録
+	if len(ga.Options.Platforms) > 0 {
+		for _, p := range ga.Options.Platforms {
+			ga.Result.Platforms = append(ga.Result.Platforms, p.String())
+		}
+		for _, pf := range ga.parsedFiles {
+			if platforms, ok := ga.platforms[pf.Path]; ok {
+				if ga.Result.FilePlatforms == nil {
+					ga.Result.FilePlatforms = make(map[string][]string)
+				}
+				ga.Result.FilePlatforms[pf.Path] = platforms
+			}
+		}
+	}
+
+	// 生成コードのノードを区別できるように生成コードのファイルを記録
+	for _, pf := range ga.parsedFiles {
+		if ga.generated[pf.Path] {
+			ga.Result.GeneratedFiles = append(ga.Result.GeneratedFiles, pf.Path)
+		}
+	}
+
+	// 依存関係抽出器を準備し、キャッシュを使う場合はパッケージごとのキャッシュキーを算出
+	extractor := ga.newExtractor(ga.targetDir)
+	var pc *packageCache
+	if ga.cache != nil {
+		pc = newPackageCache(ga.cache, extraction.GroupPackages(ga.parsedFiles), extractor.StrategyNames())
+		extractor.SetPackageCache(pc)
+	}
+
+	// 型チェックモードの場合はパッケージ単位で型情報を付与
+	// 全パッケージの依存関係と実装関係がキャッシュ済みの場合、型情報は使われないため型チェックを省略する
+	if ga.Options.TypeCheck && (pc == nil || !pc.complete() || !ga.cache.Has(pc.implementationsKey())) {
+		ga.typeCheck(fset)
 	}
 
 	// 依存関係解析を実行
-	dependencies := ga.extractDependencies(ga.Result, ga.targetDir)
+	dependencies := ga.extractDependencies(ga.Result, extractor, pc)
 	ga.Result.Dependencies = dependencies
 
-	logger.Info("解析完了", "files", len(ga.filesPath), "structs", len(ga.Result.Structs), "interfaces", len(ga.Result.Interfaces), "functions", len(ga.Result.Functions), "packages", len(ga.Result.Packages), "dependencies", len(ga.Result.Dependencies))
+	if ga.cache != nil {
+		// 今回使われなかった古いエントリを削除
+		if err := ga.cache.Prune(); err != nil {
+			logger.Warn("キャッシュ整理失敗", "dir", ga.cache.Dir(), "error", err)
+		}
+		hits, misses := ga.cache.Stats()
+		logger.Info("解析キャッシュ", "dir", ga.cache.Dir(), "hits", hits, "misses", misses)
+	}
+
+	logger.Info("解析完了", "files", len(ga.filesPath), "structs", len(ga.Result.Structs), "interfaces", len(ga.Result.Interfaces), "types", len(ga.Result.Types), "functions", len(ga.Result.Functions), "methods", len(ga.Result.Methods), "inits", len(ga.Result.Inits), "values", len(ga.Result.Values), "goroutines", len(ga.Result.Goroutines), "packages", len(ga.Result.Packages), "dependencies", len(ga.Result.Dependencies))
 	return nil
 }
 
+// newExtractor は解析方法の設定に応じた依存関係抽出器を作成します。
+func (ga *GoAnalyzer) newExtractor(targetDir string) *extraction.StrategyBasedExtractor {
+	strategyExtractor := extraction.DefaultStrategyBasedExtractor(targetDir)
+	strategyExtractor.SetWorkers(ga.Options.Workers)
+	// 関数リテラルの中の依存関係は外側の宣言のものとする（goroutineノードを作成する場合はそのgoroutineのもの）
+	strategyExtractor.SetGoroutineNodes(ga.Options.GoroutineNodes)
+	// 関数値・メソッド値の参照は常に抽出する（変数の初期化式からの参照は変数がノードになる場合のみ）
+	strategyExtractor.AddStrategy(extraction.NewFuncValueDependencyExtractor(strategyExtractor.Context(), ga.Options.IncludeGlobals))
+	if ga.Options.IncludeGlobals {
+		strategyExtractor.AddStrategy(extraction.NewGlobalDependencyExtractor(strategyExtractor.Context()))
+	}
+	if ga.Options.IncludeExternal {
+		strategyExtractor.AddStrategy(extraction.NewExternalDependencyExtractor(strategyExtractor.Context(), ga.Options.IncludeStdlib))
+	}
+	return strategyExtractor
+}
+
 // extractDependencies は解析結果から依存関係を抽出します。
 // 複数の依存関係抽出戦略（フィールド、シグネチャ、関数呼び出し、パッケージ間）を使用して
 // 包括的な依存関係情報を収集します。
-func (ga *GoAnalyzer) extractDependencies(result *Result, targetDir string) []DependencyInfo {
+// pcがnilでない場合、変更のないパッケージの依存関係と実装関係はキャッシュから取得します。
+func (ga *GoAnalyzer) extractDependencies(result *Result, strategyExtractor *extraction.StrategyBasedExtractor, pc *packageCache) []DependencyInfo {
 	logger.Info("依存関係解析開始")
 
 	var allDependencies []DependencyInfo
 
-	// 新しいstrategyベース依存関係抽出を使用
-	strategyExtractor := extraction.DefaultStrategyBasedExtractor(targetDir)
-
 	// 解析済みファイルから依存関係を抽出
-	extractionDeps, err := strategyExtractor.ExtractFromFiles(ga.filesPath)
+	extractionDeps, err := strategyExtractor.ExtractFromParsedFiles(ga.parsedFiles)
 	if err != nil {
 		logger.Error("strategy依存関係抽出エラー", "error", err)
 		return allDependencies
@@ -205,16 +411,136 @@ func (ga *GoAnalyzer) extractDependencies(result *Result, targetDir string) []De
 	// extraction.DependencyInfo を analyzer.DependencyInfo に変換
 	for _, dep := range extractionDeps {
 		allDependencies = append(allDependencies, DependencyInfo{
-			From: dep.From,
-			To:   dep.To,
-			Type: dep.Type,
+			From:     dep.From,
+			To:       dep.To,
+			Type:     dep.Type,
+			Position: dep.Position,
 		})
 	}
 
+	// 型とインターフェースの実装関係を抽出
+	allDependencies = append(allDependencies, ga.implementations(result, pc)...)
+
 	logger.Info("依存関係解析完了", "total_dependencies", len(allDependencies))
 	return allDependencies
 }
 
+// implementations は型とインターフェースの実装関係を抽出します。
+// 型チェックモードで型チェックを省略した場合は、キャッシュした実装関係を返します。
+func (ga *GoAnalyzer) implementations(result *Result, pc *packageCache) []DependencyInfo {
+	if !ga.Options.TypeCheck {
+		return extractImplementations(result)
+	}
+	if ga.resolvers == nil {
+		var deps []DependencyInfo
+		if pc != nil && ga.cache.Load(pc.implementationsKey(), &deps) {
+			return deps
+		}
+		// キャッシュを読み込めなかった場合は型チェックしてから抽出する
+		ga.typeCheck(ga.store.FileSet())
+	}
+	// 複数のプラットフォームの型チェック結果に共通する実装関係は1つにまとめる
+	var deps []DependencyInfo
+	seen := make(map[DependencyInfo]bool)
+	for _, resolver := range ga.resolvers {
+		for _, dep := range extractTypedImplementations(resolver.packages) {
+			if !seen[dep] {
+				seen[dep] = true
+				deps = append(deps, dep)
+			}
+		}
+	}
+	if pc != nil {
+		if err := ga.cache.Store(pc.implementationsKey(), deps); err != nil {
+			logger.Warn("キャッシュ保存失敗", "error", err)
+		}
+	}
+	return deps
+}
+
+// typeCheck はパース済みファイルをディレクトリ・パッケージ名ごとにまとめて型チェックし、
+// 各ファイルに型情報を関連付けます。
+// 同一ディレクトリに複数パッケージが存在する場合もパッケージ名で分けて型チェックします。
+// 複数のプラットフォームを解析する場合は、プラットフォームごとのファイル（foo_linux.go と foo_windows.go 等）の宣言が
+// 重複しないようにビルドコンテキストごとに型チェックし、各ファイルには最初にビルド対象となるコンテキストの型情報を関連付けます。
+func (ga *GoAnalyzer) typeCheck(fset *token.FileSet) {
+	logger.Info("型チェック開始", "files", len(ga.parsedFiles))
+
+	ga.resolvers = nil
+	checked := make([]bool, len(ga.parsedFiles))
+	for _, c := range ga.Options.buildContexts() {
+		var files []extraction.ParsedFile
+		var indices []int
+		for i, pf := range ga.parsedFiles {
+			if platforms, ok := ga.platforms[pf.Path]; ok && !slices.Contains(platforms, c.platform.String()) {
+				continue
+			}
+			files = append(files, pf)
+			indices = append(indices, i)
+		}
+		if len(files) == 0 {
+			continue
+		}
+
+		resolver := NewTypeResolverWithFileSet(fset)
+		resolver.SetDir(ga.targetDir)
+		resolver.SetBuildContext(c.ctxt)
+
+		// 全パッケージを登録してから型チェックすることで、import先の解析対象パッケージは先に型チェックされ、
+		// 別パッケージの型同士も同一の型オブジェクトとして比較できる
+		packages := extraction.GroupPackages(files)
+		pkgPaths := make([]string, 0, len(packages))
+		for _, pkg := range packages {
+			astFiles := make([]*ast.File, 0, len(pkg.Files))
+			for _, pf := range pkg.Files {
+				astFiles = append(astFiles, pf.File)
+			}
+			resolver.AddPackage(pkg.ID(), astFiles)
+			pkgPaths = append(pkgPaths, pkg.ID())
+		}
+		resolver.CheckAll(pkgPaths)
+
+		for _, i := range indices {
+			if !checked[i] {
+				checked[i] = true
+				ga.parsedFiles[i].TypesInfo = resolver.Info()
+			}
+		}
+		ga.resolvers = append(ga.resolvers, resolver)
+		logger.Info("型チェック完了", "platform", c.platform, "packages", len(packages))
+	}
+}
+
+// mergeResult はファイル単位の解析結果を全体の解析結果の末尾に追加します。
+func mergeResult(result *Result, fragment *Result) {
+	result.Structs = append(result.Structs, fragment.Structs...)
+	result.Interfaces = append(result.Interfaces, fragment.Interfaces...)
+	result.Types = append(result.Types, fragment.Types...)
+	result.Functions = append(result.Functions, fragment.Functions...)
+	result.Methods = append(result.Methods, fragment.Methods...)
+	result.Inits = append(result.Inits, fragment.Inits...)
+	result.Values = append(result.Values, fragment.Values...)
+	result.Goroutines = append(result.Goroutines, fragment.Goroutines...)
+	result.Packages = append(result.Packages, fragment.Packages...)
+	result.Dependencies = append(result.Dependencies, fragment.Dependencies...)
+}
+
+// attachMethods は構造体と別のファイルで定義されたメソッドをStructInfoに関連付けます。
+// 同一ファイル内のメソッドはextractFunctionsで関連付け済みのため、重複しないものだけを追加します。
+func attachMethods(result *Result) {
+	structIndex := make(map[types.NodeID]int)
+	for i, s := range result.Structs {
+		structIndex[s.NodeID()] = i
+	}
+	for _, m := range result.Methods {
+		i, ok := structIndex[m.ReceiverNodeID()]
+		if !ok || result.Structs[i].File == m.File {
+			continue
+		}
+		result.Structs[i].Methods = append(result.Structs[i].Methods, m)
+	}
+}
+
 // extractImports はASTファイルからimport文を解析してImportInfoのスライスを返します。
 // importパスとエイリアス情報を抽出し、エイリアスが指定されていない場合は
 // importパスからパッケージ名を自動抽出します。
@@ -244,9 +570,10 @@ func extractImports(f *ast.File) []ImportInfo {
 
 // extractFunctions はASTファイルから関数・メソッドを解析します。
 // 関数宣言を走査し、関数名、引数、戻り値、レシーバ情報、関数本体の呼び出し情報を抽出します。
-// メソッドの場合は対応する構造体のStructInfoに関連付けられます。
-func extractFunctions(f *ast.File, fset *token.FileSet, file string, pkgName string, structMap map[string]*StructInfo) []FuncInfo {
+// 関数とメソッドは別々に返され、メソッドは同一ファイル内の構造体であればStructInfoにも関連付けられます。
+func extractFunctions(f *ast.File, fset *token.FileSet, file string, pkgName string, pkgPath string, structMap map[string]*StructInfo) ([]FuncInfo, []FuncInfo) {
 	var functions []FuncInfo
+	var methods []FuncInfo
 
 	for _, decl := range f.Decls {
 		funcDecl, ok := decl.(*ast.FuncDecl)
@@ -254,54 +581,27 @@ func extractFunctions(f *ast.File, fset *token.FileSet, file string, pkgName str
 			continue
 		}
 		pos := fset.Position(funcDecl.Pos())
-		params := []FieldInfo{}
-		if funcDecl.Type.Params != nil {
-			for _, field := range funcDecl.Type.Params.List {
-				typeStr := exprToTypeString(field.Type)
-				for _, name := range field.Names {
-					params = append(params, FieldInfo{Name: name.Name, Type: typeStr})
-				}
-				if len(field.Names) == 0 {
-					params = append(params, FieldInfo{Name: "", Type: typeStr})
-				}
-			}
-		}
-		results := []FieldInfo{}
-		if funcDecl.Type.Results != nil {
-			for _, field := range funcDecl.Type.Results.List {
-				typeStr := exprToTypeString(field.Type)
-				for _, name := range field.Names {
-					results = append(results, FieldInfo{Name: name.Name, Type: typeStr})
-				}
-				if len(field.Names) == 0 {
-					results = append(results, FieldInfo{Name: "", Type: typeStr})
-				}
-			}
-		}
+		params := extractFieldList(funcDecl.Type.Params)
+		results := extractFieldList(funcDecl.Type.Results)
 		fi := FuncInfo{
-			Name:     funcDecl.Name.Name,
-			Package:  pkgName,
-			File:     file,
-			Position: pos,
-			Params:   params,
-			Results:  results,
+			Name:        funcDecl.Name.Name,
+			Package:     pkgName,
+			PackagePath: pkgPath,
+			File:        file,
+			Position:    pos,
+			TypeParams:  extractFieldList(funcDecl.Type.TypeParams),
+			Params:      params,
+			Results:     results,
 		}
 		// メソッドの場合はStructInfoに内包
 		if funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 {
-			recvType := ""
-			switch t := funcDecl.Recv.List[0].Type.(type) {
-			case *ast.Ident:
-				recvType = t.Name
-			case *ast.StarExpr:
-				if ident, ok := t.X.(*ast.Ident); ok {
-					recvType = ident.Name
-				}
-			}
+			recvType := receiverTypeName(funcDecl.Recv.List[0].Type)
 			fi.Receiver = recvType
 			fi.BodyCalls = extractBodyCalls(funcDecl.Body)
 			if s, ok := structMap[recvType]; ok {
 				s.Methods = append(s.Methods, fi)
 			}
+			methods = append(methods, fi)
 		} else {
 			// 通常の関数
 			// --- 関数本体の呼び出し関数名抽出 ---
@@ -309,13 +609,50 @@ func extractFunctions(f *ast.File, fset *token.FileSet, file string, pkgName str
 			functions = append(functions, fi)
 		}
 	}
-	return functions
+	return functions, methods
+}
+
+// extractFieldList は引数・戻り値のフィールドリストをFieldInfoのスライスに変換します。
+// 名前付きフィールドは名前ごとに1要素とし、無名フィールドは名前を空文字とします。
+func extractFieldList(list *ast.FieldList) []FieldInfo {
+	fields := []FieldInfo{}
+	if list == nil {
+		return fields
+	}
+	for _, field := range list.List {
+		typeStr := exprToTypeString(field.Type)
+		for _, name := range field.Names {
+			fields = append(fields, FieldInfo{Name: name.Name, Type: typeStr})
+		}
+		if len(field.Names) == 0 {
+			fields = append(fields, FieldInfo{Name: "", Type: typeStr})
+		}
+	}
+	return fields
+}
+
+// receiverTypeName はメソッドのレシーバ型表現から型名を取り出します。
+// ポインタレシーバや型パラメータ付きのレシーバ（例: *Repo[T]）にも対応します。
+func receiverTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return receiverTypeName(t.X)
+	case *ast.ParenExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(t.X)
+	}
+	return ""
 }
 
 // extractTypes はASTファイルから型宣言（構造体・インターフェース）を解析します。
 // type宣言を走査し、構造体のフィールド情報やインターフェースのメソッドシグネチャを抽出します。
 // 構造体については後でメソッドを関連付けるためのマップも返します。
-func extractTypes(f *ast.File, fset *token.FileSet, file string, pkgName string) ([]StructInfo, []InterfaceInfo, map[string]*StructInfo) {
+func extractTypes(f *ast.File, fset *token.FileSet, file string, pkgName string, pkgPath string) ([]StructInfo, []InterfaceInfo, map[string]*StructInfo) {
 	var structs []StructInfo
 	var interfaces []InterfaceInfo
 	structMap := map[string]*StructInfo{}
@@ -330,6 +667,9 @@ func extractTypes(f *ast.File, fset *token.FileSet, file string, pkgName string)
 			if !ok {
 				continue
 			}
+			if typeSpec.Assign.IsValid() {
+				continue // 型エイリアスはextractNamedTypesで扱う
+			}
 			pos := fset.Position(typeSpec.Pos())
 			switch t := typeSpec.Type.(type) {
 			case *ast.StructType:
@@ -351,21 +691,26 @@ func extractTypes(f *ast.File, fset *token.FileSet, file string, pkgName string)
 					}
 				}
 				si := StructInfo{
-					Name:     typeSpec.Name.Name,
-					Package:  pkgName,
-					File:     file,
-					Position: pos,
-					Fields:   fields,
+					Name:        typeSpec.Name.Name,
+					Package:     pkgName,
+					PackagePath: pkgPath,
+					File:        file,
+					Position:    pos,
+					TypeParams:  extractFieldList(typeSpec.TypeParams),
+					Fields:      fields,
 				}
 				structMap[si.Name] = &si
 				structs = append(structs, si)
 			case *ast.InterfaceType:
 				ii := InterfaceInfo{
-					Name:     typeSpec.Name.Name,
-					Package:  pkgName,
-					File:     file,
-					Position: pos,
+					Name:        typeSpec.Name.Name,
+					Package:     pkgName,
+					PackagePath: pkgPath,
+					File:        file,
+					Position:    pos,
+					TypeParams:  extractFieldList(typeSpec.TypeParams),
 				}
+				ii.Methods, ii.Embeds = extractInterfaceMethods(t, typeSpec.Name.Name, fset, file, pkgName, pkgPath)
 				interfaces = append(interfaces, ii)
 			}
 		}
@@ -373,10 +718,166 @@ func extractTypes(f *ast.File, fset *token.FileSet, file string, pkgName string)
 	return structs, interfaces, structMap
 }
 
+// extractNamedTypes はASTファイルから構造体・インターフェース以外の名前付き型を解析します。
+// 定義型（type Status int）、関数型、スライス・マップ等のコレクション型、型エイリアスを抽出します。
+// 構造体・インターフェースのエイリアスもエイリアスとして扱います。
+func extractNamedTypes(f *ast.File, fset *token.FileSet, file string, pkgName string, pkgPath string) []TypeInfo {
+	var namedTypes []TypeInfo
+
+	for _, decl := range f.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+			kind, ok := namedTypeKind(typeSpec)
+			if !ok {
+				continue
+			}
+			namedTypes = append(namedTypes, TypeInfo{
+				Name:        typeSpec.Name.Name,
+				Package:     pkgName,
+				PackagePath: pkgPath,
+				File:        file,
+				Position:    fset.Position(typeSpec.Pos()),
+				Kind:        kind,
+				TypeParams:  extractFieldList(typeSpec.TypeParams),
+				Underlying:  exprToTypeString(typeSpec.Type),
+			})
+		}
+	}
+	return namedTypes
+}
+
+// namedTypeKind はtype宣言の種類を判定します。
+// 構造体・インターフェースの定義はextractTypesで扱うためfalseを返します。
+func namedTypeKind(typeSpec *ast.TypeSpec) (types.TypeKind, bool) {
+	if typeSpec.Assign.IsValid() {
+		return types.AliasTypeKind, true
+	}
+	switch typeSpec.Type.(type) {
+	case *ast.StructType, *ast.InterfaceType:
+		return 0, false
+	case *ast.FuncType:
+		return types.FuncTypeKind, true
+	case *ast.ArrayType, *ast.MapType, *ast.ChanType:
+		return types.CollectionTypeKind, true
+	default:
+		return types.DefinedTypeKind, true
+	}
+}
+
+// extractValues はASTファイルからパッケージレベルの変数・定数宣言を解析します。
+// ブランク識別子（_）は対象外です。型が省略された定数は直前の型指定を引き継がず、型を空文字とします。
+func extractValues(f *ast.File, fset *token.FileSet, file string, pkgName string, pkgPath string) []ValueInfo {
+	var values []ValueInfo
+	for _, decl := range f.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || (genDecl.Tok != token.VAR && genDecl.Tok != token.CONST) {
+			continue
+		}
+		kind := types.VarKind
+		if genDecl.Tok == token.CONST {
+			kind = types.ConstKind
+		}
+		for _, spec := range genDecl.Specs {
+			valueSpec, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			typeString := ""
+			if valueSpec.Type != nil {
+				typeString = exprToTypeString(valueSpec.Type)
+			}
+			for _, name := range valueSpec.Names {
+				if name.Name == "_" {
+					continue
+				}
+				values = append(values, ValueInfo{
+					Name:        name.Name,
+					Package:     pkgName,
+					PackagePath: pkgPath,
+					File:        file,
+					Position:    fset.Position(name.Pos()),
+					Kind:        kind,
+					Type:        typeString,
+				})
+			}
+		}
+	}
+	return values
+}
+
+// extractGoroutines はgoroutineとして起動される関数リテラルのうち、ループやselectを含み長時間動作するものを抽出します。
+// 起動元の関数・メソッドと起動箇所を記録し、ノードIDは依存関係の抽出と同じ規則で決まります。
+func extractGoroutines(f *ast.File, fset *token.FileSet, file string, pkgPath string) []GoroutineInfo {
+	var goroutines []GoroutineInfo
+	for _, c := range extraction.GoroutineClosures(f, types.PackageID(pkgPath, f.Name.Name)) {
+		goroutines = append(goroutines, GoroutineInfo{
+			Name:        c.Name,
+			Package:     f.Name.Name,
+			PackagePath: pkgPath,
+			File:        file,
+			Position:    fset.Position(c.Go.Pos()),
+			Spawner:     c.Spawner,
+		})
+	}
+	return goroutines
+}
+
+// extractInterfaceMethods はインターフェース型からメソッドシグネチャと埋め込み型を抽出します。
+// 埋め込み型は型名の文字列として返し、メソッドセットの展開は実装関係の解析時に行います。
+// メソッドのレシーバにはインターフェース名を設定し、インターフェースメソッドノードとして扱えるようにします。
+func extractInterfaceMethods(t *ast.InterfaceType, ifaceName string, fset *token.FileSet, file string, pkgName string, pkgPath string) ([]FuncInfo, []string) {
+	methods := []FuncInfo{}
+	embeds := []string{}
+	if t.Methods == nil {
+		return methods, embeds
+	}
+	for _, field := range t.Methods.List {
+		funcType, ok := field.Type.(*ast.FuncType)
+		if !ok || len(field.Names) == 0 {
+			// 埋め込みインターフェース（型制約の型要素も含む）
+			embeds = append(embeds, exprToTypeString(field.Type))
+			continue
+		}
+		for _, name := range field.Names {
+			methods = append(methods, FuncInfo{
+				Name:        name.Name,
+				Package:     pkgName,
+				PackagePath: pkgPath,
+				File:        file,
+				Position:    fset.Position(name.Pos()),
+				Receiver:    ifaceName,
+				Params:      extractFieldList(funcType.Params),
+				Results:     extractFieldList(funcType.Results),
+			})
+		}
+	}
+	return methods, embeds
+}
+
+// moduleOf はファイルが属するモジュールのパスを返します。
+// importパスが不明なファイル（go.modの外のファイル）の場合は空文字を返します。
+func moduleOf(file string, pkgPath string) string {
+	if pkgPath == "" {
+		return ""
+	}
+	if module := utils.FindModule(filepath.Dir(file)); module != nil {
+		return module.Path
+	}
+	return ""
+}
+
 // analyzeFile は単一のGoファイルのASTを走査し、構造体・インターフェース・関数・メソッドを抽出します。
 // パッケージ情報、import文、型宣言、関数宣言を順序立てて処理し、
 // 抽出した情報を結果オブジェクトに追加します。
-func analyzeFile(f *ast.File, fset *token.FileSet, file string, result *Result) {
+// pkgPathはファイルが属するパッケージのimportパスで、ノードIDの修飾に使用されます。
+func analyzeFile(f *ast.File, fset *token.FileSet, file string, pkgPath string, result *Result) {
 	pkgName := f.Name.Name
 
 	// 0th pass: import文の解析
@@ -386,7 +887,8 @@ func analyzeFile(f *ast.File, fset *token.FileSet, file string, result *Result)
 	pos := fset.Position(f.Name.Pos())
 	packageInfo := PackageInfo{
 		Name:     pkgName,
-		Path:     "", // TODO: パッケージパスの取得
+		Path:     pkgPath,
+		Module:   moduleOf(file, pkgPath),
 		File:     file,
 		Position: pos,
 		Imports:  imports,
@@ -394,16 +896,29 @@ func analyzeFile(f *ast.File, fset *token.FileSet, file string, result *Result)
 	result.Packages = append(result.Packages, packageInfo)
 
 	// 1st pass: type宣言（構造体・インターフェース）
-	structs, interfaces, structMap := extractTypes(f, fset, file, pkgName)
+	structs, interfaces, structMap := extractTypes(f, fset, file, pkgName, pkgPath)
 	result.Structs = append(result.Structs, structs...)
 	result.Interfaces = append(result.Interfaces, interfaces...)
+	result.Types = append(result.Types, extractNamedTypes(f, fset, file, pkgName, pkgPath)...)
 
 	// 2nd pass: 関数・メソッド
-	functions := extractFunctions(f, fset, file, pkgName, structMap)
-	result.Functions = append(result.Functions, functions...)
+	functions, methods := extractFunctions(f, fset, file, pkgName, pkgPath, structMap)
+	for _, fn := range functions {
+		// init関数は呼び出されることがないため通常の関数と区別し、パッケージごとに1つのノードとして扱う
+		if fn.Name == "init" {
+			result.Inits = append(result.Inits, fn)
+			continue
+		}
+		result.Functions = append(result.Functions, fn)
+	}
+	result.Methods = append(result.Methods, methods...)
 
 	// 構造体リストの更新（メソッドが追加されたstructMapの内容を反映）
+	// 同名の構造体が別パッケージに存在する場合があるため、パッケージも一致するものだけを更新
 	for i, structInfo := range result.Structs {
+		if structInfo.PackagePath != pkgPath || structInfo.Package != pkgName {
+			continue
+		}
 		if s, exists := structMap[structInfo.Name]; exists {
 			result.Structs[i] = *s
 		}
@@ -411,8 +926,9 @@ func analyzeFile(f *ast.File, fset *token.FileSet, file string, result *Result)
 }
 
 // exprToTypeString はASTの型表現を文字列に変換するユーティリティ関数です。
-// 基本型、ポインタ、セレクタ、配列、マップ、インターフェース等の
-// 型表現を適切な文字列表現に変換します。
+// 基本型、ポインタ、セレクタ、スライス・固定長配列、マップ、チャネル、関数型、可変長引数、
+// 構造体・インターフェースリテラル、括弧付きの型、ジェネリック型のインスタンス化、型制約の要素など
+// Goの型表現全体を、入れ子の組み合わせ（例: map[string][]*pkg.T）も含めて文字列に変換します。
 func exprToTypeString(expr ast.Expr) string {
 	switch t := expr.(type) {
 	case *ast.Ident:
@@ -421,17 +937,117 @@ func exprToTypeString(expr ast.Expr) string {
 		return "*" + exprToTypeString(t.X)
 	case *ast.SelectorExpr:
 		return exprToTypeString(t.X) + "." + t.Sel.Name
+	case *ast.ParenExpr:
+		return "(" + exprToTypeString(t.X) + ")"
+	case *ast.Ellipsis:
+		// 可変長引数（例: ...string）
+		return "..." + exprToTypeString(t.Elt)
+	case *ast.BasicLit:
+		// 固定長配列の長さ（例: [16]byte の 16）
+		return t.Value
 	case *ast.ArrayType:
-		return "[]" + exprToTypeString(t.Elt)
+		if t.Len == nil {
+			return "[]" + exprToTypeString(t.Elt)
+		}
+		return "[" + exprToTypeString(t.Len) + "]" + exprToTypeString(t.Elt)
 	case *ast.MapType:
 		return "map[" + exprToTypeString(t.Key) + "]" + exprToTypeString(t.Value)
+	case *ast.StructType:
+		return "struct{" + strings.Join(fieldListStrings(t.Fields, " "), "; ") + "}"
 	case *ast.InterfaceType:
-		return "interface{}"
+		return "interface{" + strings.Join(fieldListStrings(t.Methods, ""), "; ") + "}"
+	case *ast.ChanType:
+		switch t.Dir {
+		case ast.SEND:
+			return "chan<- " + exprToTypeString(t.Value)
+		case ast.RECV:
+			return "<-chan " + exprToTypeString(t.Value)
+		default:
+			return "chan " + exprToTypeString(t.Value)
+		}
+	case *ast.FuncType:
+		return funcTypeToString(t)
+	case *ast.IndexExpr:
+		// ジェネリック型のインスタンス化（例: Repo[User]）
+		return exprToTypeString(t.X) + "[" + exprToTypeString(t.Index) + "]"
+	case *ast.IndexListExpr:
+		// 複数の型引数によるインスタンス化（例: Pair[K, V]）
+		args := make([]string, len(t.Indices))
+		for i, index := range t.Indices {
+			args[i] = exprToTypeString(index)
+		}
+		return exprToTypeString(t.X) + "[" + strings.Join(args, ", ") + "]"
+	case *ast.UnaryExpr:
+		// 型制約の近似要素（例: ~int）
+		return t.Op.String() + exprToTypeString(t.X)
+	case *ast.BinaryExpr:
+		// 型制約の和集合要素（例: ~int | ~string）や配列長の定数式
+		return exprToTypeString(t.X) + " " + t.Op.String() + " " + exprToTypeString(t.Y)
 	default:
 		return "unknown"
 	}
 }
 
+// fieldListStrings は構造体のフィールドやインターフェースの要素を "名前 型" 形式の文字列一覧に変換します。
+// インターフェースのメソッドは "Name(int) error" のように名前とシグネチャを連結し、
+// 埋め込みフィールド・埋め込み型は型のみを返します。
+func fieldListStrings(list *ast.FieldList, sep string) []string {
+	var out []string
+	if list == nil {
+		return out
+	}
+	for _, field := range list.List {
+		if len(field.Names) == 0 {
+			out = append(out, exprToTypeString(field.Type))
+			continue
+		}
+		typeStr := exprToTypeString(field.Type)
+		if funcType, ok := field.Type.(*ast.FuncType); ok && sep == "" {
+			// インターフェースのメソッドは "func" を除いたシグネチャを名前に続ける
+			typeStr = strings.TrimPrefix(funcTypeToString(funcType), "func")
+		}
+		for _, name := range field.Names {
+			out = append(out, name.Name+sep+typeStr)
+		}
+	}
+	return out
+}
+
+// funcTypeToString は関数型を "func(int, string) error" 形式の文字列に変換します。
+// 引数名は省略し、型のみを列挙します。
+func funcTypeToString(t *ast.FuncType) string {
+	params := fieldListTypeStrings(t.Params)
+	results := fieldListTypeStrings(t.Results)
+	out := "func(" + strings.Join(params, ", ") + ")"
+	switch len(results) {
+	case 0:
+		return out
+	case 1:
+		return out + " " + results[0]
+	default:
+		return out + " (" + strings.Join(results, ", ") + ")"
+	}
+}
+
+// fieldListTypeStrings はフィールドリストの型を、名前の数だけ繰り返した文字列一覧に変換します。
+func fieldListTypeStrings(list *ast.FieldList) []string {
+	var typeStrings []string
+	if list == nil {
+		return typeStrings
+	}
+	for _, field := range list.List {
+		typeStr := exprToTypeString(field.Type)
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			typeStrings = append(typeStrings, typeStr)
+		}
+	}
+	return typeStrings
+}
+
 // extractBodyCalls は関数本体から呼び出している関数名リストを抽出します。
 // 関数呼び出し、メソッド呼び出し、構造体リテラルの作成等を検出し、
 // 依存関係分析のための呼び出し情報を収集します。
@@ -443,8 +1059,14 @@ func extractBodyCalls(body *ast.BlockStmt) []string {
 	ast.Inspect(body, func(n ast.Node) bool {
 		switch node := n.(type) {
 		case *ast.CallExpr:
-			// 関数呼び出し
-			switch fun := node.Fun.(type) {
+			// 関数呼び出し（ジェネリック関数の明示的なインスタンス化は関数名のみを対象とする）
+			fun := ast.Unparen(node.Fun)
+			if index, ok := fun.(*ast.IndexExpr); ok {
+				fun = index.X
+			} else if indexList, ok := fun.(*ast.IndexListExpr); ok {
+				fun = indexList.X
+			}
+			switch fun := fun.(type) {
 			case *ast.Ident:
 				// 同一パッケージ内の関数呼び出し（例：New）
 				calls = append(calls, fun.Name)
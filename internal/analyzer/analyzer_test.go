@@ -5,10 +5,14 @@ import (
 	"go/token"
 	"os"
 	"path/filepath"
+	"reflect"
 	"slices"
+	"strings"
 	"testing"
 
+	"github.com/harakeishi/depsee/internal/analyzer/extraction"
 	"github.com/harakeishi/depsee/internal/logger"
+	"github.com/harakeishi/depsee/internal/types"
 )
 
 func TestMain(m *testing.M) {
@@ -476,7 +480,7 @@ func TestFilters_shouldIncludeFile(t *testing.T) {
 				ExcludePackages: tt.fields.ExcludePackages,
 				ExcludeDirs:     tt.fields.ExcludeDirs,
 			}
-			got, err := f.shouldIncludeFile(tt.args.path)
+			got, err := f.shouldIncludeFile(tt.args.path, extraction.NewFileStore())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Filters.shouldIncludeFile() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -742,7 +746,7 @@ func main() {}`,
 			}
 
 			// extractTypes関数をテスト
-			structs, interfaces, structMap := extractTypes(f, fset, "test.go", "test")
+			structs, interfaces, structMap := extractTypes(f, fset, "test.go", "test", "")
 
 			// 構造体の検証
 			if len(structs) != len(tt.expectedStructs) {
@@ -954,7 +958,7 @@ func GetCurrentTime() time.Time {
 			}
 
 			// extractFunctions関数をテスト
-			functions := extractFunctions(f, fset, "test.go", "test", tt.structMap)
+			functions, _ := extractFunctions(f, fset, "test.go", "test", "", tt.structMap)
 
 			// 関数の検証
 			if len(functions) != len(tt.expectedFunctions) {
@@ -1013,3 +1017,534 @@ func GetCurrentTime() time.Time {
 		})
 	}
 }
+
+func TestExtractNamedTypes(t *testing.T) {
+	content := `package test
+
+type User struct{}
+type Reader interface{}
+type Status int
+type HandlerFunc func(w Writer, code int) error
+type IDs []ID
+type Index map[string]*User
+type Events <-chan User
+type Account = User
+type Admin User
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "test.go", content, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test content: %v", err)
+	}
+
+	expected := []TypeInfo{
+		{Name: "Status", Kind: types.DefinedTypeKind, Underlying: "int"},
+		{Name: "HandlerFunc", Kind: types.FuncTypeKind, Underlying: "func(Writer, int) error"},
+		{Name: "IDs", Kind: types.CollectionTypeKind, Underlying: "[]ID"},
+		{Name: "Index", Kind: types.CollectionTypeKind, Underlying: "map[string]*User"},
+		{Name: "Events", Kind: types.CollectionTypeKind, Underlying: "<-chan User"},
+		{Name: "Account", Kind: types.AliasTypeKind, Underlying: "User"},
+		{Name: "Admin", Kind: types.DefinedTypeKind, Underlying: "User"},
+	}
+
+	namedTypes := extractNamedTypes(f, fset, "test.go", "test", "")
+	if len(namedTypes) != len(expected) {
+		t.Fatalf("extractNamedTypes() returned %d types, expected %d", len(namedTypes), len(expected))
+	}
+	for i, want := range expected {
+		got := namedTypes[i]
+		if got.Name != want.Name || got.Kind != want.Kind || got.Underlying != want.Underlying {
+			t.Errorf("namedTypes[%d] = {%s %s %q}, expected {%s %s %q}", i, got.Name, got.Kind, got.Underlying, want.Name, want.Kind, want.Underlying)
+		}
+		if got.Package != "test" {
+			t.Errorf("namedTypes[%d].Package = %q, expected %q", i, got.Package, "test")
+		}
+	}
+
+	// エイリアスは構造体として重複して抽出されない
+	structs, _, _ := extractTypes(f, fset, "test.go", "test", "")
+	if len(structs) != 1 || structs[0].Name != "User" {
+		t.Errorf("extractTypes() should only return User, got %v", structs)
+	}
+}
+
+func TestExtractValues(t *testing.T) {
+	content := `package test
+
+const MaxRetry = 3
+
+const (
+	ModeRead Mode = iota
+	ModeWrite
+)
+
+var (
+	DefaultClient *Client
+	registry      = map[string]Handler{}
+	_             = registry
+)
+
+func init() {}
+func init() {}
+func Run() {}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "test.go", content, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test content: %v", err)
+	}
+
+	expected := []ValueInfo{
+		{Name: "MaxRetry", Kind: types.ConstKind, Type: ""},
+		{Name: "ModeRead", Kind: types.ConstKind, Type: "Mode"},
+		{Name: "ModeWrite", Kind: types.ConstKind, Type: ""},
+		{Name: "DefaultClient", Kind: types.VarKind, Type: "*Client"},
+		{Name: "registry", Kind: types.VarKind, Type: ""},
+	}
+
+	values := extractValues(f, fset, "test.go", "test", "")
+	if len(values) != len(expected) {
+		t.Fatalf("extractValues() returned %d values, expected %d", len(values), len(expected))
+	}
+	for i, want := range expected {
+		got := values[i]
+		if got.Name != want.Name || got.Kind != want.Kind || got.Type != want.Type {
+			t.Errorf("values[%d] = {%s %s %q}, expected {%s %s %q}", i, got.Name, got.Kind, got.Type, want.Name, want.Kind, want.Type)
+		}
+	}
+
+	// init関数は通常の関数一覧に含まれず、init関数一覧にまとめられる
+	result := &Result{}
+	analyzeFile(f, fset, "test.go", "", result)
+	if len(result.Functions) != 1 || result.Functions[0].Name != "Run" {
+		t.Errorf("Functions should only contain Run, got %v", result.Functions)
+	}
+	if len(result.Inits) != 2 {
+		t.Fatalf("Inits should contain 2 init functions, got %d", len(result.Inits))
+	}
+	if result.Inits[0].NodeID() != types.NewNodeID("test", "init") || result.Inits[1].NodeID() != result.Inits[0].NodeID() {
+		t.Errorf("init functions of a package should share one node ID, got %s and %s", result.Inits[0].NodeID(), result.Inits[1].NodeID())
+	}
+}
+
+func TestExtractGoroutines(t *testing.T) {
+	content := `package worker
+
+func Start(jobs chan int) {
+	go func() {
+		for range jobs {
+		}
+	}()
+	go func() {}()
+}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "worker.go", content, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test content: %v", err)
+	}
+
+	// ループを含まない短いgoroutineはノードにしない
+	goroutines := extractGoroutines(f, fset, "worker.go", "example.com/app/worker")
+	if len(goroutines) != 1 {
+		t.Fatalf("extractGoroutines() returned %d goroutines, expected 1", len(goroutines))
+	}
+	got := goroutines[0]
+	if got.NodeID() != "example.com/app/worker.Start.go#1" {
+		t.Errorf("NodeID() = %s, expected example.com/app/worker.Start.go#1", got.NodeID())
+	}
+	if got.Spawner != "example.com/app/worker.Start" || got.File != "worker.go" || got.Position.Line != 4 {
+		t.Errorf("Unexpected goroutine: spawner=%s file=%s line=%d", got.Spawner, got.File, got.Position.Line)
+	}
+}
+
+func TestExtractGenericDeclarations(t *testing.T) {
+	content := `package test
+
+type Number interface {
+	~int | ~float64
+}
+
+type Repo[T Entity] struct {
+	items map[ID]T
+}
+
+type Pair[K comparable, V any] struct{}
+
+func Map[T Entity, R any](items []Repo[T], pair Pair[T, R]) []R {
+	return nil
+}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "test.go", content, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test content: %v", err)
+	}
+
+	structs, interfaces, _ := extractTypes(f, fset, "test.go", "test", "")
+	functions, _ := extractFunctions(f, fset, "test.go", "test", "", map[string]*StructInfo{})
+
+	assertFields := func(label string, got []FieldInfo, expected []FieldInfo) {
+		t.Helper()
+		if !slices.Equal(got, expected) {
+			t.Errorf("%s = %v, expected %v", label, got, expected)
+		}
+	}
+
+	assertFields("Repo.TypeParams", structs[0].TypeParams, []FieldInfo{{Name: "T", Type: "Entity"}})
+	assertFields("Repo.Fields", structs[0].Fields, []FieldInfo{{Name: "items", Type: "map[ID]T"}})
+	assertFields("Pair.TypeParams", structs[1].TypeParams, []FieldInfo{{Name: "K", Type: "comparable"}, {Name: "V", Type: "any"}})
+	assertFields("Map.TypeParams", functions[0].TypeParams, []FieldInfo{{Name: "T", Type: "Entity"}, {Name: "R", Type: "any"}})
+	assertFields("Map.Params", functions[0].Params, []FieldInfo{{Name: "items", Type: "[]Repo[T]"}, {Name: "pair", Type: "Pair[T, R]"}})
+
+	if !slices.Equal(interfaces[0].Embeds, []string{"~int | ~float64"}) {
+		t.Errorf("Number.Embeds = %v, expected [~int | ~float64]", interfaces[0].Embeds)
+	}
+}
+
+func TestExprToTypeString(t *testing.T) {
+	tests := []string{
+		"int",
+		"*pkg.T",
+		"map[string][]*pkg.T",
+		"[16]byte",
+		"[Size]Item",
+		"chan *Event",
+		"chan<- int",
+		"<-chan Event",
+		"func(*Request) error",
+		"func(int, ...string) (Result, error)",
+		"func()",
+		"struct{}",
+		"struct{Name string; pkg.Embedded}",
+		"interface{}",
+		"interface{Read([]byte) (int, error); io.Closer}",
+		"(*Wrapper)",
+		"Repo[User]",
+		"Pair[K, map[string]V]",
+		"~int | ~float64",
+	}
+
+	for _, src := range tests {
+		t.Run(src, func(t *testing.T) {
+			expr, err := parser.ParseExpr(src)
+			if err != nil {
+				t.Fatalf("Failed to parse expression: %v", err)
+			}
+			if got := exprToTypeString(expr); got != src {
+				t.Errorf("exprToTypeString(%s) = %q", src, got)
+			}
+		})
+	}
+}
+
+func TestAnalyze_CrossPackageTypeDependencies(t *testing.T) {
+	const pkg1 = "github.com/harakeishi/depsee/testdata/multi-package/pkg1"
+	const pkg2 = "github.com/harakeishi/depsee/testdata/multi-package/pkg2"
+	expected := []DependencyInfo{
+		{From: types.NewNodeID(pkg1, "User"), To: types.NewNodeID(pkg2, "Profile"), Type: types.FieldDependency},
+		{From: types.NewNodeID(pkg1, "User"), To: types.NewNodeID(pkg2, "Settings"), Type: types.FieldDependency},
+		{From: types.NewNodeID(pkg1, "GetUserProfile"), To: types.NewNodeID(pkg2, "Profile"), Type: types.SignatureDependency},
+	}
+
+	for _, typeCheck := range []bool{false, true} {
+		ga := &GoAnalyzer{}
+		ga.SetOptions(Options{TypeCheck: typeCheck})
+		if err := ga.ListTartgetFiles("../../testdata/multi-package"); err != nil {
+			t.Fatalf("ファイル一覧取得エラー: %v", err)
+		}
+		if err := ga.Analyze(); err != nil {
+			t.Fatalf("解析エラー: %v", err)
+		}
+		for _, want := range expected {
+			i := slices.IndexFunc(ga.Result.Dependencies, func(dep DependencyInfo) bool {
+				return dep.From == want.From && dep.To == want.To && dep.Type == want.Type
+			})
+			if i < 0 {
+				t.Errorf("TypeCheck=%v: 依存関係 %s -> %s (%s) が見つかりません", typeCheck, want.From, want.To, want.Type)
+				continue
+			}
+			if pos := ga.Result.Dependencies[i].Position; !pos.IsValid() || !strings.HasSuffix(pos.Filename, "models.go") {
+				t.Errorf("TypeCheck=%v: 依存関係 %s -> %s の位置が不正です: %v", typeCheck, want.From, want.To, pos)
+			}
+		}
+	}
+}
+
+func TestAnalyze_TypeCheckOutsideModule(t *testing.T) {
+	// 作業ディレクトリ（depseeのモジュール）ではなく解析対象のモジュールを基準にimportを解決する
+	dir := t.TempDir()
+	writeModule(t, dir, map[string]string{
+		"go.mod":         "module example.com/app\n",
+		"store/user.go":  "package store\n\ntype User struct {\n\tName string\n}\n",
+		"svc/service.go": "package svc\n\nimport \"example.com/app/store\"\n\ntype Saver interface {\n\tSave(u *store.User) error\n}\n",
+	})
+
+	ga := &GoAnalyzer{}
+	ga.SetOptions(Options{TypeCheck: true})
+	if err := ga.ListTartgetFiles(dir); err != nil {
+		t.Fatalf("ファイル一覧取得エラー: %v", err)
+	}
+	if err := ga.Analyze(); err != nil {
+		t.Fatalf("解析エラー: %v", err)
+	}
+
+	from := types.NewNodeID("example.com/app/svc", "Saver")
+	to := types.NewNodeID("example.com/app/store", "User")
+	if !slices.ContainsFunc(ga.Result.Dependencies, func(dep DependencyInfo) bool {
+		return dep.From == from && dep.To == to && dep.Type == types.SignatureDependency
+	}) {
+		t.Errorf("依存関係 %s -> %s が見つかりません: %v", from, to, ga.Result.Dependencies)
+	}
+}
+
+func TestAnalyze_GenericsAcrossPackages(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, map[string]string{
+		"go.mod":         "module example.com/app\n",
+		"model/model.go": "package model\n\ntype Entity interface {\n\tID() int\n}\n\ntype User struct{}\n\nfunc (u *User) ID() int { return 0 }\n\ntype Repo[T Entity] struct{}\n",
+		"svc/service.go": "package svc\n\nimport \"example.com/app/model\"\n\ntype Cache[T model.Entity] struct {\n\trepo model.Repo[T]\n}\n\nfunc Find() {\n\tvar r model.Repo[*model.User]\n\t_ = r\n}\n",
+	})
+	model := func(name string) types.NodeID { return types.NewNodeID("example.com/app/model", name) }
+	cache := types.NewNodeID("example.com/app/svc", "Cache")
+	find := types.NewNodeID("example.com/app/svc", "Find")
+	expected := []DependencyInfo{
+		{From: cache, To: model("Entity"), Type: types.ConstraintDependency},
+		{From: cache, To: model("Repo"), Type: types.InstantiationDependency},
+		{From: find, To: model("Repo"), Type: types.InstantiationDependency},
+		{From: find, To: model("User"), Type: types.TypeUsageDependency},
+	}
+
+	for _, typeCheck := range []bool{false, true} {
+		ga := &GoAnalyzer{}
+		ga.SetOptions(Options{TypeCheck: typeCheck})
+		if err := ga.ListTartgetFiles(dir); err != nil {
+			t.Fatalf("ファイル一覧取得エラー: %v", err)
+		}
+		if err := ga.Analyze(); err != nil {
+			t.Fatalf("解析エラー: %v", err)
+		}
+		for _, want := range expected {
+			if !slices.ContainsFunc(ga.Result.Dependencies, func(dep DependencyInfo) bool {
+				return dep.From == want.From && dep.To == want.To && dep.Type == want.Type
+			}) {
+				t.Errorf("TypeCheck=%v: 依存関係 %s -> %s (%s) が見つかりません", typeCheck, want.From, want.To, want.Type)
+			}
+		}
+	}
+}
+
+func TestAnalyze_ReusesFilesParsedByFilter(t *testing.T) {
+	ga := &GoAnalyzer{}
+	if err := ga.ListTartgetFiles("../../testdata/multi-package"); err != nil {
+		t.Fatalf("ファイル一覧取得エラー: %v", err)
+	}
+	if err := ga.Analyze(); err != nil {
+		t.Fatalf("解析エラー: %v", err)
+	}
+
+	if len(ga.parsedFiles) != len(ga.filesPath) {
+		t.Fatalf("解析済みファイル数が一致しません。期待値: %d, 実際: %d", len(ga.filesPath), len(ga.parsedFiles))
+	}
+	// フィルタ適用時にパースしたASTとFileSetがそのまま解析に使われる
+	for _, pf := range ga.parsedFiles {
+		cached, err := ga.store.Parse(pf.Path)
+		if err != nil {
+			t.Fatalf("パースエラー: %v", err)
+		}
+		if cached.File != pf.File || pf.FileSet != ga.store.FileSet() {
+			t.Errorf("%s が再パースされています", pf.Path)
+		}
+	}
+}
+
+func TestAnalyze_ParallelMatchesSequential(t *testing.T) {
+	analyze := func(workers int) *Result {
+		ga := &GoAnalyzer{Options: Options{TypeCheck: true, IncludeGlobals: true, Workers: workers}}
+		if err := ga.ListTartgetFiles("../../testdata"); err != nil {
+			t.Fatalf("ファイル一覧取得エラー: %v", err)
+		}
+		if err := ga.Analyze(); err != nil {
+			t.Fatalf("解析エラー: %v", err)
+		}
+		return ga.Result
+	}
+
+	sequential := analyze(1)
+	if len(sequential.Dependencies) == 0 {
+		t.Fatal("依存関係が抽出されていません")
+	}
+	// ワーカー数によらず、逐次実行と同じ順序・内容の結果が得られる
+	for _, workers := range []int{2, 8} {
+		if parallel := analyze(workers); !reflect.DeepEqual(sequential, parallel) {
+			t.Errorf("workers=%d の解析結果が逐次実行と一致しません", workers)
+		}
+	}
+}
+
+func TestAnalyze_Workspace(t *testing.T) {
+	t.Setenv("GOWORK", "")
+	root := t.TempDir()
+	ws := filepath.Join(root, "ws")
+	writeModule(t, root, map[string]string{
+		"ws/go.work":         "go 1.23\n\nuse (\n\t./api\n\t../core\n)\n",
+		"ws/api/go.mod":      "module example.com/api\n",
+		"ws/api/handler.go":  "package api\n\nimport \"example.com/core/model\"\n\ntype Handler struct {\n\tUser model.User\n}\n",
+		"core/go.mod":        "module example.com/core\n",
+		"core/model/user.go": "package model\n\ntype User struct {\n\tName string\n}\n",
+	})
+
+	ga := &GoAnalyzer{}
+	if err := ga.ListTartgetFiles(ws); err != nil {
+		t.Fatalf("ファイル一覧取得エラー: %v", err)
+	}
+	// 解析対象ディレクトリの外にあるメンバー（../core）も解析対象になる
+	if len(ga.filesPath) != 2 {
+		t.Fatalf("解析対象ファイル数が不正です: %v", ga.filesPath)
+	}
+	if err := ga.Analyze(); err != nil {
+		t.Fatalf("解析エラー: %v", err)
+	}
+
+	// ワークスペース内の別モジュールへのimportは内部の依存関係として扱う
+	from := types.NewNodeID("example.com/api", "Handler")
+	to := types.NewNodeID("example.com/core/model", "User")
+	if !slices.ContainsFunc(ga.Result.Dependencies, func(dep DependencyInfo) bool {
+		return dep.From == from && dep.To == to && dep.Type == types.FieldDependency
+	}) {
+		t.Errorf("依存関係 %s -> %s が見つかりません: %v", from, to, ga.Result.Dependencies)
+	}
+
+	modules := make(map[string]string)
+	for _, pkg := range ga.Result.Packages {
+		modules[pkg.Path] = pkg.Module
+	}
+	expected := map[string]string{"example.com/api": "example.com/api", "example.com/core/model": "example.com/core"}
+	if !reflect.DeepEqual(modules, expected) {
+		t.Errorf("パッケージのモジュールが不正です: %v", modules)
+	}
+
+	// 除外したメンバーにはノードがないため、その参照はモジュール単位の外部依存ノードへの依存関係になる
+	ga = &GoAnalyzer{
+		Filters: Filters{ExcludeDirs: []string{filepath.Join(root, "core")}},
+		Options: Options{IncludeExternal: true},
+	}
+	if err := ga.ListTartgetFiles(ws); err != nil {
+		t.Fatalf("ファイル一覧取得エラー: %v", err)
+	}
+	if err := ga.Analyze(); err != nil {
+		t.Fatalf("解析エラー: %v", err)
+	}
+	external := types.NewExternalNodeID("example.com/core")
+	for _, dep := range ga.Result.Dependencies {
+		if dep.To == to {
+			t.Errorf("解析対象でないモジュールへの依存関係が抽出されています: %v", dep)
+		}
+	}
+	if !slices.ContainsFunc(ga.Result.Dependencies, func(dep DependencyInfo) bool {
+		return dep.From == from && dep.To == external && dep.Type == types.ExternalDependency
+	}) {
+		t.Errorf("依存関係 %s -> %s が見つかりません: %v", from, external, ga.Result.Dependencies)
+	}
+}
+
+func TestAnalyze_IncludeTests(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, map[string]string{
+		"go.mod":               "module example.com/app\n",
+		"user.go":              "package app\n\ntype User struct{}\n\nfunc NewUser() *User { return &User{} }\n",
+		"user_test.go":         "package app\n\nimport (\n\t\"testing\"\n\n\t\"example.com/app/testutil\"\n)\n\nfunc TestNewUser(t *testing.T) {\n\ttestutil.Check(t, NewUser())\n}\n",
+		"user_ext_test.go":     "package app_test\n\nimport \"example.com/app\"\n\nfunc newUser() *app.User {\n\treturn app.NewUser()\n}\n",
+		"testutil/testutil.go": "package testutil\n\nfunc Check(t any, v any) {}\n",
+	})
+	analyze := func(includeTests bool) *Result {
+		t.Helper()
+		ga := &GoAnalyzer{Options: Options{IncludeTests: includeTests}}
+		if err := ga.ListTartgetFiles(dir); err != nil {
+			t.Fatalf("ファイル一覧取得エラー: %v", err)
+		}
+		if err := ga.Analyze(); err != nil {
+			t.Fatalf("解析エラー: %v", err)
+		}
+		return ga.Result
+	}
+	hasDependency := func(result *Result, from, to types.NodeID, depType types.DependencyType) bool {
+		return slices.ContainsFunc(result.Dependencies, func(dep DependencyInfo) bool {
+			return dep.From == from && dep.To == to && dep.Type == depType
+		})
+	}
+
+	// 既定ではテストファイルを解析しない
+	if result := analyze(false); slices.ContainsFunc(result.Functions, func(f FuncInfo) bool { return strings.HasSuffix(f.File, "_test.go") }) {
+		t.Errorf("テストファイルの関数が抽出されています: %v", result.Functions)
+	}
+
+	result := analyze(true)
+	tests := []struct {
+		from, to types.NodeID
+		depType  types.DependencyType
+	}{
+		// 内部テストはテスト対象と同じパッケージのノードになる
+		{"example.com/app.TestNewUser", "example.com/app.NewUser", types.BodyCallDependency},
+		// 外部テストパッケージはテスト対象とは別のパッケージとして、importを通じて本番コードに依存する
+		{"example.com/app_test.newUser", "example.com/app.User", types.SignatureDependency},
+		{"example.com/app_test.newUser", "example.com/app.NewUser", types.CrossPackageDependency},
+		{"package:example.com/app_test", "package:example.com/app", types.PackageDependency},
+	}
+	for _, tt := range tests {
+		if !hasDependency(result, tt.from, tt.to, tt.depType) {
+			t.Errorf("依存関係 %s -> %s (%s) が見つかりません: %v", tt.from, tt.to, tt.depType, result.Dependencies)
+		}
+	}
+	// 内部テストファイルのimportはテスト対象のパッケージの依存関係にしない
+	if hasDependency(result, "package:example.com/app", "package:example.com/app/testutil", types.PackageDependency) {
+		t.Error("内部テストファイルのimportがパッケージの依存関係になっています")
+	}
+}
+
+func TestListTargetFiles_Generated(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, map[string]string{
+		"go.mod":         "module example.com/app\n",
+		"service.go":     "package app\n\ntype Service struct {\n\tReq *UserRequest\n}\n",
+		"user.pb.go":     "// Code generated by protoc-gen-go. DO NOT EDIT.\n// source: user.proto\n\npackage app\n\ntype UserRequest struct{}\n",
+		"mock_store.go":  "// Code generated by MockGen. DO NOT EDIT.\n\n//go:build !nomock\n\npackage app\n\ntype MockStore struct{}\n",
+		"handwritten.go": "package app\n\n// Code generated by hand. DO NOT EDIT.\n\ntype Note struct{}\n",
+	})
+	analyze := func(options Options) *GoAnalyzer {
+		t.Helper()
+		ga := &GoAnalyzer{Options: options}
+		if err := ga.ListTartgetFiles(dir); err != nil {
+			t.Fatalf("ファイル一覧取得エラー: %v", err)
+		}
+		if err := ga.Analyze(); err != nil {
+			t.Fatalf("解析エラー: %v", err)
+		}
+		return ga
+	}
+
+	// package句より前にある標準のヘッダを持つファイルを生成コードとして記録する
+	ga := analyze(Options{})
+	expected := []string{filepath.Join(dir, "mock_store.go"), filepath.Join(dir, "user.pb.go")}
+	if !reflect.DeepEqual(ga.Result.GeneratedFiles, expected) {
+		t.Errorf("GeneratedFiles = %v, expected %v", ga.Result.GeneratedFiles, expected)
+	}
+	if !slices.ContainsFunc(ga.Result.Dependencies, func(dep DependencyInfo) bool {
+		return dep.From == "example.com/app.Service" && dep.To == "example.com/app.UserRequest"
+	}) {
+		t.Errorf("生成コードへの依存関係が見つかりません: %v", ga.Result.Dependencies)
+	}
+
+	// ExcludeGenerated指定時は生成コードを解析対象から除外する
+	ga = analyze(Options{ExcludeGenerated: true})
+	var names []string
+	for _, path := range ga.filesPath {
+		names = append(names, filepath.Base(path))
+	}
+	if expected := []string{"handwritten.go", "service.go"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("解析対象ファイル = %v, expected %v", names, expected)
+	}
+	if len(ga.Result.GeneratedFiles) != 0 {
+		t.Errorf("除外した生成コードが記録されています: %v", ga.Result.GeneratedFiles)
+	}
+}
@@ -0,0 +1,197 @@
+package analyzer
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/harakeishi/depsee/internal/analyzer/extraction"
+	"github.com/harakeishi/depsee/internal/cache"
+	"github.com/harakeishi/depsee/internal/logger"
+	"github.com/harakeishi/depsee/internal/utils"
+)
+
+// newCache は解析設定をソルトとしたキャッシュを作成します。
+// バージョン、解析方法、フィルタのいずれかが変わると以前のエントリは全て使われなくなります。
+func (ga *GoAnalyzer) newCache() *cache.Cache {
+	return cache.New(ga.Options.CacheDir,
+		ga.Options.Version,
+		"target="+ga.targetDir,
+		"type-check="+strconv.FormatBool(ga.Options.TypeCheck),
+		"include-globals="+strconv.FormatBool(ga.Options.IncludeGlobals),
+		"include-external="+strconv.FormatBool(ga.Options.IncludeExternal),
+		"include-stdlib="+strconv.FormatBool(ga.Options.IncludeStdlib),
+		"include-tests="+strconv.FormatBool(ga.Options.IncludeTests),
+		"exclude-generated="+strconv.FormatBool(ga.Options.ExcludeGenerated),
+		"goroutine-nodes="+strconv.FormatBool(ga.Options.GoroutineNodes),
+		fmt.Sprintf("filters=%q", ga.Filters),
+	)
+}
+
+// loadFileResult はファイル単位の解析結果をキャッシュから取得します。
+// キーにはファイルの内容のハッシュを含むため、変更されたファイルはキャッシュされていない扱いになります。
+func (ga *GoAnalyzer) loadFileResult(pf *extraction.ParsedFile) (*Result, bool) {
+	if ga.cache == nil {
+		return nil, false
+	}
+	var fragment Result
+	if !ga.cache.Load(fileCacheKey(ga.cache, pf), &fragment) {
+		return nil, false
+	}
+	return &fragment, true
+}
+
+// storeFileResult はファイル単位の解析結果をキャッシュに保存します。
+func (ga *GoAnalyzer) storeFileResult(pf *extraction.ParsedFile, fragment *Result) {
+	if ga.cache == nil {
+		return
+	}
+	if err := ga.cache.Store(fileCacheKey(ga.cache, pf), fragment); err != nil {
+		logger.Warn("キャッシュ保存失敗", "file", pf.Path, "error", err)
+	}
+}
+
+func fileCacheKey(c *cache.Cache, pf *extraction.ParsedFile) string {
+	return c.Key("file", pf.Path, pf.PackagePath, pf.Hash)
+}
+
+// packageCache はパッケージ単位の依存関係をキャッシュに保存するextraction.PackageCacheの実装です。
+type packageCache struct {
+	cache *cache.Cache
+	keys  map[string]string // パッケージ（ディレクトリとパッケージ名）ごとのキャッシュキー
+}
+
+// newPackageCache は各パッケージのキャッシュキーを算出してpackageCacheを作成します。
+// パッケージのキーは、パッケージ内の全ファイルの内容と、importしている解析対象パッケージのキーから算出します。
+// そのため、ファイルが変更されたパッケージに加えて、それを（推移的に）importしているパッケージも再解析されます。
+// importパスが不明なパッケージがある場合はimport関係を辿れないため、いずれかのファイルが変更されると全パッケージを再解析します。
+func newPackageCache(c *cache.Cache, packages []*extraction.ParsedPackage, strategies []string) *packageCache {
+	pc := &packageCache{cache: c, keys: make(map[string]string)}
+
+	byPath := make(map[string][]*extraction.ParsedPackage)
+	unknownPath := false
+	for _, pkg := range packages {
+		if pkg.PackagePath == "" {
+			unknownPath = true
+			continue
+		}
+		byPath[pkg.PackagePath] = append(byPath[pkg.PackagePath], pkg)
+	}
+	var global []string
+	if unknownPath {
+		for _, pkg := range packages {
+			for _, pf := range pkg.Files {
+				global = append(global, pf.Path, pf.Hash)
+			}
+		}
+	}
+
+	analyzed := extraction.AnalyzedModules(packages)
+	visiting := make(map[string]bool)
+	var keyOf func(pkg *extraction.ParsedPackage) string
+	keyOf = func(pkg *extraction.ParsedPackage) string {
+		id := packageCacheID(pkg)
+		if key, ok := pc.keys[id]; ok {
+			return key
+		}
+		if visiting[id] {
+			return "cycle" // 循環importはコンパイルできないため、解析結果に影響しない値を使う
+		}
+		visiting[id] = true
+
+		parts := []string{"package", pkg.Dir, pkg.Name, pkg.PackagePath, strings.Join(strategies, ",")}
+		parts = append(parts, global...)
+		// importの分類に使うモジュール情報（モジュールパス、解析対象のローカルへのreplace・go.workの他のメンバー、外部依存ノードの集約に使うrequire）
+		if module := utils.FindModule(pkg.Dir).Restrict(analyzed); module != nil {
+			parts = append(parts, module.Path)
+			for _, m := range slices.Concat(module.Replaces, module.Workspace) {
+				parts = append(parts, m.Path, m.Dir)
+			}
+			parts = append(parts, module.Requires...)
+		}
+		imports := make(map[string]bool)
+		for _, pf := range pkg.Files {
+			parts = append(parts, pf.Path, pf.Hash)
+			for _, imp := range pf.File.Imports {
+				imports[strings.Trim(imp.Path.Value, `"`)] = true
+			}
+		}
+		importPaths := make([]string, 0, len(imports))
+		for path := range imports {
+			importPaths = append(importPaths, path)
+		}
+		sort.Strings(importPaths)
+		for _, path := range importPaths {
+			for _, dep := range byPath[path] {
+				if dep != pkg {
+					parts = append(parts, path, keyOf(dep))
+				}
+			}
+		}
+
+		key := c.Key(parts...)
+		pc.keys[id] = key
+		visiting[id] = false
+		return key
+	}
+	for _, pkg := range packages {
+		keyOf(pkg)
+	}
+	return pc
+}
+
+func packageCacheID(pkg *extraction.ParsedPackage) string {
+	return pkg.Dir + "\x00" + pkg.Name
+}
+
+// complete は全パッケージの依存関係がキャッシュされているかどうかを返します。
+func (pc *packageCache) complete() bool {
+	for _, key := range pc.keys {
+		if !pc.cache.Has(key) {
+			return false
+		}
+	}
+	return true
+}
+
+// implementationsKey は全パッケージのキーから、実装関係のキャッシュキーを算出します。
+// 実装関係は解析対象全体から求めるため、いずれかのパッケージが変わると無効になります。
+func (pc *packageCache) implementationsKey() string {
+	keys := make([]string, 0, len(pc.keys)+1)
+	keys = append(keys, "implementations")
+	ids := make([]string, 0, len(pc.keys))
+	for id := range pc.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		keys = append(keys, pc.keys[id])
+	}
+	return pc.cache.Key(keys...)
+}
+
+// LoadPackage はパッケージの依存関係をキャッシュから取得します。
+func (pc *packageCache) LoadPackage(pkg *extraction.ParsedPackage) ([]DependencyInfo, bool) {
+	key, ok := pc.keys[packageCacheID(pkg)]
+	if !ok {
+		return nil, false
+	}
+	var deps []DependencyInfo
+	if !pc.cache.Load(key, &deps) {
+		return nil, false
+	}
+	return deps, true
+}
+
+// StorePackage はパッケージの依存関係をキャッシュに保存します。
+func (pc *packageCache) StorePackage(pkg *extraction.ParsedPackage, deps []DependencyInfo) {
+	key, ok := pc.keys[packageCacheID(pkg)]
+	if !ok {
+		return
+	}
+	if err := pc.cache.Store(key, deps); err != nil {
+		logger.Warn("キャッシュ保存失敗", "package", pkg.ID(), "error", err)
+	}
+}
@@ -0,0 +1,125 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeModule はテスト用のモジュールをdirに作成します。
+func writeModule(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAnalyze_Cache(t *testing.T) {
+	dir := t.TempDir()
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	writeModule(t, dir, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.21\n",
+		"model/model.go": `package model
+
+type User struct {
+	Name string
+}
+`,
+		"service/service.go": `package service
+
+import "example.com/app/model"
+
+type Service struct {
+	User *model.User
+}
+
+func (s *Service) Name() string { return s.User.Name }
+`,
+		"other/other.go": `package other
+
+type Clock interface {
+	Now() int64
+}
+
+type FixedClock struct{}
+
+func (FixedClock) Now() int64 { return 0 }
+`,
+	})
+
+	analyze := func(cacheDir string) *GoAnalyzer {
+		t.Helper()
+		ga := &GoAnalyzer{Options: Options{TypeCheck: true, CacheDir: cacheDir, Version: "test"}}
+		if err := ga.ListTartgetFiles(dir); err != nil {
+			t.Fatalf("ファイル一覧取得エラー: %v", err)
+		}
+		if err := ga.Analyze(); err != nil {
+			t.Fatalf("解析エラー: %v", err)
+		}
+		return ga
+	}
+	assertStats := func(ga *GoAnalyzer, wantHits, wantMisses int64) {
+		t.Helper()
+		if hits, misses := ga.cache.Stats(); hits != wantHits || misses != wantMisses {
+			t.Errorf("キャッシュのヒット数・ミス数 = (%d, %d), 期待値: (%d, %d)", hits, misses, wantHits, wantMisses)
+		}
+	}
+
+	uncached := analyze("")
+	if uncached.cache != nil {
+		t.Fatal("CacheDirが空の場合はキャッシュを使用しません")
+	}
+
+	// 初回はファイル3つ・パッケージ3つを解析して保存する（実装関係は型チェックから求める）
+	first := analyze(cacheDir)
+	assertStats(first, 0, 6)
+	if !reflect.DeepEqual(uncached.Result, first.Result) {
+		t.Error("初回の解析結果がキャッシュなしの結果と一致しません")
+	}
+
+	// 変更がなければ全てキャッシュから取得し、型チェックも省略する
+	second := analyze(cacheDir)
+	assertStats(second, 7, 0)
+	if second.resolvers != nil {
+		t.Error("全てキャッシュ済みの場合は型チェックを省略します")
+	}
+	if !reflect.DeepEqual(uncached.Result, second.Result) {
+		t.Error("キャッシュから取得した解析結果がキャッシュなしの結果と一致しません")
+	}
+
+	// modelを変更すると、model.goとmodel・それをimportするserviceパッケージだけを再解析する
+	// （型チェックを行うため、実装関係はキャッシュを参照せずに求め直す）
+	writeModule(t, dir, map[string]string{
+		"model/model.go": `package model
+
+type ID int
+
+type User struct {
+	ID   ID
+	Name string
+}
+`,
+	})
+	third := analyze(cacheDir)
+	assertStats(third, 3, 3)
+	if !reflect.DeepEqual(analyze("").Result, third.Result) {
+		t.Error("変更後の解析結果がキャッシュなしの結果と一致しません")
+	}
+
+	// 解析設定が変わると以前のエントリは使われない
+	ga := &GoAnalyzer{Options: Options{CacheDir: cacheDir, Version: "test"}}
+	if err := ga.ListTartgetFiles(dir); err != nil {
+		t.Fatalf("ファイル一覧取得エラー: %v", err)
+	}
+	if err := ga.Analyze(); err != nil {
+		t.Fatalf("解析エラー: %v", err)
+	}
+	assertStats(ga, 0, 6)
+}
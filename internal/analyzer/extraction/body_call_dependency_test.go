@@ -38,35 +38,29 @@ func DeleteUser() {}`,
 			},
 		},
 		{
-			name: "組み込み関数の呼び出し",
+			name: "組み込み関数の呼び出しは除外（同名のパッケージ関数は依存関係）",
 			code: `package test
+type User struct{}
 func ProcessData(data []string) []string {
 	result := make([]string, len(data))
 	for i := range data {
 		result = append(result, data[i])
 	}
+	_ = new(User)
+	println(min(1, 2))
 	return result
-}`,
+}
+func min(a, b int) int { return a }`,
 			expected: []DependencyInfo{
 				{
 					From: types.NewNodeID("test", "ProcessData"),
-					To:   types.NewNodeID("test", "make"),
-					Type: types.BodyCallDependency,
-				},
-				{
-					From: types.NewNodeID("test", "ProcessData"),
-					To:   types.NewNodeID("test", "len"),
-					Type: types.BodyCallDependency,
-				},
-				{
-					From: types.NewNodeID("test", "ProcessData"),
-					To:   types.NewNodeID("test", "append"),
+					To:   types.NewNodeID("test", "min"),
 					Type: types.BodyCallDependency,
 				},
 			},
 		},
 		{
-			name: "メソッド呼び出し（パッケージ修飾子付きは除外）",
+			name: "メソッド呼び出しはレシーバ型のメソッドへ（パッケージ修飾子付きは除外）",
 			code: `package test
 func ProcessUser(u User) {
 	ValidateUser()
@@ -82,6 +76,11 @@ func (u User) Save() {}`,
 					To:   types.NewNodeID("test", "ValidateUser"),
 					Type: types.BodyCallDependency,
 				},
+				{
+					From: types.NewNodeID("test", "ProcessUser"),
+					To:   types.NewMethodNodeID("test", "User", "Save"),
+					Type: types.BodyCallDependency,
+				},
 			},
 		},
 		{
@@ -157,15 +156,25 @@ func fetchData() (string, error) { return "", nil }`,
 			},
 		},
 		{
-			name: "関数リテラル内の呼び出し",
+			name: "関数リテラル内の呼び出し（ローカル変数経由の呼び出しは除外）",
 			code: `package test
-func WithClosures() {
+func WithClosures(callback func()) {
 	fn := func() {
 		helper()
 	}
 	fn()
+	callback()
+	go func() {
+		worker()
+	}()
+	defer func(cleanup func()) {
+		cleanup()
+		done()
+	}(nil)
 }
-func helper() {}`,
+func helper() {}
+func worker() {}
+func done() {}`,
 			expected: []DependencyInfo{
 				{
 					From: types.NewNodeID("test", "WithClosures"),
@@ -174,7 +183,35 @@ func helper() {}`,
 				},
 				{
 					From: types.NewNodeID("test", "WithClosures"),
-					To:   types.NewNodeID("test", "fn"),
+					To:   types.NewNodeID("test", "worker"),
+					Type: types.BodyCallDependency,
+				},
+				{
+					From: types.NewNodeID("test", "WithClosures"),
+					To:   types.NewNodeID("test", "done"),
+					Type: types.BodyCallDependency,
+				},
+			},
+		},
+		{
+			name: "型変換は呼び出しではない（型引数は依存関係）",
+			code: `package test
+type UserID string
+type User struct{}
+func Map[T any](s string) T { var v T; return v }
+func Convert(s string) UserID {
+	_ = Map[User](s)
+	return UserID(s)
+}`,
+			expected: []DependencyInfo{
+				{
+					From: types.NewNodeID("test", "Convert"),
+					To:   types.NewNodeID("test", "Map"),
+					Type: types.BodyCallDependency,
+				},
+				{
+					From: types.NewNodeID("test", "Convert"),
+					To:   types.NewNodeID("test", "User"),
 					Type: types.BodyCallDependency,
 				},
 			},
@@ -229,6 +266,60 @@ func helper() {}`,
 	}
 }
 
+func TestCrossPackageDependencyExtractor_TypeConversion(t *testing.T) {
+	sources := []struct {
+		path        string
+		packagePath string
+		code        string
+	}{
+		{
+			path:        "model/model.go",
+			packagePath: "example.com/app/model",
+			code: `package model
+type ID string
+func Parse(s string) ID { return ID(s) }
+`,
+		},
+		{
+			path:        "svc/service.go",
+			packagePath: "example.com/app/svc",
+			code: `package svc
+import "example.com/app/model"
+func Lookup(name string) model.ID {
+	_ = model.Parse(name)
+	return model.ID(name)
+}
+`,
+		},
+	}
+
+	fset := token.NewFileSet()
+	var files []ParsedFile
+	for _, src := range sources {
+		file, err := parser.ParseFile(fset, src.path, src.code, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("コード解析エラー: %v", err)
+		}
+		files = append(files, ParsedFile{Path: src.path, PackagePath: src.packagePath, File: file, FileSet: fset})
+	}
+
+	extractor := NewStrategyBasedExtractor("")
+	extractor.AddStrategy(NewCrossPackageDependencyExtractor(extractor.Context()))
+	extractor.AddStrategy(NewBodyTypeUsageDependencyExtractor(extractor.Context()))
+	deps, err := extractor.ExtractFromParsedFiles(files)
+	if err != nil {
+		t.Fatalf("依存関係抽出エラー: %v", err)
+	}
+
+	// 別パッケージの型への型変換はパッケージ間呼び出しではなく型の使用になる
+	lookup := types.NewNodeID("example.com/app/svc", "Lookup")
+	assertDependencies(t, deps, []DependencyInfo{
+		{From: types.NewNodeID("example.com/app/model", "Parse"), To: types.NewNodeID("example.com/app/model", "ID"), Type: types.TypeUsageDependency},
+		{From: lookup, To: types.NewNodeID("example.com/app/model", "Parse"), Type: types.CrossPackageDependency},
+		{From: lookup, To: types.NewNodeID("example.com/app/model", "ID"), Type: types.TypeUsageDependency},
+	})
+}
+
 func TestBodyCallDependencyExtractor_Name(t *testing.T) {
 	ctx := NewContext(token.NewFileSet(), "test")
 	extractor := NewBodyCallDependencyExtractor(ctx)
@@ -3,6 +3,7 @@ package extraction
 import (
 	"go/ast"
 	"go/token"
+	gotypes "go/types"
 	"strings"
 
 	"github.com/harakeishi/depsee/internal/logger"
@@ -19,30 +20,60 @@ func NewBodyCallDependencyExtractor(ctx *Context) *BodyCallDependencyExtractor {
 	return &BodyCallDependencyExtractor{ctx: ctx}
 }
 
+// withContext returns a copy of the extractor bound to ctx
+func (e *BodyCallDependencyExtractor) withContext(ctx *Context) ExtractionStrategy {
+	return NewBodyCallDependencyExtractor(ctx)
+}
+
 // ExtractDependencies extracts body call-based dependencies
-func (e *BodyCallDependencyExtractor) ExtractDependencies(file *ast.File, fset *token.FileSet, packageName string) ([]DependencyInfo, error) {
+func (e *BodyCallDependencyExtractor) ExtractDependencies(file *ast.File, fset *token.FileSet, packagePath string) ([]DependencyInfo, error) {
+	if e.ctx.IsTypeChecked() {
+		return e.extractTyped(file, packagePath), nil
+	}
+
 	var dependencies []DependencyInfo
+	decls := e.ctx.declarationsFor(file, packagePath)
 	
 	ast.Inspect(file, func(n ast.Node) bool {
 		switch node := n.(type) {
 		case *ast.FuncDecl:
 			if node.Body != nil {
-				funcName := node.Name.Name
-				fromID := types.NewNodeID(packageName, funcName)
+				fromID := funcNodeID(packagePath, node)
+				typeParams := funcTypeParams(node)
 				
-				// Extract function calls from body
-				calls := e.extractCalls(node.Body)
-				for _, call := range calls {
-					if targetFunc := e.resolveCall(call, packageName); targetFunc != "" {
-						toID := types.NewNodeID(packageName, targetFunc)
+				// Extract function calls from body, including those inside closures.
+				// Calls through local variables and parameters are not calls of package functions.
+				for _, call := range e.extractCallSites(node.Body, newStaticTypes(e.ctx, decls, node)) {
+					// Conversions to local types are type usages, not calls
+					if _, isType := decls.types[call.name]; isType && !call.typeArg {
+						continue
+					}
+					// Builtins such as len, append or new have no node
+					if isBuiltinFunc(call.name, decls) {
+						continue
+					}
+					if targetFunc := e.resolveCall(call.name, packagePath); targetFunc != "" && !typeParams[targetFunc] {
+						toID := types.NewNodeID(packagePath, targetFunc)
 						dependencies = append(dependencies, DependencyInfo{
-							From: fromID,
-							To:   toID,
-							Type: types.BodyCallDependency,
+							From:     fromID,
+							To:       toID,
+							Type:     types.BodyCallDependency,
+							Position: e.ctx.position(call.pos),
 						})
-						logger.Debug("関数呼び出し依存関係追加", "from", fromID, "to", toID, "call", call)
+						logger.Debug("関数呼び出し依存関係追加", "from", fromID, "to", toID, "call", call.name)
 					}
 				}
+
+				// Resolve method calls through the static type of their receiver
+				for _, call := range e.extractMethodCalls(node, decls, packagePath) {
+					dependencies = append(dependencies, DependencyInfo{
+						From:     fromID,
+						To:       call.toID,
+						Type:     call.depType,
+						Position: e.ctx.position(call.pos),
+					})
+					logger.Debug("メソッド呼び出し依存関係追加", "from", fromID, "to", call.toID)
+				}
 			}
 		}
 		return true
@@ -56,18 +87,121 @@ func (e *BodyCallDependencyExtractor) Name() string {
 	return "BodyCallDependency"
 }
 
+// extractTyped extracts body call dependencies whose callee resolves to a package-level function
+// or to a method of a named type. Builtins, conversions and calls through local variables are ignored.
+func (e *BodyCallDependencyExtractor) extractTyped(file *ast.File, packagePath string) []DependencyInfo {
+	var dependencies []DependencyInfo
+	pkgScope := e.ctx.packageScope(file)
+
+	for _, decl := range file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Body == nil {
+			continue
+		}
+		fromID := funcNodeID(packagePath, funcDecl)
+
+		ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			callee, _ := splitInstantiation(call.Fun)
+			if sel, ok := callee.(*ast.SelectorExpr); ok {
+				if toID, depType, ok := e.typedMethodCall(sel, pkgScope, packagePath); ok {
+					dependencies = append(dependencies, DependencyInfo{
+						From:     fromID,
+						To:       toID,
+						Type:     depType,
+						Position: e.ctx.position(call.Pos()),
+					})
+					logger.Debug("メソッド呼び出し依存関係追加", "from", fromID, "to", toID, "call", sel.Sel.Name)
+				}
+				return true
+			}
+			ident, ok := callee.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			fn, ok := e.ctx.TypesInfo.Uses[ident].(*gotypes.Func)
+			if !ok || fn.Parent() != pkgScope {
+				return true
+			}
+			toID := types.NewNodeID(packagePath, fn.Name())
+			dependencies = append(dependencies, DependencyInfo{
+				From:     fromID,
+				To:       toID,
+				Type:     types.BodyCallDependency,
+				Position: e.ctx.position(call.Pos()),
+			})
+			logger.Debug("関数呼び出し依存関係追加", "from", fromID, "to", toID, "call", ident.Name)
+
+			// A generic call also depends on its explicit or inferred type arguments
+			if instance, ok := e.ctx.TypesInfo.Instances[ident]; ok {
+				for i := 0; i < instance.TypeArgs.Len(); i++ {
+					for _, tn := range namedTypesOf(instance.TypeArgs.At(i)) {
+						if tn.Parent() != pkgScope {
+							continue
+						}
+						dependencies = append(dependencies, DependencyInfo{
+							From:     fromID,
+							To:       types.NewNodeID(packagePath, tn.Name()),
+							Type:     types.BodyCallDependency,
+							Position: e.ctx.position(call.Pos()),
+						})
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	return dependencies
+}
+
 // extractCalls extracts function calls from a function body
 func (e *BodyCallDependencyExtractor) extractCalls(body *ast.BlockStmt) []string {
 	var calls []string
+	for _, call := range e.extractCallSites(body, nil) {
+		calls = append(calls, call.name)
+	}
+	return calls
+}
+
+// callSite is a call target found in a function body together with the position of the call
+type callSite struct {
+	name    string
+	pos     token.Pos
+	typeArg bool // explicit type argument of a generic call rather than the callee
+}
+
+// extractCallSites extracts function calls from a function body with their positions.
+// When scope is not nil, calls through the local variables it tracks are skipped.
+func (e *BodyCallDependencyExtractor) extractCallSites(body *ast.BlockStmt, scope *staticTypes) []callSite {
+	var calls []callSite
 	
 	ast.Inspect(body, func(n ast.Node) bool {
+		if scope != nil {
+			scope.record(n)
+		}
 		switch node := n.(type) {
 		case *ast.CallExpr:
-			if ident, ok := node.Fun.(*ast.Ident); ok {
-				calls = append(calls, ident.Name)
-			} else if selector, ok := node.Fun.(*ast.SelectorExpr); ok {
+			callee, typeArgs := splitInstantiation(node.Fun)
+			// Explicit type arguments of a generic call are dependencies as well
+			for _, arg := range typeArgs {
+				for _, name := range localTypeNamesIn(arg) {
+					calls = append(calls, callSite{name: name, pos: arg.Pos(), typeArg: true})
+				}
+			}
+			if ident, ok := callee.(*ast.Ident); ok {
+				if scope != nil {
+					if _, local := scope.vars[ident.Name]; local {
+						return true
+					}
+				}
+				calls = append(calls, callSite{name: ident.Name, pos: node.Pos()})
+			} else if selector, ok := callee.(*ast.SelectorExpr); ok {
 				if ident, ok := selector.X.(*ast.Ident); ok {
-					calls = append(calls, ident.Name+"."+selector.Sel.Name)
+					calls = append(calls, callSite{name: ident.Name + "." + selector.Sel.Name, pos: node.Pos()})
 				}
 			}
 		}
@@ -87,3 +221,12 @@ func (e *BodyCallDependencyExtractor) resolveCall(call, currentPkg string) strin
 	// For qualified calls, return empty string for now (cross-package calls are handled separately)
 	return ""
 }
+
+// isBuiltinFunc reports whether a call name refers to a builtin function that no function of the package shadows
+func isBuiltinFunc(name string, decls *packageDecls) bool {
+	if _, declared := decls.funcs[name]; declared {
+		return false
+	}
+	_, ok := gotypes.Universe.Lookup(name).(*gotypes.Builtin)
+	return ok
+}
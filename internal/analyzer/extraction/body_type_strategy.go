@@ -0,0 +1,211 @@
+package extraction
+
+import (
+	"go/ast"
+	"go/token"
+	gotypes "go/types"
+
+	"github.com/harakeishi/depsee/internal/logger"
+	"github.com/harakeishi/depsee/internal/types"
+)
+
+// BodyTypeUsageDependencyExtractor extracts the types a function body uses without calling anything.
+// Composite literals (T{...}, &T{...}), new(T) and make([]T, n) construct values of a type and are
+// instantiation dependencies; type assertions x.(T), type switch cases and conversions T(x) are
+// type usage dependencies. Only explicitly written types count: the elided element types of
+// nested composite literals are covered by the outer literal.
+type BodyTypeUsageDependencyExtractor struct {
+	ctx *Context
+}
+
+// NewBodyTypeUsageDependencyExtractor creates a new body type usage dependency extractor
+func NewBodyTypeUsageDependencyExtractor(ctx *Context) *BodyTypeUsageDependencyExtractor {
+	return &BodyTypeUsageDependencyExtractor{ctx: ctx}
+}
+
+// withContext returns a copy of the extractor bound to ctx
+func (e *BodyTypeUsageDependencyExtractor) withContext(ctx *Context) ExtractionStrategy {
+	return NewBodyTypeUsageDependencyExtractor(ctx)
+}
+
+// ExtractDependencies extracts instantiation and type usage dependencies from function bodies
+func (e *BodyTypeUsageDependencyExtractor) ExtractDependencies(file *ast.File, fset *token.FileSet, packagePath string) ([]DependencyInfo, error) {
+	var dependencies []DependencyInfo
+	var pkgScope *gotypes.Scope
+	var decls *packageDecls
+	if e.ctx.IsTypeChecked() {
+		pkgScope = e.ctx.packageScope(file)
+	} else {
+		decls = e.ctx.declarationsFor(file, packagePath)
+	}
+
+	for _, decl := range file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Body == nil {
+			continue
+		}
+		fromID := funcNodeID(packagePath, funcDecl)
+
+		// Type parameters and types declared inside the body are not nodes of the graph
+		skip := funcTypeParams(funcDecl)
+		ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+			if typeSpec, ok := n.(*ast.TypeSpec); ok {
+				skip[typeSpec.Name.Name] = true
+			}
+			return true
+		})
+
+		resolve := func(expr ast.Expr) []types.NodeID {
+			if !e.ctx.IsTypeChecked() {
+				return (&FieldDependencyExtractor{ctx: e.ctx}).resolveTypeRefs(expr, packagePath, skip)
+			}
+			var targets []types.NodeID
+			for _, tn := range e.ctx.typeNamesIn(expr) {
+				if toID, ok := e.ctx.typeNodeID(tn, pkgScope, packagePath); ok {
+					targets = append(targets, toID)
+				}
+			}
+			return targets
+		}
+		emit := func(targets []types.NodeID, pos token.Pos, depType types.DependencyType) {
+			for _, toID := range targets {
+				dependencies = append(dependencies, DependencyInfo{
+					From:     fromID,
+					To:       toID,
+					Type:     depType,
+					Position: e.ctx.position(pos),
+				})
+				logger.Debug("型使用依存関係追加", "from", fromID, "to", toID, "type", depType)
+			}
+		}
+		add := func(expr ast.Expr, depType types.DependencyType) {
+			emit(resolve(expr), expr.Pos(), depType)
+		}
+
+		ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.CompositeLit:
+				if node.Type != nil {
+					add(node.Type, types.InstantiationDependency)
+				}
+			case *ast.CallExpr:
+				if typeArg, ok := e.allocatedType(node, decls); ok {
+					add(typeArg, types.InstantiationDependency)
+				} else if e.isConversion(node, decls, skip) {
+					add(node.Fun, types.TypeUsageDependency)
+				}
+			case *ast.ValueSpec:
+				if node.Type != nil {
+					e.declaredInstantiations(node.Type, resolve, emit)
+				}
+			case *ast.TypeAssertExpr:
+				if node.Type != nil {
+					add(node.Type, types.TypeUsageDependency)
+				}
+			case *ast.TypeSwitchStmt:
+				for _, stmt := range node.Body.List {
+					clause, ok := stmt.(*ast.CaseClause)
+					if !ok {
+						continue
+					}
+					for _, expr := range clause.List {
+						if ident, ok := expr.(*ast.Ident); ok && ident.Name == "nil" {
+							continue
+						}
+						add(expr, types.TypeUsageDependency)
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	return dependencies, nil
+}
+
+// Name returns the strategy name
+func (e *BodyTypeUsageDependencyExtractor) Name() string {
+	return "BodyTypeUsageDependency"
+}
+
+// declaredInstantiations reports the instantiations in the type of a variable declaration such as
+// var r Repo[*User]: the generic types are instantiated, the other types are type usages
+func (e *BodyTypeUsageDependencyExtractor) declaredInstantiations(typ ast.Expr, resolve func(ast.Expr) []types.NodeID, emit func([]types.NodeID, token.Pos, types.DependencyType)) {
+	insts := instantiations(typ)
+	if len(insts) == 0 {
+		return
+	}
+	generics := make(map[types.NodeID]bool)
+	for _, inst := range insts {
+		generic, _ := splitInstantiation(inst)
+		targets := resolve(generic)
+		for _, toID := range targets {
+			generics[toID] = true
+		}
+		emit(targets, inst.Pos(), types.InstantiationDependency)
+	}
+	var used []types.NodeID
+	for _, toID := range resolve(typ) {
+		if !generics[toID] {
+			used = append(used, toID)
+		}
+	}
+	emit(used, typ.Pos(), types.TypeUsageDependency)
+}
+
+// allocatedType returns the type argument of a call to the builtin new or make
+func (e *BodyTypeUsageDependencyExtractor) allocatedType(call *ast.CallExpr, decls *packageDecls) (ast.Expr, bool) {
+	ident, ok := ast.Unparen(call.Fun).(*ast.Ident)
+	if !ok || (ident.Name != "new" && ident.Name != "make") || len(call.Args) == 0 {
+		return nil, false
+	}
+	if e.ctx.IsTypeChecked() {
+		if _, ok := e.ctx.TypesInfo.Uses[ident].(*gotypes.Builtin); !ok {
+			return nil, false
+		}
+	} else if _, declared := decls.funcs[ident.Name]; declared {
+		return nil, false
+	}
+	return call.Args[0], true
+}
+
+// isConversion reports whether a call converts its argument to a type, such as UserID(x),
+// (*User)(p), []byte(s) or store.Key(s)
+func (e *BodyTypeUsageDependencyExtractor) isConversion(call *ast.CallExpr, decls *packageDecls, skip map[string]bool) bool {
+	if e.ctx.IsTypeChecked() {
+		tv, ok := e.ctx.TypesInfo.Types[call.Fun]
+		return ok && tv.IsType()
+	}
+	var isType func(expr ast.Expr) bool
+	isType = func(expr ast.Expr) bool {
+		switch t := ast.Unparen(expr).(type) {
+		case *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType, *ast.StructType:
+			return true
+		case *ast.StarExpr:
+			return isType(t.X)
+		case *ast.IndexExpr, *ast.IndexListExpr:
+			generic, _ := splitInstantiation(t)
+			return isType(generic)
+		case *ast.Ident:
+			_, declared := decls.types[t.Name]
+			return declared && !skip[t.Name]
+		case *ast.SelectorExpr:
+			qualifier, ok := t.X.(*ast.Ident)
+			if !ok {
+				return false
+			}
+			importPath, ok := e.ctx.ImportMap[qualifier.Name]
+			if !ok {
+				return false
+			}
+			target, ok := e.ctx.declarations[crossPackageTarget(e.ctx, importPath, qualifier.Name)]
+			if !ok {
+				return false
+			}
+			_, declared := target.types[t.Sel.Name]
+			return declared
+		}
+		return false
+	}
+	return isType(call.Fun)
+}
@@ -0,0 +1,162 @@
+package extraction
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/harakeishi/depsee/internal/types"
+)
+
+const bodyTypeTestCode = `package test
+
+type User struct {
+	Name string
+}
+
+type UserID string
+
+type Event interface{}
+
+type Created struct{}
+
+type Deleted struct{}
+
+type List[T any] []T
+
+func NewUser(name string) *User {
+	return &User{Name: name}
+}
+
+func Users() []User {
+	return []User{{Name: "a"}, {Name: "b"}}
+}
+
+func Allocate() {
+	u := new(User)
+	ids := make([]UserID, 0)
+	_, _ = u, ids
+}
+
+func Convert(s string) UserID {
+	return UserID(s)
+}
+
+func Wrap(items []int) List[int] {
+	return List[int](items)
+}
+
+func Handle(e Event) {
+	if c, ok := e.(*Created); ok {
+		_ = c
+	}
+	switch e.(type) {
+	case Deleted, nil:
+	}
+}
+
+func Local() {
+	type row struct{}
+	_ = row{}
+	_ = struct{}{}
+}
+
+func Generic[T any]() T {
+	return *new(T)
+}
+`
+
+func TestBodyTypeUsageDependencyExtractor(t *testing.T) {
+	id := func(name string) types.NodeID { return types.NewNodeID("test", name) }
+	expected := []DependencyInfo{
+		{From: id("NewUser"), To: id("User"), Type: types.InstantiationDependency},
+		{From: id("Users"), To: id("User"), Type: types.InstantiationDependency},
+		{From: id("Allocate"), To: id("User"), Type: types.InstantiationDependency},
+		{From: id("Allocate"), To: id("UserID"), Type: types.InstantiationDependency},
+		{From: id("Convert"), To: id("UserID"), Type: types.TypeUsageDependency},
+		{From: id("Wrap"), To: id("List"), Type: types.TypeUsageDependency},
+		{From: id("Handle"), To: id("Created"), Type: types.TypeUsageDependency},
+		{From: id("Handle"), To: id("Deleted"), Type: types.TypeUsageDependency},
+	}
+
+	t.Run("名前ベース", func(t *testing.T) {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "test.go", bodyTypeTestCode, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("コード解析エラー: %v", err)
+		}
+		deps, err := NewBodyTypeUsageDependencyExtractor(NewContext(fset, "test")).ExtractDependencies(file, fset, "test")
+		if err != nil {
+			t.Fatalf("依存関係抽出エラー: %v", err)
+		}
+		assertDependencies(t, deps, expected)
+	})
+
+	t.Run("型チェック", func(t *testing.T) {
+		file, fset, info := parseAndCheck(t, bodyTypeTestCode)
+		ctx := NewContext(fset, "test")
+		ctx.TypesInfo = info
+		deps, err := NewBodyTypeUsageDependencyExtractor(ctx).ExtractDependencies(file, fset, "test")
+		if err != nil {
+			t.Fatalf("依存関係抽出エラー: %v", err)
+		}
+		assertDependencies(t, deps, expected)
+	})
+}
+
+func TestBodyTypeUsageDependencyExtractor_AcrossPackages(t *testing.T) {
+	sources := []struct {
+		path        string
+		packagePath string
+		code        string
+	}{
+		{
+			path:        "model/model.go",
+			packagePath: "example.com/app/model",
+			code: `package model
+type User struct{}
+type ID string
+`,
+		},
+		{
+			path:        "service/service.go",
+			packagePath: "example.com/app/service",
+			code: `package service
+import (
+	"strings"
+	"example.com/app/model"
+)
+func Run(v any) {
+	_ = model.User{}
+	_ = model.ID("1")
+	_, _ = v.(*model.User)
+	_ = strings.Builder{}
+}
+`,
+		},
+	}
+
+	fset := token.NewFileSet()
+	var files []ParsedFile
+	for _, src := range sources {
+		file, err := parser.ParseFile(fset, src.path, src.code, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("コード解析エラー: %v", err)
+		}
+		files = append(files, ParsedFile{Path: src.path, PackagePath: src.packagePath, File: file, FileSet: fset})
+	}
+
+	extractor := NewStrategyBasedExtractor("")
+	extractor.AddStrategy(NewBodyTypeUsageDependencyExtractor(extractor.Context()))
+	deps, err := extractor.ExtractFromParsedFiles(files)
+	if err != nil {
+		t.Fatalf("依存関係抽出エラー: %v", err)
+	}
+	run := types.NewNodeID("example.com/app/service", "Run")
+	user := types.NewNodeID("example.com/app/model", "User")
+	assertDependencies(t, deps, []DependencyInfo{
+		{From: run, To: user, Type: types.InstantiationDependency},
+		{From: run, To: types.NewNodeID("example.com/app/model", "ID"), Type: types.TypeUsageDependency},
+		{From: run, To: user, Type: types.TypeUsageDependency},
+	})
+}
@@ -18,11 +18,11 @@ func NewCompositeExtractor(strategies ...ExtractionStrategy) *CompositeExtractor
 }
 
 // ExtractDependencies runs all strategies and combines results
-func (e *CompositeExtractor) ExtractDependencies(file *ast.File, fset *token.FileSet, packageName string) ([]DependencyInfo, error) {
+func (e *CompositeExtractor) ExtractDependencies(file *ast.File, fset *token.FileSet, packagePath string) ([]DependencyInfo, error) {
 	var allDependencies []DependencyInfo
 	
 	for _, strategy := range e.strategies {
-		deps, err := strategy.ExtractDependencies(file, fset, packageName)
+		deps, err := strategy.ExtractDependencies(file, fset, packagePath)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,117 @@
+package extraction
+
+import (
+	"go/ast"
+	"go/token"
+
+	"github.com/harakeishi/depsee/internal/types"
+)
+
+// ConstraintDependencyExtractor extracts dependencies from type parameter constraints.
+// Generic types and functions depend on the types used in their constraints, and
+// constraint interfaces depend on the types listed in their type sets (e.g. ~int | MyInt).
+type ConstraintDependencyExtractor struct {
+	ctx *Context
+}
+
+// NewConstraintDependencyExtractor creates a new constraint dependency extractor
+func NewConstraintDependencyExtractor(ctx *Context) *ConstraintDependencyExtractor {
+	return &ConstraintDependencyExtractor{ctx: ctx}
+}
+
+// withContext returns a copy of the extractor bound to ctx
+func (e *ConstraintDependencyExtractor) withContext(ctx *Context) ExtractionStrategy {
+	return NewConstraintDependencyExtractor(ctx)
+}
+
+// ExtractDependencies extracts constraint-based dependencies
+func (e *ConstraintDependencyExtractor) ExtractDependencies(file *ast.File, fset *token.FileSet, packagePath string) ([]DependencyInfo, error) {
+	var dependencies []DependencyInfo
+
+	add := func(fromID types.NodeID, exprs []ast.Expr, typeParams map[string]bool) {
+		for _, expr := range exprs {
+			for _, toID := range e.targets(file, expr, packagePath, typeParams) {
+				dependencies = append(dependencies, DependencyInfo{
+					From:     fromID,
+					To:       toID,
+					Type:     types.ConstraintDependency,
+					Position: e.ctx.position(expr.Pos()),
+				})
+			}
+		}
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			add(funcNodeID(packagePath, d), constraintExprs(d.Type.TypeParams), funcTypeParams(d))
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				typeSpec, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				fromID := types.NewNodeID(packagePath, typeSpec.Name.Name)
+				typeParams := typeParamNames(typeSpec.TypeParams)
+				add(fromID, constraintExprs(typeSpec.TypeParams), typeParams)
+				if iface, ok := typeSpec.Type.(*ast.InterfaceType); ok {
+					add(fromID, typeSetElements(iface), typeParams)
+				}
+			}
+		}
+	}
+
+	return dependencies, nil
+}
+
+// Name returns the strategy name
+func (e *ConstraintDependencyExtractor) Name() string {
+	return "ConstraintDependency"
+}
+
+// targets returns the nodes of the types referenced by a constraint expression.
+// Qualified constraints such as model.Entity resolve to types in other local packages.
+func (e *ConstraintDependencyExtractor) targets(file *ast.File, expr ast.Expr, packagePath string, typeParams map[string]bool) []types.NodeID {
+	if !e.ctx.IsTypeChecked() {
+		return (&FieldDependencyExtractor{ctx: e.ctx}).resolveTypeRefs(expr, packagePath, typeParams)
+	}
+	pkgScope := e.ctx.packageScope(file)
+	var targets []types.NodeID
+	for _, tn := range e.ctx.typeNamesIn(expr) {
+		if toID, ok := e.ctx.typeNodeID(tn, pkgScope, packagePath); ok {
+			targets = append(targets, toID)
+		}
+	}
+	return targets
+}
+
+// constraintExprs returns the constraint expressions of a type parameter list
+func constraintExprs(list *ast.FieldList) []ast.Expr {
+	var exprs []ast.Expr
+	if list == nil {
+		return exprs
+	}
+	for _, field := range list.List {
+		exprs = append(exprs, field.Type)
+	}
+	return exprs
+}
+
+// typeSetElements returns the union and approximation elements of a constraint interface.
+// Methods and plainly embedded interfaces are not part of the result.
+func typeSetElements(iface *ast.InterfaceType) []ast.Expr {
+	var elements []ast.Expr
+	if iface.Methods == nil {
+		return elements
+	}
+	for _, field := range iface.Methods.List {
+		switch field.Type.(type) {
+		case *ast.BinaryExpr, *ast.UnaryExpr:
+			elements = append(elements, field.Type)
+		}
+	}
+	return elements
+}
@@ -3,11 +3,11 @@ package extraction
 import (
 	"go/ast"
 	"go/token"
+	gotypes "go/types"
 	"strings"
 
 	"github.com/harakeishi/depsee/internal/logger"
 	"github.com/harakeishi/depsee/internal/types"
-	"github.com/harakeishi/depsee/internal/utils"
 )
 
 // CrossPackageDependencyExtractor extracts cross-package dependencies
@@ -20,8 +20,13 @@ func NewCrossPackageDependencyExtractor(ctx *Context) *CrossPackageDependencyExt
 	return &CrossPackageDependencyExtractor{ctx: ctx}
 }
 
+// withContext returns a copy of the extractor bound to ctx
+func (e *CrossPackageDependencyExtractor) withContext(ctx *Context) ExtractionStrategy {
+	return NewCrossPackageDependencyExtractor(ctx)
+}
+
 // ExtractDependencies extracts cross-package dependencies
-func (e *CrossPackageDependencyExtractor) ExtractDependencies(file *ast.File, fset *token.FileSet, packageName string) ([]DependencyInfo, error) {
+func (e *CrossPackageDependencyExtractor) ExtractDependencies(file *ast.File, fset *token.FileSet, packagePath string) ([]DependencyInfo, error) {
 	var dependencies []DependencyInfo
 	
 	// First, extract import mappings
@@ -32,16 +37,16 @@ func (e *CrossPackageDependencyExtractor) ExtractDependencies(file *ast.File, fs
 		switch node := n.(type) {
 		case *ast.FuncDecl:
 			if node.Body != nil {
-				funcName := node.Name.Name
-				fromID := types.NewNodeID(packageName, funcName)
+				fromID := funcNodeID(packagePath, node)
 				
 				// Extract cross-package calls from function body
 				crossCalls := e.extractCrossPackageCalls(node.Body, imports)
 				for _, call := range crossCalls {
 					dependencies = append(dependencies, DependencyInfo{
-						From: fromID,
-						To:   call.ToID,
-						Type: types.CrossPackageDependency,
+						From:     fromID,
+						To:       call.ToID,
+						Type:     types.CrossPackageDependency,
+						Position: e.ctx.position(call.Pos),
 					})
 					logger.Debug("パッケージ間呼び出し依存関係追加", "from", fromID, "to", call.ToID, "call", call.CallName)
 				}
@@ -62,6 +67,7 @@ func (e *CrossPackageDependencyExtractor) Name() string {
 type CrossPackageCall struct {
 	CallName string
 	ToID     types.NodeID
+	Pos      token.Pos // position of the call expression
 }
 
 // extractImports extracts import mappings from the file
@@ -93,20 +99,29 @@ func (e *CrossPackageDependencyExtractor) extractCrossPackageCalls(body *ast.Blo
 	ast.Inspect(body, func(n ast.Node) bool {
 		switch node := n.(type) {
 		case *ast.CallExpr:
-			if selector, ok := node.Fun.(*ast.SelectorExpr); ok {
+			callee, _ := splitInstantiation(node.Fun)
+			if selector, ok := callee.(*ast.SelectorExpr); ok {
 				if ident, ok := selector.X.(*ast.Ident); ok {
 					packageAlias := ident.Name
 					funcName := selector.Sel.Name
+
+					// 型チェックモードでは修飾子が本当にimportされたパッケージを指しているか検証する
+					if e.ctx.IsTypeChecked() && !e.isTypedPackageCall(ident, selector.Sel) {
+						return true
+					}
 					
 					// Check if this is a cross-package call
 					if importPath, exists := imports[packageAlias]; exists {
-						// Extract target package name
-						targetPkg := e.extractPackageAlias(importPath, packageAlias)
-						if targetPkg != "" && utils.IsLocalPackage(importPath) {
+						if targetPkg := crossPackageTarget(e.ctx, importPath, packageAlias); targetPkg != "" {
+							// A conversion such as model.ID(name) is a type usage, not a call
+							if e.isImportedType(targetPkg, funcName) {
+								return true
+							}
 							toID := types.NewNodeID(targetPkg, funcName)
 							calls = append(calls, CrossPackageCall{
 								CallName: packageAlias + "." + funcName,
 								ToID:     toID,
+								Pos:      node.Pos(),
 							})
 						}
 					}
@@ -119,6 +134,51 @@ func (e *CrossPackageDependencyExtractor) extractCrossPackageCalls(body *ast.Blo
 	return calls
 }
 
+// isImportedType reports whether name is a type declared in the imported package targetPkg.
+// Type-checked mode rejects such selectors in isTypedPackageCall instead.
+func (e *CrossPackageDependencyExtractor) isImportedType(targetPkg, name string) bool {
+	if e.ctx.IsTypeChecked() {
+		return false
+	}
+	decls, ok := e.ctx.declarations[targetPkg]
+	if !ok {
+		return false
+	}
+	_, declared := decls.types[name]
+	return declared
+}
+
+// isTypedPackageCall reports whether qualifier.sel is a call to a function of an imported package.
+// A local variable shadowing a package name is rejected; an unresolved selector is accepted
+// because the imported package may have failed to type-check.
+func (e *CrossPackageDependencyExtractor) isTypedPackageCall(qualifier, sel *ast.Ident) bool {
+	if _, ok := e.ctx.TypesInfo.Uses[qualifier].(*gotypes.PkgName); !ok {
+		return false
+	}
+	obj := e.ctx.TypesInfo.Uses[sel]
+	if obj == nil {
+		return true
+	}
+	_, ok := obj.(*gotypes.Func)
+	return ok
+}
+
+// crossPackageTarget returns the package identifier used in node IDs for a reference made through an import.
+// It returns "" for references that must not produce dependencies: blank and dot imports and
+// packages outside the repository (standard library and third-party modules, see utils.Module.Classify).
+// Field, signature and call dependencies on other packages all share these filtering rules.
+func crossPackageTarget(ctx *Context, importPath, alias string) string {
+	targetPkg := (&CrossPackageDependencyExtractor{ctx: ctx}).extractPackageAlias(importPath, alias)
+	if targetPkg == "" || !ctx.ImportKind(importPath).IsLocal() {
+		return ""
+	}
+	if ctx != nil && ctx.PackagePath != "" {
+		// importパスが判明している場合はノードIDと同じくimportパスで識別する
+		return importPath
+	}
+	return targetPkg
+}
+
 // extractPackageAlias extracts package alias from import path
 func (e *CrossPackageDependencyExtractor) extractPackageAlias(importPath, alias string) string {
 	// Handle special cases
@@ -0,0 +1,120 @@
+package extraction
+
+import (
+	"go/ast"
+	"go/token"
+	gotypes "go/types"
+
+	"github.com/harakeishi/depsee/internal/logger"
+	"github.com/harakeishi/depsee/internal/types"
+	"github.com/harakeishi/depsee/internal/utils"
+)
+
+// ExternalDependencyExtractor extracts references from declarations to packages outside the repository.
+// Every pkg.Name reference to a third-party package made by a function, method or type declaration
+// becomes a dependency on the collapsed node of the package's module; references to the standard
+// library become dependencies on one node per standard package when includeStdlib is set.
+type ExternalDependencyExtractor struct {
+	ctx           *Context
+	includeStdlib bool
+}
+
+// NewExternalDependencyExtractor creates a new external dependency extractor
+func NewExternalDependencyExtractor(ctx *Context, includeStdlib bool) *ExternalDependencyExtractor {
+	return &ExternalDependencyExtractor{ctx: ctx, includeStdlib: includeStdlib}
+}
+
+// withContext returns a copy of the extractor bound to ctx
+func (e *ExternalDependencyExtractor) withContext(ctx *Context) ExtractionStrategy {
+	return NewExternalDependencyExtractor(ctx, e.includeStdlib)
+}
+
+// ExtractDependencies extracts external dependencies
+func (e *ExternalDependencyExtractor) ExtractDependencies(file *ast.File, fset *token.FileSet, packagePath string) ([]DependencyInfo, error) {
+	var dependencies []DependencyInfo
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			dependencies = append(dependencies, e.references(funcNodeID(packagePath, d), d)...)
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+					fromID := types.NewNodeID(packagePath, typeSpec.Name.Name)
+					dependencies = append(dependencies, e.references(fromID, typeSpec)...)
+				}
+			}
+		}
+	}
+
+	return dependencies, nil
+}
+
+// Name returns the strategy name
+func (e *ExternalDependencyExtractor) Name() string {
+	return "ExternalDependency"
+}
+
+// references returns a dependency for every qualified reference to an external package inside node
+func (e *ExternalDependencyExtractor) references(fromID types.NodeID, node ast.Node) []DependencyInfo {
+	var dependencies []DependencyInfo
+	ast.Inspect(node, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		qualifier, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		importPath, ok := e.importPathOf(qualifier)
+		if !ok {
+			return true
+		}
+		if toID, ok := e.externalNode(importPath); ok {
+			dependencies = append(dependencies, DependencyInfo{
+				From:     fromID,
+				To:       toID,
+				Type:     types.ExternalDependency,
+				Position: e.ctx.position(sel.Pos()),
+			})
+			logger.Debug("外部依存関係追加", "from", fromID, "to", toID, "ref", qualifier.Name+"."+sel.Sel.Name)
+		}
+		return false
+	})
+	return dependencies
+}
+
+// importPathOf returns the import path of the package a qualifier refers to.
+// In type-checked mode a local variable shadowing a package name is rejected.
+func (e *ExternalDependencyExtractor) importPathOf(qualifier *ast.Ident) (string, bool) {
+	if e.ctx.IsTypeChecked() {
+		pkgName, ok := e.ctx.TypesInfo.Uses[qualifier].(*gotypes.PkgName)
+		if !ok {
+			return "", false
+		}
+		return pkgName.Imported().Path(), true
+	}
+	if qualifier.Name == "_" || qualifier.Name == "." {
+		return "", false
+	}
+	importPath, ok := e.ctx.ImportMap[qualifier.Name]
+	return importPath, ok
+}
+
+// externalNode returns the node of an external import: the module node of a third-party package
+// or, when enabled, the node of a standard package. Local packages have no external node.
+func (e *ExternalDependencyExtractor) externalNode(importPath string) (types.NodeID, bool) {
+	switch e.ctx.ImportKind(importPath) {
+	case utils.ThirdPartyImport:
+		return types.NewExternalNodeID(e.ctx.Module.ModulePathOf(importPath)), true
+	case utils.StandardImport:
+		if e.includeStdlib {
+			return types.NewStdlibNodeID(importPath), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,104 @@
+package extraction
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/harakeishi/depsee/internal/types"
+	"github.com/harakeishi/depsee/internal/utils"
+)
+
+const externalTestCode = `package cmd
+
+import (
+	"strings"
+
+	"example.com/app/model"
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+	_ "github.com/lib/pq"
+)
+
+type Command struct {
+	Root *cobra.Command
+	User model.User
+}
+
+func (c *Command) Run(args []string) error {
+	return cobra.ExactArgs(1)(c.Root, args)
+}
+
+func GenDocs(c *Command) string {
+	_ = doc.GenMarkdownTree
+	return strings.ToUpper(c.Root.Use)
+}
+`
+
+func TestExternalDependencyExtractor(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "cmd.go", externalTestCode, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("コード解析エラー: %v", err)
+	}
+
+	newContext := func() *Context {
+		ctx := NewContext(fset, "cmd")
+		ctx.PackagePath = "example.com/app/cmd"
+		ctx.Module = &utils.Module{Path: "example.com/app", Requires: []string{"github.com/spf13/cobra", "github.com/lib/pq"}}
+		ctx.ImportMap = NewStrategyBasedExtractor("").extractImportMap(file)
+		return ctx
+	}
+	id := func(name string) types.NodeID { return types.NewNodeID("example.com/app/cmd", name) }
+	cobra := types.NewExternalNodeID("github.com/spf13/cobra")
+	// cobra/docはcobraモジュールのノードにまとめられ、ローカルのmodelと副作用importのpqは対象外
+	expected := []DependencyInfo{
+		{From: id("Command"), To: cobra, Type: types.ExternalDependency},
+		{From: types.NewMethodNodeID("example.com/app/cmd", "Command", "Run"), To: cobra, Type: types.ExternalDependency},
+		{From: id("GenDocs"), To: cobra, Type: types.ExternalDependency},
+	}
+
+	t.Run("サードパーティのみ", func(t *testing.T) {
+		deps, err := NewExternalDependencyExtractor(newContext(), false).ExtractDependencies(file, fset, "example.com/app/cmd")
+		if err != nil {
+			t.Fatalf("依存関係抽出エラー: %v", err)
+		}
+		assertDependencies(t, deps, expected)
+	})
+
+	t.Run("標準ライブラリを含む", func(t *testing.T) {
+		deps, err := NewExternalDependencyExtractor(newContext(), true).ExtractDependencies(file, fset, "example.com/app/cmd")
+		if err != nil {
+			t.Fatalf("依存関係抽出エラー: %v", err)
+		}
+		withStdlib := append(expected, DependencyInfo{From: id("GenDocs"), To: types.NewStdlibNodeID("strings"), Type: types.ExternalDependency})
+		assertDependencies(t, deps, withStdlib)
+	})
+}
+
+func TestExternalDependencyExtractor_TypeChecked(t *testing.T) {
+	code := `package test
+
+import "strings"
+
+func Upper(s string) string {
+	return strings.ToUpper(s)
+}
+
+func Shadowed() int {
+	strings := struct{ N int }{N: 1}
+	return strings.N
+}
+`
+	file, fset, info := parseAndCheck(t, code)
+	ctx := NewContext(fset, "test")
+	ctx.TypesInfo = info
+	ctx.Module = &utils.Module{Path: "example.com/app"}
+	deps, err := NewExternalDependencyExtractor(ctx, true).ExtractDependencies(file, fset, "test")
+	if err != nil {
+		t.Fatalf("依存関係抽出エラー: %v", err)
+	}
+	assertDependencies(t, deps, []DependencyInfo{
+		{From: types.NewNodeID("test", "Upper"), To: types.NewStdlibNodeID("strings"), Type: types.ExternalDependency},
+	})
+}
@@ -8,7 +8,8 @@ import (
 	"github.com/harakeishi/depsee/internal/types"
 )
 
-// FieldDependencyExtractor extracts dependencies from struct fields
+// FieldDependencyExtractor extracts dependencies from struct fields.
+// Embedded struct fields and embedded interfaces become embedding dependencies.
 type FieldDependencyExtractor struct {
 	ctx *Context
 }
@@ -18,25 +19,42 @@ func NewFieldDependencyExtractor(ctx *Context) *FieldDependencyExtractor {
 	return &FieldDependencyExtractor{ctx: ctx}
 }
 
+// withContext returns a copy of the extractor bound to ctx
+func (e *FieldDependencyExtractor) withContext(ctx *Context) ExtractionStrategy {
+	return NewFieldDependencyExtractor(ctx)
+}
+
 // ExtractDependencies extracts field-based dependencies
-func (e *FieldDependencyExtractor) ExtractDependencies(file *ast.File, fset *token.FileSet, packageName string) ([]DependencyInfo, error) {
+func (e *FieldDependencyExtractor) ExtractDependencies(file *ast.File, fset *token.FileSet, packagePath string) ([]DependencyInfo, error) {
+	if e.ctx.IsTypeChecked() {
+		return e.extractTyped(file, packagePath), nil
+	}
+
 	var dependencies []DependencyInfo
 	
 	ast.Inspect(file, func(n ast.Node) bool {
 		switch node := n.(type) {
 		case *ast.TypeSpec:
-			if structType, ok := node.Type.(*ast.StructType); ok {
-				structName := node.Name.Name
-				fromID := types.NewNodeID(packageName, structName)
-				
-				for _, field := range structType.Fields.List {
-					fieldType := extractTypeString(field.Type)
-					if targetType := e.resolveType(fieldType, packageName); targetType != "" {
-						toID := types.NewNodeID(packageName, targetType)
+			fromID := types.NewNodeID(packagePath, node.Name.Name)
+			typeParams := typeParamNames(node.TypeParams)
+			for _, field := range typeFields(node) {
+				for _, toID := range e.resolveTypeRefs(field.Type, packagePath, typeParams) {
+					dependencies = append(dependencies, DependencyInfo{
+						From:     fromID,
+						To:       toID,
+						Type:     fieldDependencyType(field),
+						Position: e.ctx.position(field.Type.Pos()),
+					})
+				}
+				// A field of an instantiated type such as Repo[User] also instantiates the generic type
+				for _, inst := range instantiations(field.Type) {
+					generic, _ := splitInstantiation(inst)
+					for _, toID := range e.resolveTypeRefs(generic, packagePath, typeParams) {
 						dependencies = append(dependencies, DependencyInfo{
-							From: fromID,
-							To:   toID,
-							Type: types.FieldDependency,
+							From:     fromID,
+							To:       toID,
+							Type:     types.InstantiationDependency,
+							Position: e.ctx.position(inst.Pos()),
 						})
 					}
 				}
@@ -53,30 +71,144 @@ func (e *FieldDependencyExtractor) Name() string {
 	return "FieldDependency"
 }
 
-// resolveType resolves a type string to a node name
+// extractTyped extracts field dependencies by resolving every referenced type through go/types
+func (e *FieldDependencyExtractor) extractTyped(file *ast.File, packagePath string) []DependencyInfo {
+	var dependencies []DependencyInfo
+	pkgScope := e.ctx.packageScope(file)
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		typeSpec, ok := n.(*ast.TypeSpec)
+		if !ok || !isPackageLevel(e.ctx.TypesInfo.Defs[typeSpec.Name]) {
+			return true
+		}
+		fromID := types.NewNodeID(packagePath, typeSpec.Name.Name)
+		for _, field := range typeFields(typeSpec) {
+			for _, tn := range e.ctx.typeNamesIn(field.Type) {
+				toID, ok := e.ctx.typeNodeID(tn, pkgScope, packagePath)
+				if !ok {
+					continue
+				}
+				dependencies = append(dependencies, DependencyInfo{
+					From:     fromID,
+					To:       toID,
+					Type:     fieldDependencyType(field),
+					Position: e.ctx.position(field.Type.Pos()),
+				})
+			}
+			for _, inst := range instantiations(field.Type) {
+				generic, _ := splitInstantiation(inst)
+				for _, tn := range e.ctx.typeNamesIn(generic) {
+					toID, ok := e.ctx.typeNodeID(tn, pkgScope, packagePath)
+					if !ok {
+						continue
+					}
+					dependencies = append(dependencies, DependencyInfo{
+						From:     fromID,
+						To:       toID,
+						Type:     types.InstantiationDependency,
+						Position: e.ctx.position(inst.Pos()),
+					})
+				}
+			}
+		}
+		return true
+	})
+
+	return dependencies
+}
+
+// typeFields returns the fields of a struct definition and the embedded interfaces of an interface definition.
+// Methods and type set elements such as ~int | string are not part of the result.
+func typeFields(typeSpec *ast.TypeSpec) []*ast.Field {
+	switch t := typeSpec.Type.(type) {
+	case *ast.StructType:
+		return t.Fields.List
+	case *ast.InterfaceType:
+		if t.Methods == nil {
+			return nil
+		}
+		var embeds []*ast.Field
+		for _, field := range t.Methods.List {
+			if len(field.Names) == 0 && isEmbeddedType(field.Type) {
+				embeds = append(embeds, field)
+			}
+		}
+		return embeds
+	}
+	return nil
+}
+
+// isEmbeddedType reports whether an interface element names a single type, e.g. Reader, io.Reader or Set[T]
+func isEmbeddedType(expr ast.Expr) bool {
+	switch t := expr.(type) {
+	case *ast.Ident, *ast.SelectorExpr:
+		return true
+	case *ast.ParenExpr:
+		return isEmbeddedType(t.X)
+	case *ast.IndexExpr:
+		return isEmbeddedType(t.X)
+	case *ast.IndexListExpr:
+		return isEmbeddedType(t.X)
+	}
+	return false
+}
+
+// fieldDependencyType returns the kind of dependency created by a field: embedding for anonymous fields
+func fieldDependencyType(field *ast.Field) types.DependencyType {
+	if len(field.Names) == 0 {
+		return types.EmbeddingDependency
+	}
+	return types.FieldDependency
+}
+
+// resolveTypeRefs resolves every node referenced anywhere inside a type expression.
+// Generic instantiations yield both the generic type and its type arguments; type parameters are skipped.
+// Package-qualified references resolve to types in other local packages.
+func (e *FieldDependencyExtractor) resolveTypeRefs(expr ast.Expr, currentPkg string, typeParams map[string]bool) []types.NodeID {
+	var targets []types.NodeID
+	for _, ref := range typeRefs(expr) {
+		if alias, name, ok := strings.Cut(ref, "."); ok {
+			if toID, ok := e.resolveQualifiedType(alias, name); ok {
+				targets = append(targets, toID)
+			}
+			continue
+		}
+		target := e.resolveType(ref, currentPkg)
+		if target == "" || typeParams[target] {
+			continue
+		}
+		targets = append(targets, types.NewNodeID(currentPkg, target))
+	}
+	return targets
+}
+
+// resolveQualifiedType resolves a reference such as pkg.Profile to the node of a type in another local package
+func (e *FieldDependencyExtractor) resolveQualifiedType(alias, name string) (types.NodeID, bool) {
+	importPath, ok := e.ctx.ImportMap[alias]
+	if !ok {
+		return "", false
+	}
+	targetPkg := crossPackageTarget(e.ctx, importPath, alias)
+	if targetPkg == "" {
+		return "", false
+	}
+	return types.NewNodeID(targetPkg, name), true
+}
+
+// resolveType resolves a type reference ("Name" or "pkg.Name") to a node name
 func (e *FieldDependencyExtractor) resolveType(typeStr string, currentPkg string) string {
-	// Remove pointer and slice indicators
-	typeStr = strings.TrimPrefix(typeStr, "*")
-	typeStr = strings.TrimPrefix(typeStr, "[]")
-	typeStr = strings.TrimPrefix(typeStr, "[]*")
+	// Remove pointer and slice indicators, however deeply nested
+	for strings.HasPrefix(typeStr, "*") || strings.HasPrefix(typeStr, "[]") {
+		typeStr = strings.TrimPrefix(strings.TrimPrefix(typeStr, "*"), "[]")
+	}
 	
 	// Skip basic types
 	if isBasicType(typeStr) {
 		return ""
 	}
 	
-	// Handle package-qualified types
+	// Package-qualified types are resolved by resolveQualifiedType
 	if strings.Contains(typeStr, ".") {
-		parts := strings.Split(typeStr, ".")
-		if len(parts) == 2 {
-			// Check if it's from an imported package
-			if pkgPath, ok := e.ctx.ImportMap[parts[0]]; ok {
-				// For now, we only handle same-package dependencies
-				if pkgPath == currentPkg {
-					return parts[1]
-				}
-			}
-		}
 		return ""
 	}
 	
@@ -84,23 +216,6 @@ func (e *FieldDependencyExtractor) resolveType(typeStr string, currentPkg string
 	return typeStr
 }
 
-// extractTypeString extracts type as string from ast.Expr
-func extractTypeString(expr ast.Expr) string {
-	switch t := expr.(type) {
-	case *ast.Ident:
-		return t.Name
-	case *ast.StarExpr:
-		return "*" + extractTypeString(t.X)
-	case *ast.ArrayType:
-		return "[]" + extractTypeString(t.Elt)
-	case *ast.SelectorExpr:
-		if x, ok := t.X.(*ast.Ident); ok {
-			return x.Name + "." + t.Sel.Name
-		}
-	}
-	return ""
-}
-
 // isBasicType checks if a type is a basic Go type
 func isBasicType(t string) bool {
 	basicTypes := map[string]bool{
@@ -110,7 +225,7 @@ func isBasicType(t string) bool {
 		"byte": true, "rune": true,
 		"float32": true, "float64": true,
 		"complex64": true, "complex128": true,
-		"interface{}": true, "any": true,
+		"interface{}": true, "any": true, "comparable": true,
 	}
 	return basicTypes[t]
 }
\ No newline at end of file
@@ -67,7 +67,7 @@ type UserSettings struct {
 				{
 					From: types.NewNodeID("test", "User"),
 					To:   types.NewNodeID("test", "Profile"),
-					Type: types.FieldDependency,
+					Type: types.EmbeddingDependency,
 				},
 				{
 					From: types.NewNodeID("test", "User"),
@@ -76,6 +76,28 @@ type UserSettings struct {
 				},
 			},
 		},
+		{
+			name: "埋め込みインターフェース",
+			code: `package test
+type Reader interface {
+	Read() error
+}
+type Number interface {
+	~int | ~float64
+}
+type ReadCloser interface {
+	Reader
+	error
+	Close() error
+}`,
+			expected: []DependencyInfo{
+				{
+					From: types.NewNodeID("test", "ReadCloser"),
+					To:   types.NewNodeID("test", "Reader"),
+					Type: types.EmbeddingDependency,
+				},
+			},
+		},
 		{
 			name: "複雑なポインタとスライス",
 			code: `package test
@@ -99,12 +121,12 @@ type Post struct {
 				},
 				{
 					From: types.NewNodeID("test", "User"),
-					To:   types.NewNodeID("test", "*Post"),
+					To:   types.NewNodeID("test", "Post"),
 					Type: types.FieldDependency,
 				},
 				{
 					From: types.NewNodeID("test", "User"),
-					To:   types.NewNodeID("test", "*User"),
+					To:   types.NewNodeID("test", "User"),
 					Type: types.FieldDependency,
 				},
 			},
@@ -176,6 +198,35 @@ type Storage interface {
 	}
 }
 
+func TestFieldDependencyExtractor_Positions(t *testing.T) {
+	code := `package test
+type User struct {
+	Name    string
+	Profile *Profile
+}
+type Profile struct{}`
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "user.go", code, 0)
+	if err != nil {
+		t.Fatalf("コード解析エラー: %v", err)
+	}
+
+	extractor := NewFieldDependencyExtractor(NewContext(fset, "test"))
+	result, err := extractor.ExtractDependencies(file, fset, "test")
+	if err != nil {
+		t.Fatalf("依存関係抽出エラー: %v", err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("依存関係数が一致しません。期待値: 1, 実際: %d", len(result))
+	}
+
+	// 依存を生じさせているフィールドの型の位置が記録される
+	if pos := result[0].Position; pos.Filename != "user.go" || pos.Line != 4 || pos.Column != 10 {
+		t.Errorf("依存関係の位置が一致しません: %v", pos)
+	}
+}
+
 func TestFieldDependencyExtractor_Name(t *testing.T) {
 	ctx := NewContext(token.NewFileSet(), "test")
 	extractor := NewFieldDependencyExtractor(ctx)
@@ -215,7 +266,7 @@ func TestFieldDependencyExtractor_ResolveType(t *testing.T) {
 			name:       "スライスポインタ型",
 			typeStr:    "[]*User",
 			currentPkg: "test",
-			expected:   "*User",
+			expected:   "User",
 		},
 		{
 			name:       "同パッケージ型",
@@ -243,3 +294,50 @@ func TestFieldDependencyExtractor_ResolveType(t *testing.T) {
 		})
 	}
 }
+
+func TestFieldDependencyExtractor_CrossPackage(t *testing.T) {
+	code := `package pkg1
+import (
+	"time"
+	"example.com/app/pkg2"
+	shared "example.com/app/common"
+)
+type User struct {
+	shared.Base
+	Profile  *pkg2.Profile
+	Settings map[string][]pkg2.Setting
+	Created  time.Time
+}
+func Load(p *pkg2.Profile) (*User, error) { return nil, nil }`
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", code, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("コード解析エラー: %v", err)
+	}
+	ctx := NewContext(fset, "pkg1")
+	ctx.PackagePath = "example.com/app/pkg1"
+	ctx.ImportMap = map[string]string{
+		"time":   "time",
+		"pkg2":   "example.com/app/pkg2",
+		"shared": "example.com/app/common",
+	}
+
+	var deps []DependencyInfo
+	for _, strategy := range []ExtractionStrategy{NewFieldDependencyExtractor(ctx), NewSignatureDependencyExtractor(ctx)} {
+		found, err := strategy.ExtractDependencies(file, fset, "example.com/app/pkg1")
+		if err != nil {
+			t.Fatalf("依存関係抽出エラー: %v", err)
+		}
+		deps = append(deps, found...)
+	}
+
+	// 埋め込みを含むローカルパッケージの型への参照は依存関係になり、標準ライブラリの型は除外される
+	assertDependencies(t, deps, []DependencyInfo{
+		{From: types.NewNodeID("example.com/app/pkg1", "User"), To: types.NewNodeID("example.com/app/common", "Base"), Type: types.EmbeddingDependency},
+		{From: types.NewNodeID("example.com/app/pkg1", "User"), To: types.NewNodeID("example.com/app/pkg2", "Profile"), Type: types.FieldDependency},
+		{From: types.NewNodeID("example.com/app/pkg1", "User"), To: types.NewNodeID("example.com/app/pkg2", "Setting"), Type: types.FieldDependency},
+		{From: types.NewNodeID("example.com/app/pkg1", "Load"), To: types.NewNodeID("example.com/app/pkg2", "Profile"), Type: types.SignatureDependency},
+		{From: types.NewNodeID("example.com/app/pkg1", "Load"), To: types.NewNodeID("example.com/app/pkg1", "User"), Type: types.SignatureDependency},
+	})
+}
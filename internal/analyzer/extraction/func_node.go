@@ -0,0 +1,35 @@
+package extraction
+
+import (
+	"go/ast"
+
+	"github.com/harakeishi/depsee/internal/types"
+)
+
+// funcNodeID returns the node ID of a function declaration.
+// Methods get a "pkg.Type.Method" ID so they never collide with functions or other types' methods.
+func funcNodeID(packagePath string, decl *ast.FuncDecl) types.NodeID {
+	if decl.Recv != nil && len(decl.Recv.List) > 0 {
+		if receiver := receiverTypeName(decl.Recv.List[0].Type); receiver != "" {
+			return types.NewMethodNodeID(packagePath, receiver, decl.Name.Name)
+		}
+	}
+	return types.NewNodeID(packagePath, decl.Name.Name)
+}
+
+// receiverTypeName returns the base type name of a method receiver expression
+func receiverTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return receiverTypeName(t.X)
+	case *ast.ParenExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(t.X)
+	}
+	return ""
+}
@@ -0,0 +1,236 @@
+package extraction
+
+import (
+	"go/ast"
+	"go/token"
+	gotypes "go/types"
+
+	"github.com/harakeishi/depsee/internal/logger"
+	"github.com/harakeishi/depsee/internal/types"
+)
+
+// FuncValueDependencyExtractor extracts references to functions and methods that are used as
+// values instead of being called: callbacks passed as arguments, functions stored in variables,
+// maps or struct literal fields, and functions returned from other functions.
+// Method values (h.ServeUser) and method expressions ((*Handler).ServeUser) resolve to the method node.
+// Function bodies are always scanned; package-level variable initializers are scanned only when
+// variables are nodes of the graph (includeGlobals).
+type FuncValueDependencyExtractor struct {
+	ctx            *Context
+	includeGlobals bool
+}
+
+// NewFuncValueDependencyExtractor creates a new function value dependency extractor
+func NewFuncValueDependencyExtractor(ctx *Context, includeGlobals bool) *FuncValueDependencyExtractor {
+	return &FuncValueDependencyExtractor{ctx: ctx, includeGlobals: includeGlobals}
+}
+
+// withContext returns a copy of the extractor bound to ctx
+func (e *FuncValueDependencyExtractor) withContext(ctx *Context) ExtractionStrategy {
+	return NewFuncValueDependencyExtractor(ctx, e.includeGlobals)
+}
+
+// ExtractDependencies extracts function value and method value dependencies
+func (e *FuncValueDependencyExtractor) ExtractDependencies(file *ast.File, fset *token.FileSet, packagePath string) ([]DependencyInfo, error) {
+	var dependencies []DependencyInfo
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Body == nil {
+				continue
+			}
+			var scope *staticTypes
+			if !e.ctx.IsTypeChecked() {
+				scope = newStaticTypes(e.ctx, e.ctx.declarationsFor(file, packagePath), d)
+				for name := range funcTypeParams(d) {
+					scope.set(name, typeRef{})
+				}
+			}
+			dependencies = append(dependencies, e.values(file, funcNodeID(packagePath, d), d.Body, scope, packagePath)...)
+		case *ast.GenDecl:
+			if d.Tok != token.VAR || !e.includeGlobals {
+				continue
+			}
+			for _, spec := range d.Specs {
+				valueSpec, ok := spec.(*ast.ValueSpec)
+				if !ok {
+					continue
+				}
+				for _, name := range valueSpec.Names {
+					if name.Name == "_" {
+						continue
+					}
+					fromID := types.NewNodeID(packagePath, name.Name)
+					for _, value := range valueSpec.Values {
+						var scope *staticTypes
+						if !e.ctx.IsTypeChecked() {
+							scope = &staticTypes{ctx: e.ctx, decls: e.ctx.declarationsFor(file, packagePath), vars: make(map[string]typeRef)}
+						}
+						dependencies = append(dependencies, e.values(file, fromID, value, scope, packagePath)...)
+					}
+				}
+			}
+		}
+	}
+
+	return dependencies, nil
+}
+
+// Name returns the strategy name
+func (e *FuncValueDependencyExtractor) Name() string {
+	return "FuncValueDependency"
+}
+
+// values returns the functions and methods referenced as values inside node.
+// The callee of a call expression is a call, not a value, and is left to the call strategies.
+// In name-based mode scope tracks the local variables that shadow functions; it is nil in type-checked mode.
+func (e *FuncValueDependencyExtractor) values(file *ast.File, fromID types.NodeID, node ast.Node, scope *staticTypes, packagePath string) []DependencyInfo {
+	var dependencies []DependencyInfo
+	called := make(map[ast.Expr]bool)
+	ast.Inspect(node, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			callee, _ := splitInstantiation(call.Fun)
+			called[callee] = true
+		}
+		return true
+	})
+
+	add := func(toID types.NodeID, ident *ast.Ident) {
+		// A function referring to itself (e.g. for recursion through a variable) is not a dependency
+		if toID == fromID {
+			return
+		}
+		dependencies = append(dependencies, DependencyInfo{
+			From:     fromID,
+			To:       toID,
+			Type:     types.FuncValueDependency,
+			Position: e.ctx.position(ident.Pos()),
+		})
+		logger.Debug("関数値依存関係追加", "from", fromID, "to", toID, "name", ident.Name)
+	}
+
+	var visit func(n ast.Node) bool
+	if e.ctx.IsTypeChecked() {
+		pkgScope := e.ctx.packageScope(file)
+		visit = func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.SelectorExpr:
+				if !called[n] {
+					if toID, ok := e.typedSelectorValue(n, pkgScope, packagePath); ok {
+						add(toID, n.Sel)
+					}
+				}
+				// The selected name never refers to a function of the current package
+				ast.Inspect(n.X, visit)
+				return false
+			case *ast.Ident:
+				if called[n] {
+					return true
+				}
+				if fn, ok := e.ctx.TypesInfo.Uses[n].(*gotypes.Func); ok && fn.Parent() == pkgScope {
+					add(types.NewNodeID(packagePath, fn.Name()), n)
+				}
+			}
+			return true
+		}
+		ast.Inspect(node, visit)
+		return dependencies
+	}
+
+	visit = func(n ast.Node) bool {
+		scope.record(n)
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			if !called[n] {
+				if toID, ok := e.selectorValue(n, scope, packagePath); ok {
+					add(toID, n.Sel)
+					return false
+				}
+			}
+			ast.Inspect(n.X, visit)
+			return false
+		case *ast.KeyValueExpr:
+			// Struct literal keys are field names
+			if _, ok := n.Key.(*ast.Ident); ok {
+				ast.Inspect(n.Value, visit)
+				return false
+			}
+		case *ast.LabeledStmt:
+			ast.Inspect(n.Stmt, visit)
+			return false
+		case *ast.BranchStmt:
+			return false
+		case *ast.Ident:
+			if called[n] {
+				return true
+			}
+			if _, local := scope.vars[n.Name]; local {
+				return true
+			}
+			if _, ok := scope.decls.funcs[n.Name]; ok && n.Name != "init" {
+				add(types.NewNodeID(packagePath, n.Name), n)
+			}
+		}
+		return true
+	}
+	ast.Inspect(node, visit)
+
+	return dependencies
+}
+
+// selectorValue resolves a selector used as a value in name-based mode: pkg.Func of an imported
+// local package, a method value recv.Method or a method expression Type.Method
+func (e *FuncValueDependencyExtractor) selectorValue(sel *ast.SelectorExpr, scope *staticTypes, packagePath string) (types.NodeID, bool) {
+	if owner, _, ok := scope.methodTarget(sel); ok {
+		return owner.methodNodeID(packagePath, sel.Sel.Name), true
+	}
+	qualifier, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	if _, local := scope.vars[qualifier.Name]; local || scope.decls.values[qualifier.Name] {
+		return "", false
+	}
+	importPath, ok := e.ctx.ImportMap[qualifier.Name]
+	if !ok {
+		return "", false
+	}
+	targetPkg := crossPackageTarget(e.ctx, importPath, qualifier.Name)
+	if targetPkg == "" {
+		return "", false
+	}
+	decls, ok := e.ctx.declarations[targetPkg]
+	if !ok {
+		return "", false
+	}
+	if _, ok := decls.funcs[sel.Sel.Name]; !ok {
+		return "", false
+	}
+	return types.NewNodeID(targetPkg, sel.Sel.Name), true
+}
+
+// typedSelectorValue resolves a selector used as a value through go/types: a function of another
+// local package, or a method value or method expression resolved like a method call
+func (e *FuncValueDependencyExtractor) typedSelectorValue(sel *ast.SelectorExpr, pkgScope *gotypes.Scope, packagePath string) (types.NodeID, bool) {
+	if _, ok := e.ctx.TypesInfo.Selections[sel]; ok {
+		toID, _, ok := (&BodyCallDependencyExtractor{ctx: e.ctx}).typedMethodCall(sel, pkgScope, packagePath)
+		return toID, ok
+	}
+	qualifier, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	if _, ok := e.ctx.TypesInfo.Uses[qualifier].(*gotypes.PkgName); !ok {
+		return "", false
+	}
+	fn, ok := e.ctx.TypesInfo.Uses[sel.Sel].(*gotypes.Func)
+	if !ok || !isPackageLevel(fn) {
+		return "", false
+	}
+	targetPkg := crossPackageTarget(e.ctx, fn.Pkg().Path(), fn.Pkg().Name())
+	if targetPkg == "" {
+		return "", false
+	}
+	return types.NewNodeID(targetPkg, fn.Name()), true
+}
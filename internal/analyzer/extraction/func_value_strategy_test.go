@@ -0,0 +1,162 @@
+package extraction
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/harakeishi/depsee/internal/types"
+)
+
+const funcValueTestCode = `package test
+
+import "sort"
+
+type Handler struct{}
+
+func (h *Handler) ServeUser(id string) {}
+
+type Router struct {
+	OnUser func(string)
+}
+
+type Repo struct{}
+
+func NewRepo() *Repo { return &Repo{} }
+
+func Less(a, b int) bool { return a < b }
+
+func Identity[T any](v T) T { return v }
+
+var factories = map[string]func() *Repo{"default": NewRepo}
+
+var clock func() *Repo
+
+func Register(h *Handler) Router {
+	clock = NewRepo
+	return Router{OnUser: h.ServeUser}
+}
+
+func Sort(items []int) {
+	sort.Slice(items, func(i, j int) bool { return Less(items[i], items[j]) })
+}
+
+func Serve() func(*Handler, string) {
+	return (*Handler).ServeUser
+}
+
+func Generic() func(int) int {
+	return Identity[int]
+}
+
+func Shadowed(NewRepo func() *Repo) {
+	f := NewRepo
+	_ = f
+	_ = NewRepo()
+}
+
+func Recursive() {
+	f := Recursive
+	_ = f
+}
+`
+
+func TestFuncValueDependencyExtractor(t *testing.T) {
+	id := func(name string) types.NodeID { return types.NewNodeID("test", name) }
+	serveUser := types.NewMethodNodeID("test", "Handler", "ServeUser")
+	expected := []DependencyInfo{
+		{From: id("Register"), To: id("NewRepo"), Type: types.FuncValueDependency},
+		{From: id("Register"), To: serveUser, Type: types.FuncValueDependency},
+		{From: id("Serve"), To: serveUser, Type: types.FuncValueDependency},
+		{From: id("Generic"), To: id("Identity"), Type: types.FuncValueDependency},
+	}
+	// 変数がノードになる場合は変数の初期化式からの参照も抽出する
+	withGlobals := append([]DependencyInfo{
+		{From: id("factories"), To: id("NewRepo"), Type: types.FuncValueDependency},
+	}, expected...)
+
+	for _, includeGlobals := range []bool{false, true} {
+		want, suffix := expected, ""
+		if includeGlobals {
+			want, suffix = withGlobals, "（変数ノードあり）"
+		}
+
+		t.Run("名前ベース"+suffix, func(t *testing.T) {
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, "test.go", funcValueTestCode, parser.ParseComments)
+			if err != nil {
+				t.Fatalf("コード解析エラー: %v", err)
+			}
+			deps, err := NewFuncValueDependencyExtractor(NewContext(fset, "test"), includeGlobals).ExtractDependencies(file, fset, "test")
+			if err != nil {
+				t.Fatalf("依存関係抽出エラー: %v", err)
+			}
+			assertDependencies(t, deps, want)
+		})
+
+		t.Run("型チェック"+suffix, func(t *testing.T) {
+			file, fset, info := parseAndCheck(t, funcValueTestCode)
+			ctx := NewContext(fset, "test")
+			ctx.TypesInfo = info
+			deps, err := NewFuncValueDependencyExtractor(ctx, includeGlobals).ExtractDependencies(file, fset, "test")
+			if err != nil {
+				t.Fatalf("依存関係抽出エラー: %v", err)
+			}
+			assertDependencies(t, deps, want)
+		})
+	}
+}
+
+func TestFuncValueDependencyExtractor_AcrossPackages(t *testing.T) {
+	sources := []struct {
+		path        string
+		packagePath string
+		code        string
+	}{
+		{
+			path:        "repo/repo.go",
+			packagePath: "example.com/app/repo",
+			code: `package repo
+type Repo struct{}
+func New() *Repo { return &Repo{} }
+`,
+		},
+		{
+			path:        "service/service.go",
+			packagePath: "example.com/app/service",
+			code: `package service
+import "example.com/app/repo"
+var factory = repo.New
+func Run() {
+	f := repo.New
+	_ = f
+	_ = repo.New()
+}
+`,
+		},
+	}
+
+	fset := token.NewFileSet()
+	var files []ParsedFile
+	for _, src := range sources {
+		file, err := parser.ParseFile(fset, src.path, src.code, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("コード解析エラー: %v", err)
+		}
+		files = append(files, ParsedFile{Path: src.path, PackagePath: src.packagePath, File: file, FileSet: fset})
+	}
+
+	extractor := NewStrategyBasedExtractor("")
+	extractor.AddStrategy(NewFuncValueDependencyExtractor(extractor.Context(), false))
+	deps, err := extractor.ExtractFromParsedFiles(files)
+	if err != nil {
+		t.Fatalf("依存関係抽出エラー: %v", err)
+	}
+	assertDependencies(t, deps, []DependencyInfo{
+		{
+			From: types.NewNodeID("example.com/app/service", "Run"),
+			To:   types.NewNodeID("example.com/app/repo", "New"),
+			Type: types.FuncValueDependency,
+		},
+	})
+}
@@ -0,0 +1,112 @@
+package extraction
+
+import (
+	"go/ast"
+	gotypes "go/types"
+)
+
+// typeParamNames returns the names declared in type parameter lists
+func typeParamNames(lists ...*ast.FieldList) map[string]bool {
+	names := make(map[string]bool)
+	for _, list := range lists {
+		if list == nil {
+			continue
+		}
+		for _, field := range list.List {
+			for _, name := range field.Names {
+				names[name.Name] = true
+			}
+		}
+	}
+	return names
+}
+
+// funcTypeParams returns the type parameters in scope of a function declaration:
+// its own type parameter list and the ones bound by a generic receiver such as *Repo[T]
+func funcTypeParams(decl *ast.FuncDecl) map[string]bool {
+	names := typeParamNames(decl.Type.TypeParams)
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return names
+	}
+	expr := decl.Recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	var indices []ast.Expr
+	switch t := ast.Unparen(expr).(type) {
+	case *ast.IndexExpr:
+		indices = []ast.Expr{t.Index}
+	case *ast.IndexListExpr:
+		indices = t.Indices
+	}
+	for _, index := range indices {
+		if ident, ok := index.(*ast.Ident); ok {
+			names[ident.Name] = true
+		}
+	}
+	return names
+}
+
+// splitInstantiation separates an instantiation such as Map[User] or Pair[K, V]
+// into the generic expression and its type arguments.
+// Non-generic expressions are returned unchanged with no type arguments.
+func splitInstantiation(expr ast.Expr) (ast.Expr, []ast.Expr) {
+	switch t := ast.Unparen(expr).(type) {
+	case *ast.IndexExpr:
+		return t.X, []ast.Expr{t.Index}
+	case *ast.IndexListExpr:
+		return t.X, t.Indices
+	}
+	return ast.Unparen(expr), nil
+}
+
+// instantiations returns the generic instantiations written in a type expression, including
+// nested ones: both Repo[Pair[K, V]] and Pair[K, V] for []*Repo[Pair[K, V]]
+func instantiations(expr ast.Expr) []ast.Expr {
+	var found []ast.Expr
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch t := n.(type) {
+		case *ast.IndexExpr, *ast.IndexListExpr:
+			found = append(found, t.(ast.Expr))
+		case *ast.ArrayType:
+			// Array lengths are constants, not types
+			ast.Inspect(t.Elt, visit)
+			return false
+		}
+		return true
+	}
+	ast.Inspect(expr, visit)
+	return found
+}
+
+// namedTypesOf returns the named types making up a type, looking through
+// pointers, slices, arrays, maps, channels and the type arguments of instantiated types
+func namedTypesOf(t gotypes.Type) []*gotypes.TypeName {
+	var names []*gotypes.TypeName
+	var visit func(t gotypes.Type)
+	visit = func(t gotypes.Type) {
+		switch t := t.(type) {
+		case *gotypes.Named:
+			names = append(names, t.Origin().Obj())
+			for i := 0; i < t.TypeArgs().Len(); i++ {
+				visit(t.TypeArgs().At(i))
+			}
+		case *gotypes.Alias:
+			names = append(names, t.Obj())
+		case *gotypes.Pointer:
+			visit(t.Elem())
+		case *gotypes.Slice:
+			visit(t.Elem())
+		case *gotypes.Array:
+			visit(t.Elem())
+		case *gotypes.Map:
+			visit(t.Key())
+			visit(t.Elem())
+		case *gotypes.Chan:
+			visit(t.Elem())
+		}
+	}
+	visit(t)
+	return names
+}
@@ -0,0 +1,228 @@
+package extraction
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/harakeishi/depsee/internal/types"
+)
+
+const genericsTestCode = `package test
+type Entity interface{ ID() int }
+type MyInt int
+type Number interface{ ~int64 | MyInt }
+type User struct{}
+func (User) ID() int { return 0 }
+type Repo[T Entity] struct {
+	items []T
+	index map[int]*T
+}
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+type List[T any] []T
+type Service struct {
+	users Repo[User]
+	pairs []Pair[MyInt, *User]
+}
+func (r *Repo[T]) Get(id int) T {
+	var zero T
+	return zero
+}
+func Map[T Entity, R any](items []T, f func(T) R) []R {
+	return nil
+}
+func Sum[N Number](values ...N) N {
+	var total N
+	return total
+}
+func Run(users []User) {
+	_ = Map[User, MyInt](users, nil)
+	_ = Sum(MyInt(1))
+	var repo *Repo[User]
+	_ = repo
+}
+`
+
+func TestGenericsDependencies(t *testing.T) {
+	id := func(name string) types.NodeID { return types.NewNodeID("test", name) }
+
+	tests := []struct {
+		name     string
+		strategy func(ctx *Context) ExtractionStrategy
+		expected []DependencyInfo
+	}{
+		{
+			name:     "フィールドのインスタンス化",
+			strategy: func(ctx *Context) ExtractionStrategy { return NewFieldDependencyExtractor(ctx) },
+			expected: []DependencyInfo{
+				{From: id("Service"), To: id("Repo"), Type: types.FieldDependency},
+				{From: id("Service"), To: id("User"), Type: types.FieldDependency},
+				{From: id("Service"), To: id("Pair"), Type: types.FieldDependency},
+				{From: id("Service"), To: id("MyInt"), Type: types.FieldDependency},
+				{From: id("Service"), To: id("Repo"), Type: types.InstantiationDependency},
+				{From: id("Service"), To: id("Pair"), Type: types.InstantiationDependency},
+			},
+		},
+		{
+			name:     "変数宣言のインスタンス化",
+			strategy: func(ctx *Context) ExtractionStrategy { return NewBodyTypeUsageDependencyExtractor(ctx) },
+			expected: []DependencyInfo{
+				{From: id("Run"), To: id("MyInt"), Type: types.TypeUsageDependency},
+				{From: id("Run"), To: id("Repo"), Type: types.InstantiationDependency},
+				{From: id("Run"), To: id("User"), Type: types.TypeUsageDependency},
+			},
+		},
+		{
+			name:     "シグネチャの型パラメータは除外",
+			strategy: func(ctx *Context) ExtractionStrategy { return NewSignatureDependencyExtractor(ctx) },
+			expected: []DependencyInfo{
+				{From: types.NewMethodNodeID("test", "Repo", "Get"), To: id("Repo"), Type: types.ReceiverDependency},
+				{From: id("Run"), To: id("User"), Type: types.SignatureDependency},
+				{From: types.NewMethodNodeID("test", "User", "ID"), To: id("User"), Type: types.ReceiverDependency},
+				{From: types.NewMethodNodeID("test", "Entity", "ID"), To: id("Entity"), Type: types.ReceiverDependency},
+			},
+		},
+		{
+			name:     "型パラメータの制約と型制約インターフェース",
+			strategy: func(ctx *Context) ExtractionStrategy { return NewConstraintDependencyExtractor(ctx) },
+			expected: []DependencyInfo{
+				{From: id("Number"), To: id("MyInt"), Type: types.ConstraintDependency},
+				{From: id("Repo"), To: id("Entity"), Type: types.ConstraintDependency},
+				{From: id("Map"), To: id("Entity"), Type: types.ConstraintDependency},
+				{From: id("Sum"), To: id("Number"), Type: types.ConstraintDependency},
+			},
+		},
+		{
+			name:     "ジェネリックな名前付き型",
+			strategy: func(ctx *Context) ExtractionStrategy { return NewNamedTypeDependencyExtractor(ctx) },
+			expected: []DependencyInfo{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"_名前ベース", func(t *testing.T) {
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, "test.go", genericsTestCode, parser.ParseComments)
+			if err != nil {
+				t.Fatalf("コード解析エラー: %v", err)
+			}
+			deps, err := tt.strategy(NewContext(fset, "test")).ExtractDependencies(file, fset, "test")
+			if err != nil {
+				t.Fatalf("依存関係抽出エラー: %v", err)
+			}
+			assertDependencies(t, deps, tt.expected)
+		})
+		t.Run(tt.name+"_型チェック", func(t *testing.T) {
+			file, fset, info := parseAndCheck(t, genericsTestCode)
+			ctx := NewContext(fset, "test")
+			ctx.TypesInfo = info
+			deps, err := tt.strategy(ctx).ExtractDependencies(file, fset, "test")
+			if err != nil {
+				t.Fatalf("依存関係抽出エラー: %v", err)
+			}
+			assertDependencies(t, deps, tt.expected)
+		})
+	}
+}
+
+func TestGenericsBodyCallDependencies(t *testing.T) {
+	// 明示的な型引数（型チェック時は推論された型引数も）が依存関係になる
+	expected := []DependencyInfo{
+		{From: types.NewNodeID("test", "Run"), To: types.NewNodeID("test", "Map"), Type: types.BodyCallDependency},
+		{From: types.NewNodeID("test", "Run"), To: types.NewNodeID("test", "User"), Type: types.BodyCallDependency},
+		{From: types.NewNodeID("test", "Run"), To: types.NewNodeID("test", "MyInt"), Type: types.BodyCallDependency},
+		{From: types.NewNodeID("test", "Run"), To: types.NewNodeID("test", "Sum"), Type: types.BodyCallDependency},
+	}
+
+	t.Run("名前ベース", func(t *testing.T) {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "test.go", genericsTestCode, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("コード解析エラー: %v", err)
+		}
+		deps, err := NewBodyCallDependencyExtractor(NewContext(fset, "test")).ExtractDependencies(file, fset, "test")
+		if err != nil {
+			t.Fatalf("依存関係抽出エラー: %v", err)
+		}
+		assertDependencies(t, deps, expected)
+	})
+
+	t.Run("型チェック", func(t *testing.T) {
+		file, fset, info := parseAndCheck(t, genericsTestCode)
+		ctx := NewContext(fset, "test")
+		ctx.TypesInfo = info
+		deps, err := NewBodyCallDependencyExtractor(ctx).ExtractDependencies(file, fset, "test")
+		if err != nil {
+			t.Fatalf("依存関係抽出エラー: %v", err)
+		}
+		assertDependencies(t, deps, expected)
+	})
+}
+
+func TestGenericsDependencies_AcrossPackages(t *testing.T) {
+	sources := []struct {
+		path        string
+		packagePath string
+		code        string
+	}{
+		{
+			path:        "model/model.go",
+			packagePath: "example.com/app/model",
+			code: `package model
+type Entity interface{ ID() int }
+type User struct{}
+type Repo[T Entity] struct{}
+`,
+		},
+		{
+			path:        "svc/service.go",
+			packagePath: "example.com/app/svc",
+			code: `package svc
+import "example.com/app/model"
+type Cache[T model.Entity] struct {
+	repo model.Repo[T]
+}
+func Find[T model.Entity](id int) {
+	var r model.Repo[*model.User]
+	_ = r
+}
+`,
+		},
+	}
+
+	fset := token.NewFileSet()
+	var files []ParsedFile
+	for _, src := range sources {
+		file, err := parser.ParseFile(fset, src.path, src.code, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("コード解析エラー: %v", err)
+		}
+		files = append(files, ParsedFile{Path: src.path, PackagePath: src.packagePath, File: file, FileSet: fset})
+	}
+
+	extractor := NewStrategyBasedExtractor("")
+	extractor.AddStrategy(NewConstraintDependencyExtractor(extractor.Context()))
+	extractor.AddStrategy(NewFieldDependencyExtractor(extractor.Context()))
+	extractor.AddStrategy(NewBodyTypeUsageDependencyExtractor(extractor.Context()))
+	deps, err := extractor.ExtractFromParsedFiles(files)
+	if err != nil {
+		t.Fatalf("依存関係抽出エラー: %v", err)
+	}
+
+	// 修飾された制約と、フィールド・変数宣言でのインスタンス化は別パッケージの型への依存関係になる
+	model := func(name string) types.NodeID { return types.NewNodeID("example.com/app/model", name) }
+	cache := types.NewNodeID("example.com/app/svc", "Cache")
+	find := types.NewNodeID("example.com/app/svc", "Find")
+	assertDependencies(t, deps, []DependencyInfo{
+		{From: model("Repo"), To: model("Entity"), Type: types.ConstraintDependency},
+		{From: cache, To: model("Entity"), Type: types.ConstraintDependency},
+		{From: find, To: model("Entity"), Type: types.ConstraintDependency},
+		{From: cache, To: model("Repo"), Type: types.FieldDependency},
+		{From: cache, To: model("Repo"), Type: types.InstantiationDependency},
+		{From: find, To: model("Repo"), Type: types.InstantiationDependency},
+		{From: find, To: model("User"), Type: types.TypeUsageDependency},
+	})
+}
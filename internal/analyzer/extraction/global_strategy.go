@@ -0,0 +1,232 @@
+package extraction
+
+import (
+	"go/ast"
+	"go/token"
+	gotypes "go/types"
+
+	"github.com/harakeishi/depsee/internal/logger"
+	"github.com/harakeishi/depsee/internal/types"
+)
+
+// GlobalDependencyExtractor extracts reads and writes of package-level variables and constants.
+// Function bodies (including init) depend on the globals they use, and package-level variable
+// initializers read the globals used in their initial values. Assignments and increments of a
+// global, or of a field or element reached through it, are writes; every other use is a read.
+type GlobalDependencyExtractor struct {
+	ctx *Context
+}
+
+// NewGlobalDependencyExtractor creates a new global dependency extractor
+func NewGlobalDependencyExtractor(ctx *Context) *GlobalDependencyExtractor {
+	return &GlobalDependencyExtractor{ctx: ctx}
+}
+
+// withContext returns a copy of the extractor bound to ctx
+func (e *GlobalDependencyExtractor) withContext(ctx *Context) ExtractionStrategy {
+	return NewGlobalDependencyExtractor(ctx)
+}
+
+// ExtractDependencies extracts global variable and constant dependencies
+func (e *GlobalDependencyExtractor) ExtractDependencies(file *ast.File, fset *token.FileSet, packagePath string) ([]DependencyInfo, error) {
+	var dependencies []DependencyInfo
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Body == nil {
+				continue
+			}
+			fromID := funcNodeID(packagePath, d)
+			var scope *staticTypes
+			if !e.ctx.IsTypeChecked() {
+				scope = newStaticTypes(e.ctx, e.ctx.declarationsFor(file, packagePath), d)
+			}
+			dependencies = append(dependencies, e.accesses(file, fromID, d.Body, scope, packagePath)...)
+		case *ast.GenDecl:
+			if d.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range d.Specs {
+				valueSpec, ok := spec.(*ast.ValueSpec)
+				if !ok {
+					continue
+				}
+				for _, name := range valueSpec.Names {
+					if name.Name == "_" {
+						continue
+					}
+					fromID := types.NewNodeID(packagePath, name.Name)
+					for _, value := range valueSpec.Values {
+						var scope *staticTypes
+						if !e.ctx.IsTypeChecked() {
+							scope = &staticTypes{ctx: e.ctx, decls: e.ctx.declarationsFor(file, packagePath), vars: make(map[string]typeRef)}
+						}
+						for _, dep := range e.accesses(file, fromID, value, scope, packagePath) {
+							if dep.To != fromID {
+								dependencies = append(dependencies, dep)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return dependencies, nil
+}
+
+// Name returns the strategy name
+func (e *GlobalDependencyExtractor) Name() string {
+	return "GlobalDependency"
+}
+
+// accesses returns the global reads and writes made inside node.
+// In name-based mode scope tracks the local variables that shadow globals; it is nil in type-checked mode.
+func (e *GlobalDependencyExtractor) accesses(file *ast.File, fromID types.NodeID, node ast.Node, scope *staticTypes, packagePath string) []DependencyInfo {
+	var dependencies []DependencyInfo
+	writes := writeTargets(node)
+
+	add := func(toID types.NodeID, ident *ast.Ident) {
+		depType := types.ReadDependency
+		if writes[ident] {
+			depType = types.WriteDependency
+		}
+		dependencies = append(dependencies, DependencyInfo{
+			From:     fromID,
+			To:       toID,
+			Type:     depType,
+			Position: e.ctx.position(ident.Pos()),
+		})
+		logger.Debug("グローバル変数依存関係追加", "from", fromID, "to", toID, "type", depType)
+	}
+
+	if e.ctx.IsTypeChecked() {
+		pkgScope := e.ctx.packageScope(file)
+		ast.Inspect(node, func(n ast.Node) bool {
+			ident, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			if toID, ok := e.typedGlobal(ident, pkgScope, packagePath); ok {
+				add(toID, ident)
+			}
+			return true
+		})
+		return dependencies
+	}
+
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		scope.record(n)
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			// A qualified reference pkg.Name is resolved against the imported package;
+			// the selected name is never a global of the current package
+			if toID, ok := e.qualifiedGlobal(n, scope); ok {
+				add(toID, n.Sel)
+				return false
+			}
+			ast.Inspect(n.X, visit)
+			return false
+		case *ast.KeyValueExpr:
+			// Struct literal keys are field names
+			if _, ok := n.Key.(*ast.Ident); ok {
+				ast.Inspect(n.Value, visit)
+				return false
+			}
+		case *ast.Ident:
+			if _, local := scope.vars[n.Name]; !local && scope.decls.values[n.Name] {
+				add(types.NewNodeID(packagePath, n.Name), n)
+			}
+		}
+		return true
+	}
+	ast.Inspect(node, visit)
+
+	return dependencies
+}
+
+// typedGlobal resolves an identifier to the node of a package-level variable or constant.
+// Globals of other local packages are resolved with the same rules as cross-package calls.
+func (e *GlobalDependencyExtractor) typedGlobal(ident *ast.Ident, pkgScope *gotypes.Scope, packagePath string) (types.NodeID, bool) {
+	obj := e.ctx.TypesInfo.Uses[ident]
+	switch obj.(type) {
+	case *gotypes.Var, *gotypes.Const:
+	default:
+		return "", false
+	}
+	if !isPackageLevel(obj) || obj.Name() == "_" {
+		return "", false
+	}
+	if obj.Parent() == pkgScope {
+		return types.NewNodeID(packagePath, obj.Name()), true
+	}
+	targetPkg := crossPackageTarget(e.ctx, obj.Pkg().Path(), obj.Pkg().Name())
+	if targetPkg == "" {
+		return "", false
+	}
+	return types.NewNodeID(targetPkg, obj.Name()), true
+}
+
+// qualifiedGlobal resolves pkg.Name to a global of an imported local package.
+// Only packages indexed by the extractor are known, so a name-based run on a single file never resolves it.
+func (e *GlobalDependencyExtractor) qualifiedGlobal(sel *ast.SelectorExpr, scope *staticTypes) (types.NodeID, bool) {
+	qualifier, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	if _, local := scope.vars[qualifier.Name]; local || scope.decls.values[qualifier.Name] {
+		return "", false
+	}
+	importPath, ok := e.ctx.ImportMap[qualifier.Name]
+	if !ok {
+		return "", false
+	}
+	targetPkg := crossPackageTarget(e.ctx, importPath, qualifier.Name)
+	if decls, ok := e.ctx.declarations[targetPkg]; !ok || targetPkg == "" || !decls.values[sel.Sel.Name] {
+		return "", false
+	}
+	return types.NewNodeID(targetPkg, sel.Sel.Name), true
+}
+
+// writeTargets returns the identifiers written by assignments and increments inside node.
+// Writing x.f, x[i] or *x writes x as well, so every identifier along the written expression is included.
+func writeTargets(node ast.Node) map[*ast.Ident]bool {
+	writes := make(map[*ast.Ident]bool)
+	mark := func(expr ast.Expr) {
+		for expr != nil {
+			switch e := expr.(type) {
+			case *ast.Ident:
+				writes[e] = true
+				return
+			case *ast.SelectorExpr:
+				writes[e.Sel] = true
+				expr = e.X
+			case *ast.IndexExpr:
+				expr = e.X
+			case *ast.StarExpr:
+				expr = e.X
+			case *ast.ParenExpr:
+				expr = e.X
+			default:
+				return
+			}
+		}
+	}
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE {
+				return true
+			}
+			for _, lhs := range n.Lhs {
+				mark(lhs)
+			}
+		case *ast.IncDecStmt:
+			mark(n.X)
+		}
+		return true
+	})
+	return writes
+}
@@ -0,0 +1,143 @@
+package extraction
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/harakeishi/depsee/internal/types"
+)
+
+const globalTestCode = `package test
+
+import "errors"
+
+type Config struct {
+	Timeout int
+}
+
+const MaxRetry = 3
+
+var ErrNotFound = errors.New("not found")
+
+var registry = map[string]Config{}
+
+var defaultConfig = Config{Timeout: MaxRetry}
+
+var counter int
+
+func init() {
+	registry["default"] = defaultConfig
+}
+
+func Lookup(name string) (Config, error) {
+	c, ok := registry[name]
+	if !ok {
+		return Config{}, ErrNotFound
+	}
+	return c, nil
+}
+
+func Increment() {
+	counter++
+	defaultConfig.Timeout = MaxRetry
+}
+
+func Shadowed(counter int, items []string) int {
+	registry := 1
+	for _, defaultConfig := range items {
+		_ = defaultConfig
+	}
+	return counter + registry
+}
+`
+
+func TestGlobalDependencyExtractor(t *testing.T) {
+	id := func(name string) types.NodeID { return types.NewNodeID("test", name) }
+	expected := []DependencyInfo{
+		{From: id("defaultConfig"), To: id("MaxRetry"), Type: types.ReadDependency},
+		{From: id("init"), To: id("registry"), Type: types.WriteDependency},
+		{From: id("init"), To: id("defaultConfig"), Type: types.ReadDependency},
+		{From: id("Lookup"), To: id("registry"), Type: types.ReadDependency},
+		{From: id("Lookup"), To: id("ErrNotFound"), Type: types.ReadDependency},
+		{From: id("Increment"), To: id("counter"), Type: types.WriteDependency},
+		{From: id("Increment"), To: id("defaultConfig"), Type: types.WriteDependency},
+		{From: id("Increment"), To: id("MaxRetry"), Type: types.ReadDependency},
+	}
+
+	t.Run("名前ベース", func(t *testing.T) {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "test.go", globalTestCode, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("コード解析エラー: %v", err)
+		}
+		deps, err := NewGlobalDependencyExtractor(NewContext(fset, "test")).ExtractDependencies(file, fset, "test")
+		if err != nil {
+			t.Fatalf("依存関係抽出エラー: %v", err)
+		}
+		assertDependencies(t, deps, expected)
+	})
+
+	t.Run("型チェック", func(t *testing.T) {
+		file, fset, info := parseAndCheck(t, globalTestCode)
+		ctx := NewContext(fset, "test")
+		ctx.TypesInfo = info
+		deps, err := NewGlobalDependencyExtractor(ctx).ExtractDependencies(file, fset, "test")
+		if err != nil {
+			t.Fatalf("依存関係抽出エラー: %v", err)
+		}
+		assertDependencies(t, deps, expected)
+	})
+}
+
+func TestGlobalDependencyExtractor_AcrossPackages(t *testing.T) {
+	sources := []struct {
+		path        string
+		packagePath string
+		code        string
+	}{
+		{
+			path:        "store/store.go",
+			packagePath: "example.com/app/store",
+			code: `package store
+var DefaultStore = "memory"
+func Open() {}
+`,
+		},
+		{
+			path:        "service/service.go",
+			packagePath: "example.com/app/service",
+			code: `package service
+import "example.com/app/store"
+func Run() {
+	store.DefaultStore = "disk"
+	store.Open()
+}
+`,
+		},
+	}
+
+	fset := token.NewFileSet()
+	var files []ParsedFile
+	for _, src := range sources {
+		file, err := parser.ParseFile(fset, src.path, src.code, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("コード解析エラー: %v", err)
+		}
+		files = append(files, ParsedFile{Path: src.path, PackagePath: src.packagePath, File: file, FileSet: fset})
+	}
+
+	extractor := NewStrategyBasedExtractor("")
+	extractor.AddStrategy(NewGlobalDependencyExtractor(extractor.Context()))
+	deps, err := extractor.ExtractFromParsedFiles(files)
+	if err != nil {
+		t.Fatalf("依存関係抽出エラー: %v", err)
+	}
+	assertDependencies(t, deps, []DependencyInfo{
+		{
+			From: types.NewNodeID("example.com/app/service", "Run"),
+			To:   types.NewNodeID("example.com/app/store", "DefaultStore"),
+			Type: types.WriteDependency,
+		},
+	})
+}
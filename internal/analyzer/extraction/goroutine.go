@@ -0,0 +1,137 @@
+package extraction
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+
+	"github.com/harakeishi/depsee/internal/types"
+)
+
+// Everything written inside a function literal — a callback, a deferred closure or a goroutine —
+// is attributed to the function or method declaring it, so strategies walk closures as part of
+// the enclosing body. Goroutine closures are the one exception, and only on request:
+// StrategyBasedExtractor.SetGoroutineNodes moves the dependencies of long-lived goroutine entry
+// closures onto nodes of their own.
+
+// GoroutineClosure is a function literal started as a goroutine (go func() { ... }()) whose body
+// runs a loop or a select, i.e. a long-lived worker rather than a short task
+type GoroutineClosure struct {
+	Name    string       // "Func.go#N" or "Type.Method.go#N", N counting the closures of the declaration from 1
+	ID      types.NodeID // node ID of the closure
+	Spawner types.NodeID // enclosing function or method, or the enclosing goroutine closure
+	Go      *ast.GoStmt
+	Lit     *ast.FuncLit
+	decl    types.NodeID // function or method declaring the closure
+}
+
+// GoroutineClosures returns the long-lived goroutine entry closures of a file in source order.
+// Arguments of the go statement are evaluated by the spawner and stay attributed to it.
+func GoroutineClosures(file *ast.File, packagePath string) []GoroutineClosure {
+	var closures []GoroutineClosure
+	for _, decl := range file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Body == nil {
+			continue
+		}
+		declID := funcNodeID(packagePath, funcDecl)
+		declName := funcDecl.Name.Name
+		if funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 {
+			if receiver := receiverTypeName(funcDecl.Recv.List[0].Type); receiver != "" {
+				declName = receiver + "." + declName
+			}
+		}
+
+		count := 0
+		var walk func(node ast.Node, spawner types.NodeID)
+		walk = func(node ast.Node, spawner types.NodeID) {
+			ast.Inspect(node, func(n ast.Node) bool {
+				goStmt, ok := n.(*ast.GoStmt)
+				if !ok {
+					return true
+				}
+				lit, ok := ast.Unparen(goStmt.Call.Fun).(*ast.FuncLit)
+				if !ok || !isLongLived(lit) {
+					return true
+				}
+				count++
+				name := declName + ".go#" + strconv.Itoa(count)
+				closure := GoroutineClosure{
+					Name:    name,
+					ID:      types.NewNodeID(packagePath, name),
+					Spawner: spawner,
+					Go:      goStmt,
+					Lit:     lit,
+					decl:    declID,
+				}
+				closures = append(closures, closure)
+				walk(lit.Body, closure.ID)
+				for _, arg := range goStmt.Call.Args {
+					walk(arg, spawner)
+				}
+				return false
+			})
+		}
+		walk(funcDecl.Body, declID)
+	}
+	return closures
+}
+
+// isLongLived reports whether a goroutine closure loops or waits in a select outside nested closures
+func isLongLived(lit *ast.FuncLit) bool {
+	found := false
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.ForStmt, *ast.RangeStmt, *ast.SelectStmt:
+			found = true
+		case *ast.FuncLit:
+			return false
+		}
+		return !found
+	})
+	return found
+}
+
+// attributeGoroutines moves the dependencies found inside goroutine closures from the declaring
+// function to the innermost closure, and adds a spawn dependency from each spawner to its closure
+func attributeGoroutines(deps []DependencyInfo, closures []GoroutineClosure, fset *token.FileSet) []DependencyInfo {
+	if len(closures) == 0 {
+		return deps
+	}
+	type span struct {
+		filename   string
+		start, end int
+	}
+	spans := make([]span, len(closures))
+	for i, c := range closures {
+		start, end := fset.Position(c.Lit.Pos()), fset.Position(c.Lit.End())
+		spans[i] = span{filename: start.Filename, start: start.Offset, end: end.Offset}
+	}
+
+	for i, dep := range deps {
+		innermost := -1
+		for j, c := range closures {
+			s := spans[j]
+			if dep.From != c.decl || dep.Position.Filename != s.filename || dep.Position.Offset < s.start || dep.Position.Offset >= s.end {
+				continue
+			}
+			// Closures nest, so a later match lies inside the earlier ones
+			if innermost < 0 || s.start >= spans[innermost].start {
+				innermost = j
+			}
+		}
+		if innermost >= 0 {
+			deps[i].From = closures[innermost].ID
+		}
+	}
+
+	for _, c := range closures {
+		deps = append(deps, DependencyInfo{
+			From:     c.Spawner,
+			To:       c.ID,
+			Type:     types.SpawnDependency,
+			Position: fset.Position(c.Go.Pos()),
+		})
+	}
+	return deps
+}
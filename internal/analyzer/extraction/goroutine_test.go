@@ -0,0 +1,141 @@
+package extraction
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/harakeishi/depsee/internal/types"
+)
+
+const goroutineTestCode = `package test
+
+type Server struct {
+	jobs chan int
+}
+
+func handle(j int) {}
+func tick()        {}
+func once()        {}
+func cleanup()     {}
+func arg() int     { return 0 }
+
+func (s *Server) Run() {
+	defer func() { cleanup() }()
+	go func(n int) {
+		for j := range s.jobs {
+			handle(j)
+			go func() {
+				for {
+					tick()
+				}
+			}()
+		}
+	}(arg())
+	go func() { once() }()
+}
+`
+
+func TestGoroutineClosures(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", goroutineTestCode, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("コード解析エラー: %v", err)
+	}
+
+	run := types.NewMethodNodeID("test", "Server", "Run")
+	expected := []struct {
+		name    string
+		spawner types.NodeID
+		line    int
+	}{
+		{name: "Server.Run.go#1", spawner: run, line: 15},
+		{name: "Server.Run.go#2", spawner: types.NewNodeID("test", "Server.Run.go#1"), line: 18},
+	}
+
+	// ループやselectを含まない短いgoroutineは対象外
+	closures := GoroutineClosures(file, "test")
+	if len(closures) != len(expected) {
+		t.Fatalf("goroutine数が一致しません。期待値: %d, 実際: %d", len(expected), len(closures))
+	}
+	for i, exp := range expected {
+		c := closures[i]
+		if c.Name != exp.name || c.ID != types.NewNodeID("test", exp.name) || c.Spawner != exp.spawner {
+			t.Errorf("goroutine[%d]が一致しません: name=%s id=%s spawner=%s", i, c.Name, c.ID, c.Spawner)
+		}
+		if line := fset.Position(c.Go.Pos()).Line; line != exp.line {
+			t.Errorf("goroutine[%d]の起動箇所が一致しません。期待値: %d行目, 実際: %d行目", i, exp.line, line)
+		}
+	}
+}
+
+func TestStrategyBasedExtractor_GoroutineNodes(t *testing.T) {
+	run := types.NewMethodNodeID("test", "Server", "Run")
+	worker := types.NewNodeID("test", "Server.Run.go#1")
+	ticker := types.NewNodeID("test", "Server.Run.go#2")
+	id := func(name string) types.NodeID { return types.NewNodeID("test", name) }
+
+	tests := []struct {
+		name       string
+		goroutines bool
+		expected   []DependencyInfo
+	}{
+		{
+			name:       "関数リテラルの中の依存関係は外側のメソッドのもの",
+			goroutines: false,
+			expected: []DependencyInfo{
+				{From: run, To: id("cleanup"), Type: types.BodyCallDependency},
+				{From: run, To: id("handle"), Type: types.BodyCallDependency},
+				{From: run, To: id("tick"), Type: types.BodyCallDependency},
+				{From: run, To: id("arg"), Type: types.BodyCallDependency},
+				{From: run, To: id("once"), Type: types.BodyCallDependency},
+			},
+		},
+		{
+			name:       "goroutineノードを作成する場合はgoroutineのもの",
+			goroutines: true,
+			expected: []DependencyInfo{
+				{From: run, To: id("cleanup"), Type: types.BodyCallDependency},
+				{From: worker, To: id("handle"), Type: types.BodyCallDependency},
+				{From: ticker, To: id("tick"), Type: types.BodyCallDependency},
+				// goステートメントの引数は起動元で評価される
+				{From: run, To: id("arg"), Type: types.BodyCallDependency},
+				{From: run, To: id("once"), Type: types.BodyCallDependency},
+				{From: run, To: worker, Type: types.SpawnDependency},
+				{From: worker, To: ticker, Type: types.SpawnDependency},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, typeChecked := range []bool{false, true} {
+			mode := "名前ベース"
+			if typeChecked {
+				mode = "型チェック"
+			}
+			t.Run(tt.name+"（"+mode+"）", func(t *testing.T) {
+				var pf ParsedFile
+				if typeChecked {
+					file, fset, info := parseAndCheck(t, goroutineTestCode)
+					pf = ParsedFile{Path: "test.go", PackagePath: "test", File: file, FileSet: fset, TypesInfo: info}
+				} else {
+					fset := token.NewFileSet()
+					file, err := parser.ParseFile(fset, "test.go", goroutineTestCode, parser.ParseComments)
+					if err != nil {
+						t.Fatalf("コード解析エラー: %v", err)
+					}
+					pf = ParsedFile{Path: "test.go", PackagePath: "test", File: file, FileSet: fset}
+				}
+
+				extractor := NewStrategyBasedExtractor("")
+				extractor.AddStrategy(NewBodyCallDependencyExtractor(extractor.Context()))
+				extractor.SetGoroutineNodes(tt.goroutines)
+				deps, err := extractor.ExtractFromParsedFiles([]ParsedFile{pf})
+				if err != nil {
+					t.Fatalf("依存関係抽出エラー: %v", err)
+				}
+				assertDependencies(t, deps, tt.expected)
+			})
+		}
+	}
+}
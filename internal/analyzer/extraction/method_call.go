@@ -0,0 +1,567 @@
+package extraction
+
+import (
+	"go/ast"
+	"go/token"
+	gotypes "go/types"
+
+	"github.com/harakeishi/depsee/internal/types"
+)
+
+// declaredType describes a package-level type declaration for name-based method resolution
+type declaredType struct {
+	fields  map[string]ast.Expr   // field name -> field type (embedded fields use the type name)
+	embeds  []string              // embedded local type names, in declaration order
+	methods map[string][]ast.Expr // method name -> result types
+}
+
+// packageDecls indexes the package-level declarations of one package so that
+// name-based strategies can infer static types across the files of the package
+type packageDecls struct {
+	types  map[string]*declaredType
+	funcs  map[string][]ast.Expr // function name -> result types
+	values map[string]bool       // package-level variable and constant names
+}
+
+func newPackageDecls() *packageDecls {
+	return &packageDecls{
+		types:  make(map[string]*declaredType),
+		funcs:  make(map[string][]ast.Expr),
+		values: make(map[string]bool),
+	}
+}
+
+// typeNamed returns the entry of a type, creating it on first use.
+// Methods may be declared in a file that is indexed before the type itself.
+func (d *packageDecls) typeNamed(name string) *declaredType {
+	dt, ok := d.types[name]
+	if !ok {
+		dt = &declaredType{
+			fields:  make(map[string]ast.Expr),
+			methods: make(map[string][]ast.Expr),
+		}
+		d.types[name] = dt
+	}
+	return dt
+}
+
+// addFile indexes the type, function, method, variable and constant declarations of a file
+func (d *packageDecls) addFile(file *ast.File) {
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			results := resultTypes(decl.Type.Results)
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				if receiver := receiverTypeName(decl.Recv.List[0].Type); receiver != "" {
+					d.typeNamed(receiver).methods[decl.Name.Name] = results
+				}
+				continue
+			}
+			d.funcs[decl.Name.Name] = results
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if spec.Assign == token.NoPos {
+						d.addTypeSpec(spec)
+					}
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						if name.Name != "_" {
+							d.values[name.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// addTypeSpec indexes the fields, embedded types and interface methods of a type declaration
+func (d *packageDecls) addTypeSpec(typeSpec *ast.TypeSpec) {
+	dt := d.typeNamed(typeSpec.Name.Name)
+	switch t := typeSpec.Type.(type) {
+	case *ast.StructType:
+		for _, field := range t.Fields.List {
+			if len(field.Names) == 0 {
+				if embedded := receiverTypeName(field.Type); embedded != "" {
+					dt.embeds = append(dt.embeds, embedded)
+					dt.fields[embedded] = field.Type
+				}
+				continue
+			}
+			for _, name := range field.Names {
+				dt.fields[name.Name] = field.Type
+			}
+		}
+	case *ast.InterfaceType:
+		if t.Methods == nil {
+			return
+		}
+		for _, field := range t.Methods.List {
+			funcType, ok := field.Type.(*ast.FuncType)
+			if !ok {
+				if embedded := receiverTypeName(field.Type); embedded != "" {
+					dt.embeds = append(dt.embeds, embedded)
+				}
+				continue
+			}
+			for _, name := range field.Names {
+				dt.methods[name.Name] = resultTypes(funcType.Results)
+			}
+		}
+	}
+}
+
+// resultTypes expands a result list into one type per returned value
+func resultTypes(list *ast.FieldList) []ast.Expr {
+	var results []ast.Expr
+	if list == nil {
+		return results
+	}
+	for _, field := range list.List {
+		for i := 0; i < len(field.Names) || i == 0; i++ {
+			results = append(results, field.Type)
+		}
+	}
+	return results
+}
+
+// lookupMethod finds the type declaring a method of typeName, following embedded types
+// the same way Go promotes methods. Interfaces resolve to their own method declarations.
+func (d *packageDecls) lookupMethod(typeName, method string) (string, []ast.Expr, bool) {
+	var owner string
+	var results []ast.Expr
+	found := d.walkEmbeds(typeName, func(name string, dt *declaredType) bool {
+		if r, ok := dt.methods[method]; ok {
+			owner, results = name, r
+			return true
+		}
+		return false
+	})
+	return owner, results, found
+}
+
+// lookupField returns the type of a field of typeName, including promoted fields
+func (d *packageDecls) lookupField(typeName, field string) (ast.Expr, bool) {
+	var fieldType ast.Expr
+	found := d.walkEmbeds(typeName, func(_ string, dt *declaredType) bool {
+		if t, ok := dt.fields[field]; ok {
+			fieldType = t
+			return true
+		}
+		return false
+	})
+	return fieldType, found
+}
+
+// walkEmbeds visits typeName and then its embedded types breadth-first until visit reports a match
+func (d *packageDecls) walkEmbeds(typeName string, visit func(name string, dt *declaredType) bool) bool {
+	seen := make(map[string]bool)
+	queue := []string{typeName}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		dt, ok := d.types[name]
+		if !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		if visit(name, dt) {
+			return true
+		}
+		queue = append(queue, dt.embeds...)
+	}
+	return false
+}
+
+// indexDeclarations builds the declaration index of every package among files
+func (c *Context) indexDeclarations(files []ParsedFile) {
+	c.declarations = make(map[string]*packageDecls)
+	for _, pf := range files {
+		packagePath := types.PackageID(pf.PackagePath, pf.File.Name.Name)
+		decls, ok := c.declarations[packagePath]
+		if !ok {
+			decls = newPackageDecls()
+			c.declarations[packagePath] = decls
+		}
+		decls.addFile(pf.File)
+	}
+}
+
+// declarationsFor returns the declaration index of the file's package.
+// Without a prepared index (e.g. a strategy run on a single file) only the file itself is indexed.
+func (c *Context) declarationsFor(file *ast.File, packagePath string) *packageDecls {
+	if decls, ok := c.declarations[packagePath]; ok {
+		return decls
+	}
+	decls := newPackageDecls()
+	decls.addFile(file)
+	return decls
+}
+
+// typeRef names a type declared in one of the analyzed packages; the zero value is an unknown type
+type typeRef struct {
+	pkg  string // package identifier of another local package, "" for the current package
+	name string
+}
+
+// known reports whether the type could be inferred
+func (r typeRef) known() bool {
+	return r.name != ""
+}
+
+// methodNodeID returns the node ID of a method of the type
+func (r typeRef) methodNodeID(packagePath, method string) types.NodeID {
+	if r.pkg != "" {
+		packagePath = r.pkg
+	}
+	return types.NewMethodNodeID(packagePath, r.name, method)
+}
+
+// staticTypes tracks the types of the variables visible in a function body.
+// Only types declared in the current package or in another analyzed local package are tracked;
+// anything else is unknown.
+type staticTypes struct {
+	ctx   *Context
+	decls *packageDecls
+	vars  map[string]typeRef // variable name -> inferred type
+}
+
+// newStaticTypes seeds the variables of a function with its receiver, parameters and named results
+func newStaticTypes(ctx *Context, decls *packageDecls, decl *ast.FuncDecl) *staticTypes {
+	s := &staticTypes{ctx: ctx, decls: decls, vars: make(map[string]typeRef)}
+	s.declareFields(decl.Recv)
+	s.declareFields(decl.Type.Params)
+	s.declareFields(decl.Type.Results)
+	return s
+}
+
+// declareFields records the names of a receiver or parameter list with their declared types
+func (s *staticTypes) declareFields(list *ast.FieldList) {
+	if list == nil {
+		return
+	}
+	for _, field := range list.List {
+		for _, name := range field.Names {
+			s.set(name.Name, s.typeRefOf(field.Type, ""))
+		}
+	}
+}
+
+// record updates the variables from a declaration or assignment statement
+func (s *staticTypes) record(n ast.Node) {
+	switch n := n.(type) {
+	case *ast.FuncLit:
+		s.declareFields(n.Type.Params)
+		s.declareFields(n.Type.Results)
+	case *ast.RangeStmt:
+		if n.Tok == token.DEFINE {
+			for _, expr := range []ast.Expr{n.Key, n.Value} {
+				if ident, ok := expr.(*ast.Ident); ok && ident.Name != "_" {
+					s.set(ident.Name, typeRef{})
+				}
+			}
+		}
+	case *ast.AssignStmt:
+		if n.Tok == token.DEFINE || n.Tok == token.ASSIGN {
+			s.assign(n.Lhs, n.Rhs, n.Tok == token.DEFINE)
+		}
+	case *ast.ValueSpec:
+		if n.Type != nil {
+			for _, name := range n.Names {
+				s.set(name.Name, s.typeRefOf(n.Type, ""))
+			}
+			return
+		}
+		lhs := make([]ast.Expr, len(n.Names))
+		for i, name := range n.Names {
+			lhs[i] = name
+		}
+		s.assign(lhs, n.Values, true)
+	}
+}
+
+// assign records the inferred types of assigned variables, including multi-value calls.
+// A plain assignment only updates variables already declared in the function;
+// assigning to a package-level variable never turns it into a local one.
+func (s *staticTypes) assign(lhs, rhs []ast.Expr, define bool) {
+	refs := make([]typeRef, len(lhs))
+	switch {
+	case len(lhs) == len(rhs):
+		for i, value := range rhs {
+			refs[i] = s.typeOf(value)
+		}
+	case len(rhs) == 1:
+		if call, ok := ast.Unparen(rhs[0]).(*ast.CallExpr); ok {
+			pkg, results := s.resultsOf(call)
+			for i, result := range results {
+				if i < len(refs) {
+					refs[i] = s.typeRefOf(result, pkg)
+				}
+			}
+		}
+	}
+	for i, expr := range lhs {
+		ident, ok := expr.(*ast.Ident)
+		if !ok || ident.Name == "_" {
+			continue
+		}
+		if _, declared := s.vars[ident.Name]; define || declared {
+			s.set(ident.Name, refs[i])
+		}
+	}
+}
+
+// set records the type of a variable; an unknown type hides any outer variable of the same name
+func (s *staticTypes) set(name string, ref typeRef) {
+	s.vars[name] = ref
+}
+
+// declsOf returns the declaration index of the package of a type, nil when it is not analyzed
+func (s *staticTypes) declsOf(pkg string) *packageDecls {
+	if pkg == "" {
+		return s.decls
+	}
+	if s.ctx == nil {
+		return nil
+	}
+	return s.ctx.declarations[pkg]
+}
+
+// importedDecls resolves a package qualifier of the current file to another analyzed local package.
+// Variables and package-level values shadowing the import are not qualifiers.
+func (s *staticTypes) importedDecls(qualifier *ast.Ident) (string, *packageDecls, bool) {
+	if s.ctx == nil {
+		return "", nil, false
+	}
+	if _, local := s.vars[qualifier.Name]; local || s.decls.values[qualifier.Name] {
+		return "", nil, false
+	}
+	importPath, ok := s.ctx.ImportMap[qualifier.Name]
+	if !ok {
+		return "", nil, false
+	}
+	targetPkg := crossPackageTarget(s.ctx, importPath, qualifier.Name)
+	if targetPkg == "" {
+		return "", nil, false
+	}
+	decls, ok := s.ctx.declarations[targetPkg]
+	return targetPkg, decls, ok
+}
+
+// typeRefOf returns the type named by a type expression written in package pkg ("" for the current
+// file), looking through pointers and instantiations. Qualified types such as store.Repository are
+// resolved through the imports of the current file. Builtins and type parameters yield an unknown type.
+func (s *staticTypes) typeRefOf(expr ast.Expr, pkg string) typeRef {
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+			continue
+		case *ast.ParenExpr:
+			expr = t.X
+			continue
+		case *ast.IndexExpr:
+			expr = t.X
+			continue
+		case *ast.IndexListExpr:
+			expr = t.X
+			continue
+		}
+		break
+	}
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if decls := s.declsOf(pkg); decls != nil {
+			if _, ok := decls.types[t.Name]; ok {
+				return typeRef{pkg: pkg, name: t.Name}
+			}
+		}
+	case *ast.SelectorExpr:
+		qualifier, ok := t.X.(*ast.Ident)
+		if !ok || pkg != "" {
+			return typeRef{}
+		}
+		if targetPkg, decls, ok := s.importedDecls(qualifier); ok {
+			if _, ok := decls.types[t.Sel.Name]; ok {
+				return typeRef{pkg: targetPkg, name: t.Sel.Name}
+			}
+		}
+	}
+	return typeRef{}
+}
+
+// typeOf infers the type of an expression
+func (s *staticTypes) typeOf(expr ast.Expr) typeRef {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return s.vars[e.Name]
+	case *ast.ParenExpr:
+		return s.typeOf(e.X)
+	case *ast.StarExpr:
+		return s.typeOf(e.X)
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return s.typeOf(e.X)
+		}
+	case *ast.CompositeLit:
+		return s.typeRefOf(e.Type, "")
+	case *ast.SelectorExpr:
+		if receiver := s.typeOf(e.X); receiver.known() {
+			if fieldType, ok := s.declsOf(receiver.pkg).lookupField(receiver.name, e.Sel.Name); ok {
+				return s.typeRefOf(fieldType, receiver.pkg)
+			}
+		}
+	case *ast.CallExpr:
+		if pkg, results := s.resultsOf(e); len(results) > 0 {
+			return s.typeRefOf(results[0], pkg)
+		}
+		callee, _ := splitInstantiation(e.Fun)
+		switch fun := callee.(type) {
+		case *ast.Ident:
+			if _, isVar := s.vars[fun.Name]; isVar {
+				return typeRef{}
+			}
+			if fun.Name == "new" && len(e.Args) == 1 {
+				return s.typeRefOf(e.Args[0], "")
+			}
+			// Conversion such as UserID(x)
+			return s.typeRefOf(fun, "")
+		case *ast.SelectorExpr:
+			// Conversion such as model.UserID(x)
+			return s.typeRefOf(fun, "")
+		}
+	}
+	return typeRef{}
+}
+
+// resultsOf returns the declared result types of a call to a package function, a function of
+// another local package or a resolved method, with the package the result types are written in
+func (s *staticTypes) resultsOf(call *ast.CallExpr) (string, []ast.Expr) {
+	callee, _ := splitInstantiation(call.Fun)
+	switch fun := callee.(type) {
+	case *ast.Ident:
+		if _, isVar := s.vars[fun.Name]; isVar {
+			return "", nil
+		}
+		return "", s.decls.funcs[fun.Name]
+	case *ast.SelectorExpr:
+		if owner, results, ok := s.methodTarget(fun); ok {
+			return owner.pkg, results
+		}
+		if qualifier, ok := fun.X.(*ast.Ident); ok {
+			if targetPkg, decls, ok := s.importedDecls(qualifier); ok {
+				return targetPkg, decls.funcs[fun.Sel.Name]
+			}
+		}
+	}
+	return "", nil
+}
+
+// methodTarget resolves recv.Method to the type declaring the method.
+// Method expressions such as User.Save, (*User).Save or store.Repository.Save are resolved
+// through the named type.
+func (s *staticTypes) methodTarget(sel *ast.SelectorExpr) (typeRef, []ast.Expr, bool) {
+	receiver := s.typeOf(sel.X)
+	if !receiver.known() {
+		expr := ast.Unparen(sel.X)
+		if star, ok := expr.(*ast.StarExpr); ok {
+			expr = ast.Unparen(star.X)
+		}
+		switch x := expr.(type) {
+		case *ast.Ident:
+			if _, isVar := s.vars[x.Name]; !isVar {
+				receiver = s.typeRefOf(x, "")
+			}
+		case *ast.SelectorExpr:
+			receiver = s.typeRefOf(x, "")
+		}
+	}
+	if !receiver.known() {
+		return typeRef{}, nil, false
+	}
+	decls := s.declsOf(receiver.pkg)
+	if decls == nil {
+		return typeRef{}, nil, false
+	}
+	owner, results, ok := decls.lookupMethod(receiver.name, sel.Sel.Name)
+	return typeRef{pkg: receiver.pkg, name: owner}, results, ok
+}
+
+// methodCall is a resolved method call and the position of the call expression
+type methodCall struct {
+	toID    types.NodeID
+	depType types.DependencyType
+	pos     token.Pos
+}
+
+// extractMethodCalls resolves the method calls of a function body through the static type
+// of their receiver expression: receivers, parameters, local variables, fields and call results.
+// Methods of types declared in other local packages are reported as cross-package dependencies.
+func (e *BodyCallDependencyExtractor) extractMethodCalls(decl *ast.FuncDecl, decls *packageDecls, packagePath string) []methodCall {
+	var targets []methodCall
+	scope := newStaticTypes(e.ctx, decls, decl)
+
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		scope.record(n)
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		callee, _ := splitInstantiation(call.Fun)
+		sel, ok := callee.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if owner, _, ok := scope.methodTarget(sel); ok {
+			depType := types.BodyCallDependency
+			if owner.pkg != "" {
+				depType = types.CrossPackageDependency
+			}
+			targets = append(targets, methodCall{toID: owner.methodNodeID(packagePath, sel.Sel.Name), depType: depType, pos: call.Pos()})
+		}
+		return true
+	})
+
+	return targets
+}
+
+// typedMethodCall resolves recv.Method through go/types to the node of the declaring type's method.
+// Interface receivers resolve to the interface method node; methods promoted from embedded
+// fields resolve to the embedded type. Methods of other local packages are reported as
+// cross-package dependencies, and methods of unnamed or non-local types are ignored.
+func (e *BodyCallDependencyExtractor) typedMethodCall(sel *ast.SelectorExpr, pkgScope *gotypes.Scope, packagePath string) (types.NodeID, types.DependencyType, bool) {
+	selection, ok := e.ctx.TypesInfo.Selections[sel]
+	if !ok || selection.Kind() == gotypes.FieldVal {
+		return "", 0, false
+	}
+	fn, ok := selection.Obj().(*gotypes.Func)
+	if !ok {
+		return "", 0, false
+	}
+	recv := fn.Origin().Type().(*gotypes.Signature).Recv()
+	if recv == nil {
+		return "", 0, false
+	}
+	recvType := recv.Type()
+	if ptr, ok := recvType.(*gotypes.Pointer); ok {
+		recvType = ptr.Elem()
+	}
+	named, ok := recvType.(*gotypes.Named)
+	if !ok {
+		return "", 0, false
+	}
+	tn := named.Origin().Obj()
+	if !isPackageLevel(tn) {
+		return "", 0, false
+	}
+	if tn.Parent() == pkgScope {
+		return types.NewMethodNodeID(packagePath, tn.Name(), fn.Name()), types.BodyCallDependency, true
+	}
+	targetPkg := crossPackageTarget(e.ctx, tn.Pkg().Path(), tn.Pkg().Name())
+	if targetPkg == "" {
+		return "", 0, false
+	}
+	return types.NewMethodNodeID(targetPkg, tn.Name(), fn.Name()), types.CrossPackageDependency, true
+}
@@ -0,0 +1,210 @@
+package extraction
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/harakeishi/depsee/internal/types"
+)
+
+const methodCallTestCode = `package test
+
+type User struct {
+	Name string
+}
+
+func (u *User) Validate() error { return nil }
+
+type Repository interface {
+	Save(u *User) error
+	Find(id int) (*User, error)
+}
+
+type Logger interface {
+	Log(msg string)
+}
+
+type Base struct {
+	logger Logger
+}
+
+func (b *Base) Close() {}
+
+type Service struct {
+	Base
+	repo Repository
+}
+
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+func (s *Service) Repo() Repository { return s.repo }
+
+func (s *Service) Register(u *User) error {
+	if err := u.Validate(); err != nil {
+		return err
+	}
+	s.logger.Log("register")
+	s.Close()
+	return s.repo.Save(u)
+}
+
+func Run(repo Repository) {
+	svc := NewService(repo)
+	found, _ := svc.Repo().Find(1)
+	found.Validate()
+	var other User
+	other.Validate()
+	(*User).Validate(&other)
+	svc.Register(&User{})
+}
+`
+
+func TestMethodCallDependencies(t *testing.T) {
+	id := func(name string) types.NodeID { return types.NewNodeID("test", name) }
+	method := func(recv, name string) types.NodeID { return types.NewMethodNodeID("test", recv, name) }
+	expected := []DependencyInfo{
+		{From: method("Service", "Register"), To: method("User", "Validate"), Type: types.BodyCallDependency},
+		{From: method("Service", "Register"), To: method("Logger", "Log"), Type: types.BodyCallDependency},
+		{From: method("Service", "Register"), To: method("Base", "Close"), Type: types.BodyCallDependency},
+		{From: method("Service", "Register"), To: method("Repository", "Save"), Type: types.BodyCallDependency},
+		{From: id("Run"), To: id("NewService"), Type: types.BodyCallDependency},
+		{From: id("Run"), To: method("Service", "Repo"), Type: types.BodyCallDependency},
+		{From: id("Run"), To: method("Repository", "Find"), Type: types.BodyCallDependency},
+		{From: id("Run"), To: method("User", "Validate"), Type: types.BodyCallDependency},
+		{From: id("Run"), To: method("Service", "Register"), Type: types.BodyCallDependency},
+	}
+
+	t.Run("名前ベース", func(t *testing.T) {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "test.go", methodCallTestCode, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("コード解析エラー: %v", err)
+		}
+		deps, err := NewBodyCallDependencyExtractor(NewContext(fset, "test")).ExtractDependencies(file, fset, "test")
+		if err != nil {
+			t.Fatalf("依存関係抽出エラー: %v", err)
+		}
+		assertDependencies(t, deps, expected)
+	})
+
+	t.Run("型チェック", func(t *testing.T) {
+		file, fset, info := parseAndCheck(t, methodCallTestCode)
+		ctx := NewContext(fset, "test")
+		ctx.TypesInfo = info
+		deps, err := NewBodyCallDependencyExtractor(ctx).ExtractDependencies(file, fset, "test")
+		if err != nil {
+			t.Fatalf("依存関係抽出エラー: %v", err)
+		}
+		assertDependencies(t, deps, expected)
+	})
+}
+
+func TestMethodCallDependencies_AcrossFiles(t *testing.T) {
+	sources := map[string]string{
+		"repo.go": `package test
+type Repository struct{}
+func (r *Repository) Save() error { return nil }
+`,
+		"service.go": `package test
+import "fmt"
+type Service struct {
+	repo *Repository
+}
+func (s *Service) Run() {
+	s.repo.Save()
+	fmt.Println("done")
+}
+`,
+	}
+
+	fset := token.NewFileSet()
+	var files []ParsedFile
+	for _, name := range []string{"repo.go", "service.go"} {
+		file, err := parser.ParseFile(fset, name, sources[name], parser.ParseComments)
+		if err != nil {
+			t.Fatalf("コード解析エラー: %v", err)
+		}
+		files = append(files, ParsedFile{Path: name, File: file, FileSet: fset})
+	}
+
+	extractor := NewStrategyBasedExtractor("")
+	extractor.AddStrategy(NewBodyCallDependencyExtractor(extractor.ctx))
+	deps, err := extractor.ExtractFromParsedFiles(files)
+	if err != nil {
+		t.Fatalf("依存関係抽出エラー: %v", err)
+	}
+	assertDependencies(t, deps, []DependencyInfo{
+		{
+			From: types.NewMethodNodeID("test", "Service", "Run"),
+			To:   types.NewMethodNodeID("test", "Repository", "Save"),
+			Type: types.BodyCallDependency,
+		},
+	})
+}
+
+func TestMethodCallDependencies_AcrossPackages(t *testing.T) {
+	sources := []struct {
+		path        string
+		packagePath string
+		code        string
+	}{
+		{
+			path:        "store/store.go",
+			packagePath: "example.com/app/store",
+			code: `package store
+type Base struct{}
+func (b *Base) Close() error { return nil }
+type Repository struct {
+	Base
+}
+func NewRepository() *Repository { return &Repository{} }
+func (r *Repository) Save() error { return nil }
+`,
+		},
+		{
+			path:        "svc/service.go",
+			packagePath: "example.com/app/svc",
+			code: `package svc
+import "example.com/app/store"
+type Service struct {
+	repo *store.Repository
+}
+func (s *Service) Run() {
+	s.repo.Save()
+	r := store.NewRepository()
+	r.Save()
+	r.Close()
+}
+`,
+		},
+	}
+
+	fset := token.NewFileSet()
+	var files []ParsedFile
+	for _, src := range sources {
+		file, err := parser.ParseFile(fset, src.path, src.code, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("コード解析エラー: %v", err)
+		}
+		files = append(files, ParsedFile{Path: src.path, PackagePath: src.packagePath, File: file, FileSet: fset})
+	}
+
+	extractor := NewStrategyBasedExtractor("")
+	extractor.AddStrategy(NewBodyCallDependencyExtractor(extractor.ctx))
+	deps, err := extractor.ExtractFromParsedFiles(files)
+	if err != nil {
+		t.Fatalf("依存関係抽出エラー: %v", err)
+	}
+
+	// フィールドとコンストラクタの戻り値の型から別パッケージのメソッドを解決する
+	run := types.NewMethodNodeID("example.com/app/svc", "Service", "Run")
+	save := types.NewMethodNodeID("example.com/app/store", "Repository", "Save")
+	assertDependencies(t, deps, []DependencyInfo{
+		{From: run, To: save, Type: types.CrossPackageDependency},
+		{From: run, To: save, Type: types.CrossPackageDependency},
+		{From: run, To: types.NewMethodNodeID("example.com/app/store", "Base", "Close"), Type: types.CrossPackageDependency},
+	})
+}
@@ -0,0 +1,110 @@
+package extraction
+
+import (
+	"go/ast"
+	"go/token"
+
+	"github.com/harakeishi/depsee/internal/types"
+)
+
+// NamedTypeDependencyExtractor extracts dependencies from named types other than structs and interfaces.
+// A function type depends on its parameter and result types; defined types, collection types
+// and aliases depend on the types used in their definition.
+type NamedTypeDependencyExtractor struct {
+	ctx *Context
+}
+
+// NewNamedTypeDependencyExtractor creates a new named type dependency extractor
+func NewNamedTypeDependencyExtractor(ctx *Context) *NamedTypeDependencyExtractor {
+	return &NamedTypeDependencyExtractor{ctx: ctx}
+}
+
+// withContext returns a copy of the extractor bound to ctx
+func (e *NamedTypeDependencyExtractor) withContext(ctx *Context) ExtractionStrategy {
+	return NewNamedTypeDependencyExtractor(ctx)
+}
+
+// ExtractDependencies extracts named type dependencies
+func (e *NamedTypeDependencyExtractor) ExtractDependencies(file *ast.File, fset *token.FileSet, packagePath string) ([]DependencyInfo, error) {
+	var dependencies []DependencyInfo
+
+	for _, typeSpec := range namedTypeSpecs(file) {
+		fromID := types.NewNodeID(packagePath, typeSpec.Name.Name)
+		depType := namedTypeDependencyType(typeSpec)
+
+		var targets []string
+		if e.ctx.IsTypeChecked() {
+			targets = e.typedTargets(file, typeSpec)
+		} else {
+			targets = localTypeNamesIn(typeSpec.Type)
+		}
+		typeParams := typeParamNames(typeSpec.TypeParams)
+		for _, target := range targets {
+			if target == typeSpec.Name.Name || typeParams[target] {
+				continue
+			}
+			dependencies = append(dependencies, DependencyInfo{
+				From:     fromID,
+				To:       types.NewNodeID(packagePath, target),
+				Type:     depType,
+				Position: e.ctx.position(typeSpec.Type.Pos()),
+			})
+		}
+	}
+
+	return dependencies, nil
+}
+
+// Name returns the strategy name
+func (e *NamedTypeDependencyExtractor) Name() string {
+	return "NamedTypeDependency"
+}
+
+// typedTargets resolves the types referenced by a type declaration through go/types
+func (e *NamedTypeDependencyExtractor) typedTargets(file *ast.File, typeSpec *ast.TypeSpec) []string {
+	if !isPackageLevel(e.ctx.TypesInfo.Defs[typeSpec.Name]) {
+		return nil
+	}
+	pkgScope := e.ctx.packageScope(file)
+	var targets []string
+	for _, tn := range e.ctx.typeNamesIn(typeSpec.Type) {
+		if tn.Parent() == pkgScope {
+			targets = append(targets, tn.Name())
+		}
+	}
+	return targets
+}
+
+// namedTypeSpecs returns the package-level type declarations that are neither struct nor interface definitions.
+// Aliases are always returned, whatever they refer to.
+func namedTypeSpecs(file *ast.File) []*ast.TypeSpec {
+	var specs []*ast.TypeSpec
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+			if !typeSpec.Assign.IsValid() {
+				switch typeSpec.Type.(type) {
+				case *ast.StructType, *ast.InterfaceType:
+					continue
+				}
+			}
+			specs = append(specs, typeSpec)
+		}
+	}
+	return specs
+}
+
+// namedTypeDependencyType returns SignatureDependency for function types and UnderlyingDependency otherwise
+func namedTypeDependencyType(typeSpec *ast.TypeSpec) types.DependencyType {
+	if _, ok := typeSpec.Type.(*ast.FuncType); ok && !typeSpec.Assign.IsValid() {
+		return types.SignatureDependency
+	}
+	return types.UnderlyingDependency
+}
@@ -0,0 +1,60 @@
+package extraction
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/harakeishi/depsee/internal/types"
+)
+
+const namedTypeTestCode = `package test
+type ID int
+type User struct{}
+type Writer interface{ Write(p []byte) (int, error) }
+type Status int
+type HandlerFunc func(w Writer, u *User) error
+type IDs []ID
+type Index map[ID]*User
+type Buffer [16]byte
+type Events chan User
+type Account = User
+type Admin User
+`
+
+func TestNamedTypeDependencyExtractor_ExtractDependencies(t *testing.T) {
+	expected := []DependencyInfo{
+		{From: types.NewNodeID("test", "HandlerFunc"), To: types.NewNodeID("test", "Writer"), Type: types.SignatureDependency},
+		{From: types.NewNodeID("test", "HandlerFunc"), To: types.NewNodeID("test", "User"), Type: types.SignatureDependency},
+		{From: types.NewNodeID("test", "IDs"), To: types.NewNodeID("test", "ID"), Type: types.UnderlyingDependency},
+		{From: types.NewNodeID("test", "Index"), To: types.NewNodeID("test", "ID"), Type: types.UnderlyingDependency},
+		{From: types.NewNodeID("test", "Index"), To: types.NewNodeID("test", "User"), Type: types.UnderlyingDependency},
+		{From: types.NewNodeID("test", "Events"), To: types.NewNodeID("test", "User"), Type: types.UnderlyingDependency},
+		{From: types.NewNodeID("test", "Account"), To: types.NewNodeID("test", "User"), Type: types.UnderlyingDependency},
+		{From: types.NewNodeID("test", "Admin"), To: types.NewNodeID("test", "User"), Type: types.UnderlyingDependency},
+	}
+
+	t.Run("名前ベース", func(t *testing.T) {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "test.go", namedTypeTestCode, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("コード解析エラー: %v", err)
+		}
+		deps, err := NewNamedTypeDependencyExtractor(NewContext(fset, "test")).ExtractDependencies(file, fset, "test")
+		if err != nil {
+			t.Fatalf("依存関係抽出エラー: %v", err)
+		}
+		assertDependencies(t, deps, expected)
+	})
+
+	t.Run("型チェック", func(t *testing.T) {
+		file, fset, info := parseAndCheck(t, namedTypeTestCode)
+		ctx := NewContext(fset, "test")
+		ctx.TypesInfo = info
+		deps, err := NewNamedTypeDependencyExtractor(ctx).ExtractDependencies(file, fset, "test")
+		if err != nil {
+			t.Fatalf("依存関係抽出エラー: %v", err)
+		}
+		assertDependencies(t, deps, expected)
+	})
+}
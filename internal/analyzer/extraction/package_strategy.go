@@ -2,18 +2,15 @@ package extraction
 
 import (
 	"go/ast"
-	"go/parser"
 	"go/token"
-	"os"
-	"path/filepath"
 	"strings"
 
-	"github.com/harakeishi/depsee/internal/logger"
 	"github.com/harakeishi/depsee/internal/types"
 	"github.com/harakeishi/depsee/internal/utils"
 )
 
-// PackageDependencyExtractor extracts package-level dependencies
+// PackageDependencyExtractor extracts package-level dependencies from import declarations.
+// It is a package-level strategy: the imports of all files of a package are processed at once.
 type PackageDependencyExtractor struct {
 	ctx       *Context
 	targetDir string
@@ -27,31 +24,23 @@ func NewPackageDependencyExtractor(ctx *Context, targetDir string) *PackageDepen
 	}
 }
 
-// ExtractDependencies extracts package-level dependencies
-func (e *PackageDependencyExtractor) ExtractDependencies(file *ast.File, fset *token.FileSet, packageName string) ([]DependencyInfo, error) {
+// withContext returns a copy of the extractor bound to ctx
+func (e *PackageDependencyExtractor) withContext(ctx *Context) ExtractionStrategy {
+	return NewPackageDependencyExtractor(ctx, e.targetDir)
+}
+
+// ExtractDependencies extracts the package dependencies created by the imports of a single file
+func (e *PackageDependencyExtractor) ExtractDependencies(file *ast.File, fset *token.FileSet, packagePath string) ([]DependencyInfo, error) {
+	return e.importDependencies(file, fset, packagePath, e.ctx.Module), nil
+}
+
+// ExtractPackageDependencies extracts the package dependencies created by the imports of every file of pkg
+func (e *PackageDependencyExtractor) ExtractPackageDependencies(pkg *ParsedPackage) ([]DependencyInfo, error) {
 	var dependencies []DependencyInfo
-	
-	// パッケージ間の依存関係を抽出
-	// これは単一ファイルではなく、プロジェクト全体を解析する必要がある
-	packageDeps, err := e.extractPackageDependencies()
-	if err != nil {
-		logger.Error("パッケージ依存関係抽出エラー", "error", err)
-		return dependencies, err
+	module := moduleOf(pkg.Dir, pkg.PackagePath, e.ctx.modules)
+	for _, pf := range pkg.Files {
+		dependencies = append(dependencies, e.importDependencies(pf.File, pf.FileSet, pkg.ID(), module)...)
 	}
-	
-	// 現在のパッケージから他のパッケージへの依存関係を作成
-	for _, dep := range packageDeps {
-		if dep.From == packageName {
-			fromID := types.NewPackageNodeID(dep.From)
-			toID := types.NewPackageNodeID(dep.To)
-			dependencies = append(dependencies, DependencyInfo{
-				From: fromID,
-				To:   toID,
-				Type: types.PackageDependency,
-			})
-		}
-	}
-	
 	return dependencies, nil
 }
 
@@ -60,68 +49,38 @@ func (e *PackageDependencyExtractor) Name() string {
 	return "PackageDependency"
 }
 
-// PackageDep represents a package dependency
-type PackageDep struct {
-	From string
-	To   string
-}
+// importDependencies creates a dependency from the current package to every local package imported by file.
+// Imports are classified against module, the module of the file: packages of the same module and of
+// analyzed modules replaced by local directories are local, the standard library and third-party modules are not.
+// Imports of in-package test files are test-only and are not attributed to the package under test;
+// external test packages (package foo_test) have their own package node.
+func (e *PackageDependencyExtractor) importDependencies(file *ast.File, fset *token.FileSet, packagePath string, module *utils.Module) []DependencyInfo {
+	var dependencies []DependencyInfo
+	if path := fset.Position(file.Package).Filename; IsTestFile(path) && !IsExternalTestFile(path, file) {
+		return dependencies
+	}
+	fromID := types.NewPackageNodeID(packagePath)
 
-// extractPackageDependencies extracts dependencies between packages
-func (e *PackageDependencyExtractor) extractPackageDependencies() ([]PackageDep, error) {
-	var dependencies []PackageDep
-	packageImports := make(map[string][]string)
-	
-	// プロジェクト内のすべてのGoファイルを走査
-	err := filepath.Walk(e.targetDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		
-		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
-			return nil
+	for _, imp := range file.Imports {
+		importPath := strings.Trim(imp.Path.Value, `"`)
+		if !module.Classify(importPath).IsLocal() {
+			continue
 		}
-		
-		// ファイルを解析してインポートを抽出
-		fset := token.NewFileSet()
-		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
-		if err != nil {
-			logger.Debug("ファイル解析エラー", "path", path, "error", err)
-			return nil
+		// ノードIDと同じ規則でパッケージを識別する（go.modがなければパッケージ名）
+		target := importPath
+		if module == nil {
+			target = utils.ExtractPackageName(importPath)
 		}
-		
-		packageName := file.Name.Name
-		var imports []string
-		
-		for _, imp := range file.Imports {
-			importPath := strings.Trim(imp.Path.Value, `"`)
-			if utils.IsLocalPackage(importPath) {
-				// ローカルパッケージの場合、パッケージ名を抽出
-				if pkgName := utils.ExtractPackageName(importPath); pkgName != "" {
-					imports = append(imports, pkgName)
-				}
-			}
+		if target == "" {
+			continue
 		}
-		
-		if len(imports) > 0 {
-			packageImports[packageName] = imports
-		}
-		
-		return nil
-	})
-	
-	if err != nil {
-		return nil, err
+		dependencies = append(dependencies, DependencyInfo{
+			From:     fromID,
+			To:       types.NewPackageNodeID(target),
+			Type:     types.PackageDependency,
+			Position: fset.Position(imp.Pos()),
+		})
 	}
-	
-	// パッケージ間依存関係を構築
-	for fromPkg, imports := range packageImports {
-		for _, toPkg := range imports {
-			dependencies = append(dependencies, PackageDep{
-				From: fromPkg,
-				To:   toPkg,
-			})
-		}
-	}
-	
-	return dependencies, nil
+
+	return dependencies
 }
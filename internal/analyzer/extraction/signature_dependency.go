@@ -17,26 +17,34 @@ func NewSignatureDependencyExtractor(ctx *Context) *SignatureDependencyExtractor
 	return &SignatureDependencyExtractor{ctx: ctx}
 }
 
+// withContext returns a copy of the extractor bound to ctx
+func (e *SignatureDependencyExtractor) withContext(ctx *Context) ExtractionStrategy {
+	return NewSignatureDependencyExtractor(ctx)
+}
+
 // ExtractDependencies extracts signature-based dependencies
-func (e *SignatureDependencyExtractor) ExtractDependencies(file *ast.File, fset *token.FileSet, packageName string) ([]DependencyInfo, error) {
+func (e *SignatureDependencyExtractor) ExtractDependencies(file *ast.File, fset *token.FileSet, packagePath string) ([]DependencyInfo, error) {
+	if e.ctx.IsTypeChecked() {
+		return e.extractTyped(file, packagePath), nil
+	}
+
 	var dependencies []DependencyInfo
 	
 	ast.Inspect(file, func(n ast.Node) bool {
 		switch node := n.(type) {
 		case *ast.FuncDecl:
-			funcName := node.Name.Name
-			fromID := types.NewNodeID(packageName, funcName)
+			fromID := funcNodeID(packagePath, node)
+			typeParams := funcTypeParams(node)
 			
 			// Extract dependencies from parameters
 			if node.Type.Params != nil {
 				for _, field := range node.Type.Params.List {
-					typeStr := extractTypeString(field.Type)
-					if targetType := e.resolveType(typeStr, packageName); targetType != "" {
-						toID := types.NewNodeID(packageName, targetType)
+					for _, toID := range e.resolveTypeRefs(field.Type, packagePath, typeParams) {
 						dependencies = append(dependencies, DependencyInfo{
-							From: fromID,
-							To:   toID,
-							Type: types.SignatureDependency,
+							From:     fromID,
+							To:       toID,
+							Type:     types.SignatureDependency,
+							Position: e.ctx.position(field.Type.Pos()),
 						})
 					}
 				}
@@ -45,13 +53,12 @@ func (e *SignatureDependencyExtractor) ExtractDependencies(file *ast.File, fset
 			// Extract dependencies from return types
 			if node.Type.Results != nil {
 				for _, field := range node.Type.Results.List {
-					typeStr := extractTypeString(field.Type)
-					if targetType := e.resolveType(typeStr, packageName); targetType != "" {
-						toID := types.NewNodeID(packageName, targetType)
+					for _, toID := range e.resolveTypeRefs(field.Type, packagePath, typeParams) {
 						dependencies = append(dependencies, DependencyInfo{
-							From: fromID,
-							To:   toID,
-							Type: types.SignatureDependency,
+							From:     fromID,
+							To:       toID,
+							Type:     types.SignatureDependency,
+							Position: e.ctx.position(field.Type.Pos()),
 						})
 					}
 				}
@@ -60,13 +67,12 @@ func (e *SignatureDependencyExtractor) ExtractDependencies(file *ast.File, fset
 			// Extract dependencies from receiver (methods)
 			if node.Recv != nil {
 				for _, field := range node.Recv.List {
-					typeStr := extractTypeString(field.Type)
-					if targetType := e.resolveType(typeStr, packageName); targetType != "" {
-						toID := types.NewNodeID(packageName, targetType)
+					if receiver := receiverTypeName(field.Type); receiver != "" {
 						dependencies = append(dependencies, DependencyInfo{
-							From: fromID,
-							To:   toID,
-							Type: types.SignatureDependency,
+							From:     fromID,
+							To:       types.NewNodeID(packagePath, receiver),
+							Type:     types.ReceiverDependency,
+							Position: e.ctx.position(field.Type.Pos()),
 						})
 					}
 				}
@@ -74,6 +80,26 @@ func (e *SignatureDependencyExtractor) ExtractDependencies(file *ast.File, fset
 		}
 		return true
 	})
+
+	// Interface methods contribute signature dependencies to their interface
+	for _, typeSpec := range interfaceTypeSpecs(file) {
+		fromID := types.NewNodeID(packagePath, typeSpec.Name.Name)
+		typeParams := typeParamNames(typeSpec.TypeParams)
+		dependencies = append(dependencies, e.interfaceMethodReceivers(typeSpec, packagePath)...)
+		for _, method := range interfaceMethodTypes(typeSpec) {
+			for _, toID := range e.resolveTypeRefs(method, packagePath, typeParams) {
+				if toID == fromID {
+					continue
+				}
+				dependencies = append(dependencies, DependencyInfo{
+					From:     fromID,
+					To:       toID,
+					Type:     types.SignatureDependency,
+					Position: e.ctx.position(method.Pos()),
+				})
+			}
+		}
+	}
 	
 	return dependencies, nil
 }
@@ -83,9 +109,137 @@ func (e *SignatureDependencyExtractor) Name() string {
 	return "SignatureDependency"
 }
 
-// resolveType resolves a type string to a node name
-func (e *SignatureDependencyExtractor) resolveType(typeStr string, currentPkg string) string {
+// extractTyped extracts signature dependencies by resolving every referenced type through go/types
+func (e *SignatureDependencyExtractor) extractTyped(file *ast.File, packagePath string) []DependencyInfo {
+	var dependencies []DependencyInfo
+	pkgScope := e.ctx.packageScope(file)
+
+	for _, decl := range file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		fromID := funcNodeID(packagePath, funcDecl)
+
+		// A method always depends on its receiver type
+		if funcDecl.Recv != nil {
+			for _, field := range funcDecl.Recv.List {
+				if receiver := receiverTypeName(field.Type); receiver != "" {
+					dependencies = append(dependencies, DependencyInfo{
+						From:     fromID,
+						To:       types.NewNodeID(packagePath, receiver),
+						Type:     types.ReceiverDependency,
+						Position: e.ctx.position(field.Type.Pos()),
+					})
+				}
+			}
+		}
+
+		var fields []*ast.Field
+		for _, list := range []*ast.FieldList{funcDecl.Type.Params, funcDecl.Type.Results} {
+			if list != nil {
+				fields = append(fields, list.List...)
+			}
+		}
+		for _, field := range fields {
+			for _, tn := range e.ctx.typeNamesIn(field.Type) {
+				toID, ok := e.ctx.typeNodeID(tn, pkgScope, packagePath)
+				if !ok {
+					continue
+				}
+				dependencies = append(dependencies, DependencyInfo{
+					From:     fromID,
+					To:       toID,
+					Type:     types.SignatureDependency,
+					Position: e.ctx.position(field.Type.Pos()),
+				})
+			}
+		}
+	}
+
+	for _, typeSpec := range interfaceTypeSpecs(file) {
+		fromID := types.NewNodeID(packagePath, typeSpec.Name.Name)
+		dependencies = append(dependencies, e.interfaceMethodReceivers(typeSpec, packagePath)...)
+		for _, method := range interfaceMethodTypes(typeSpec) {
+			for _, tn := range e.ctx.typeNamesIn(method) {
+				toID, ok := e.ctx.typeNodeID(tn, pkgScope, packagePath)
+				if !ok || toID == fromID {
+					continue
+				}
+				dependencies = append(dependencies, DependencyInfo{
+					From:     fromID,
+					To:       toID,
+					Type:     types.SignatureDependency,
+					Position: e.ctx.position(method.Pos()),
+				})
+			}
+		}
+	}
+
+	return dependencies
+}
+
+// interfaceTypeSpecs returns the package-level interface type declarations of a file
+func interfaceTypeSpecs(file *ast.File) []*ast.TypeSpec {
+	var specs []*ast.TypeSpec
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+				if _, ok := typeSpec.Type.(*ast.InterfaceType); ok {
+					specs = append(specs, typeSpec)
+				}
+			}
+		}
+	}
+	return specs
+}
+
+// interfaceMethodTypes returns the function types of the methods declared by an interface
+func interfaceMethodTypes(typeSpec *ast.TypeSpec) []*ast.FuncType {
+	var methods []*ast.FuncType
+	iface := typeSpec.Type.(*ast.InterfaceType)
+	if iface.Methods == nil {
+		return methods
+	}
+	for _, field := range iface.Methods.List {
+		if funcType, ok := field.Type.(*ast.FuncType); ok {
+			methods = append(methods, funcType)
+		}
+	}
+	return methods
+}
+
+// interfaceMethodReceivers links every method node declared by an interface to the interface,
+// the same way methods of concrete types depend on their receiver
+func (e *SignatureDependencyExtractor) interfaceMethodReceivers(typeSpec *ast.TypeSpec, packagePath string) []DependencyInfo {
+	var dependencies []DependencyInfo
+	iface := typeSpec.Type.(*ast.InterfaceType)
+	if iface.Methods == nil {
+		return dependencies
+	}
+	for _, field := range iface.Methods.List {
+		if _, ok := field.Type.(*ast.FuncType); !ok {
+			continue
+		}
+		for _, name := range field.Names {
+			dependencies = append(dependencies, DependencyInfo{
+				From:     types.NewMethodNodeID(packagePath, typeSpec.Name.Name, name.Name),
+				To:       types.NewNodeID(packagePath, typeSpec.Name.Name),
+				Type:     types.ReceiverDependency,
+				Position: e.ctx.position(name.Pos()),
+			})
+		}
+	}
+	return dependencies
+}
+
+// resolveTypeRefs resolves every node referenced by a type expression
+func (e *SignatureDependencyExtractor) resolveTypeRefs(expr ast.Expr, currentPkg string, typeParams map[string]bool) []types.NodeID {
 	// Reuse the same logic from FieldDependencyExtractor
 	fieldExtractor := &FieldDependencyExtractor{ctx: e.ctx}
-	return fieldExtractor.resolveType(typeStr, currentPkg)
+	return fieldExtractor.resolveTypeRefs(expr, currentPkg, typeParams)
 }
\ No newline at end of file
@@ -84,19 +84,19 @@ type Profile struct {
 }`,
 			expected: []DependencyInfo{
 				{
-					From: types.NewNodeID("test", "GetProfile"),
+					From: types.NewMethodNodeID("test", "User", "GetProfile"),
 					To:   types.NewNodeID("test", "User"),
-					Type: types.SignatureDependency,
+					Type: types.ReceiverDependency,
 				},
 				{
-					From: types.NewNodeID("test", "GetProfile"),
+					From: types.NewMethodNodeID("test", "User", "GetProfile"),
 					To:   types.NewNodeID("test", "Profile"),
 					Type: types.SignatureDependency,
 				},
 				{
-					From: types.NewNodeID("test", "GetName"),
+					From: types.NewMethodNodeID("test", "User", "GetName"),
 					To:   types.NewNodeID("test", "User"),
-					Type: types.SignatureDependency,
+					Type: types.ReceiverDependency,
 				},
 			},
 		},
@@ -128,7 +128,7 @@ type Result struct {
 				},
 				{
 					From: types.NewNodeID("test", "ProcessUsers"),
-					To:   types.NewNodeID("test", "*Result"),
+					To:   types.NewNodeID("test", "Result"),
 					Type: types.SignatureDependency,
 				},
 			},
@@ -197,6 +197,11 @@ type Storage interface {
 					To:   types.NewNodeID("test", "Storage"),
 					Type: types.SignatureDependency,
 				},
+				{
+					From: types.NewMethodNodeID("test", "Storage", "Save"),
+					To:   types.NewNodeID("test", "Storage"),
+					Type: types.ReceiverDependency,
+				},
 			},
 		},
 	}
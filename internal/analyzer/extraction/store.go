@@ -0,0 +1,202 @@
+package extraction
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/harakeishi/depsee/internal/types"
+	"github.com/harakeishi/depsee/internal/utils"
+)
+
+// FileStore parses every Go file at most once into a single shared FileSet.
+// The file filter, the analyzer and all extraction strategies consume the same parsed files.
+// It is safe for concurrent use.
+type FileStore struct {
+	fset    *token.FileSet
+	mu      sync.Mutex
+	entries map[string]*storeEntry // parse results by path
+}
+
+// storeEntry is the parse result of a single file, computed once
+type storeEntry struct {
+	once    sync.Once
+	file    *ParsedFile
+	err     error
+	modTime time.Time // modification time of the file when it was read
+	size    int64     // size of the file when it was read
+}
+
+// NewFileStore creates an empty file store
+func NewFileStore() *FileStore {
+	return &FileStore{
+		fset:    token.NewFileSet(),
+		entries: make(map[string]*storeEntry),
+	}
+}
+
+// FileSet returns the FileSet shared by all files of the store
+func (s *FileStore) FileSet() *token.FileSet {
+	return s.fset
+}
+
+// Parse returns the parsed file at path, parsing it on first use.
+// A file that failed to parse returns the same error on every call.
+// Concurrent calls for the same path wait for a single parse.
+func (s *FileStore) Parse(path string) (*ParsedFile, error) {
+	s.mu.Lock()
+	entry, ok := s.entries[path]
+	if !ok {
+		entry = &storeEntry{}
+		s.entries[path] = entry
+	}
+	s.mu.Unlock()
+
+	entry.once.Do(func() {
+		// Stat before reading: a change made in between is detected by the next Refresh
+		if info, err := os.Stat(path); err == nil {
+			entry.modTime, entry.size = info.ModTime(), info.Size()
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			entry.err = err
+			return
+		}
+		file, err := parser.ParseFile(s.fset, path, src, parser.ParseComments)
+		if err != nil {
+			entry.err = err
+			return
+		}
+		sum := sha256.Sum256(src)
+		entry.file = &ParsedFile{
+			Path:        path,
+			PackagePath: packagePathOf(path, file),
+			File:        file,
+			FileSet:     s.fset,
+			Hash:        hex.EncodeToString(sum[:]),
+		}
+	})
+	return entry.file, entry.err
+}
+
+// Refresh drops the parse results of files that were modified or removed since they were read,
+// or whose import path changed (e.g. after editing go.mod), so that the next call to Parse reads them again.
+// It returns the paths of the dropped files.
+// Refresh must not be called concurrently with Parse.
+func (s *FileStore) Refresh() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var dropped []string
+	for path, entry := range s.entries {
+		info, err := os.Stat(path)
+		if err == nil && info.ModTime().Equal(entry.modTime) && info.Size() == entry.size &&
+			(entry.file == nil || entry.file.PackagePath == packagePathOf(path, entry.file.File)) {
+			continue
+		}
+		delete(s.entries, path)
+		dropped = append(dropped, path)
+	}
+	sort.Strings(dropped)
+	return dropped
+}
+
+// ParseAll parses the files at paths on up to workers goroutines.
+// Errors are kept in the store and returned by later calls to Parse.
+func (s *FileStore) ParseAll(paths []string, workers int) {
+	utils.ParallelFor(len(paths), utils.Workers(workers), func(i int) {
+		s.Parse(paths[i])
+	})
+}
+
+// packagePathOf returns the import path of the package of the file at path.
+// Like the go tool, an external test package (package foo_test in a _test.go file) gets the import path
+// of the package under test with a "_test" suffix, so that its nodes never merge with the package under test.
+func packagePathOf(path string, file *ast.File) string {
+	importPath := utils.ImportPathOf(path)
+	if importPath != "" && IsExternalTestFile(path, file) {
+		return importPath + "_test"
+	}
+	return importPath
+}
+
+// IsTestFile reports whether path is a Go test file (*_test.go)
+func IsTestFile(path string) bool {
+	return strings.HasSuffix(path, "_test.go")
+}
+
+// IsExternalTestFile reports whether the file at path belongs to an external test package (package foo_test)
+func IsExternalTestFile(path string, file *ast.File) bool {
+	return IsTestFile(path) && strings.HasSuffix(file.Name.Name, "_test")
+}
+
+// moduleOf returns the module containing dir, used to classify the imports of its files.
+// Only the local modules (replace targets and go.work members) among analyzed stay local;
+// the others have no nodes and their imports are classified as third-party.
+// It returns nil when there is no go.mod or when the module disagrees with the known import path
+// of the package, in which case imports are classified without module information.
+func moduleOf(dir, packagePath string, analyzed map[string]bool) *utils.Module {
+	module := utils.FindModule(dir)
+	if module == nil {
+		return nil
+	}
+	// the import path of an external test package is that of the package under test plus "_test"
+	if importPath, ok := module.ImportPath(dir); packagePath != "" && (!ok || (importPath != packagePath && importPath+"_test" != packagePath)) {
+		return nil
+	}
+	return module.Restrict(analyzed)
+}
+
+// AnalyzedModules returns the directories of the modules containing packages.
+// Imports of local modules outside this set are not local, see utils.Module.Restrict.
+func AnalyzedModules(packages []*ParsedPackage) map[string]bool {
+	modules := make(map[string]bool)
+	for _, pkg := range packages {
+		if module := utils.FindModule(pkg.Dir); module != nil {
+			modules[module.Dir] = true
+		}
+	}
+	return modules
+}
+
+// ParsedPackage is a package made of parsed files sharing a directory and package name
+type ParsedPackage struct {
+	Dir         string
+	Name        string
+	PackagePath string // import path of the package (empty when unknown)
+	Files       []ParsedFile
+}
+
+// ID returns the identifier of the package used in node IDs
+func (p *ParsedPackage) ID() string {
+	return types.PackageID(p.PackagePath, p.Name)
+}
+
+// GroupPackages groups files by directory and package name, keeping the order of first appearance
+func GroupPackages(files []ParsedFile) []*ParsedPackage {
+	type packageKey struct {
+		dir  string
+		name string
+	}
+	var packages []*ParsedPackage
+	index := make(map[packageKey]*ParsedPackage)
+	for _, pf := range files {
+		key := packageKey{dir: filepath.Dir(pf.Path), name: pf.File.Name.Name}
+		pkg, ok := index[key]
+		if !ok {
+			pkg = &ParsedPackage{Dir: key.dir, Name: key.name, PackagePath: pf.PackagePath}
+			index[key] = pkg
+			packages = append(packages, pkg)
+		}
+		pkg.Files = append(pkg.Files, pf)
+	}
+	return packages
+}
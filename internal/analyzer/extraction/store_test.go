@@ -0,0 +1,201 @@
+package extraction
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/harakeishi/depsee/internal/utils"
+)
+
+func TestFileStore_ParseOnce(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := createTestFile(t, tmpDir, "user.go", `package test
+type User struct{}`)
+
+	store := NewFileStore()
+	first, err := store.Parse(path)
+	if err != nil {
+		t.Fatalf("パースエラー: %v", err)
+	}
+	second, err := store.Parse(path)
+	if err != nil {
+		t.Fatalf("パースエラー: %v", err)
+	}
+
+	// 2回目以降はパース済みのASTをそのまま返す
+	if first.File != second.File {
+		t.Error("同じファイルが再パースされました")
+	}
+	if first.FileSet != store.FileSet() {
+		t.Error("ストアのFileSetが共有されていません")
+	}
+}
+
+func TestFileStore_ParseError(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := createTestFile(t, tmpDir, "broken.go", `package test
+func {`)
+
+	store := NewFileStore()
+	if _, err := store.Parse(path); err == nil {
+		t.Fatal("パースエラーが返されませんでした")
+	}
+	// ファイルを修正してもエラーはキャッシュされている
+	if err := os.WriteFile(path, []byte("package test\n"), 0644); err != nil {
+		t.Fatalf("テストファイル作成エラー: %v", err)
+	}
+	if _, err := store.Parse(path); err == nil {
+		t.Error("キャッシュされたパースエラーが返されませんでした")
+	}
+	if _, err := store.Parse(filepath.Join(tmpDir, "missing.go")); err == nil {
+		t.Error("存在しないファイルでエラーが返されませんでした")
+	}
+}
+
+func TestGroupPackages(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewFileStore()
+	var files []ParsedFile
+	for _, src := range []struct{ dir, name, code string }{
+		{"a", "user.go", "package a"},
+		{"b", "post.go", "package b"},
+		{"a", "profile.go", "package a"},
+		{"a", "main.go", "package main"},
+	} {
+		dir := filepath.Join(tmpDir, src.dir)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("ディレクトリ作成エラー: %v", err)
+		}
+		pf, err := store.Parse(createTestFile(t, dir, src.name, src.code))
+		if err != nil {
+			t.Fatalf("パースエラー: %v", err)
+		}
+		files = append(files, *pf)
+	}
+
+	packages := GroupPackages(files)
+
+	// ディレクトリとパッケージ名の組ごとに、最初に現れた順でまとめられる
+	expected := []struct {
+		name  string
+		files int
+	}{{"a", 2}, {"b", 1}, {"main", 1}}
+	if len(packages) != len(expected) {
+		t.Fatalf("パッケージ数が一致しません。期待値: %d, 実際: %d", len(expected), len(packages))
+	}
+	for i, want := range expected {
+		if packages[i].Name != want.name || len(packages[i].Files) != want.files {
+			t.Errorf("パッケージ[%d]が一致しません: %s (%d files)", i, packages[i].Name, len(packages[i].Files))
+		}
+	}
+}
+
+func TestFileStore_ParseAllConcurrent(t *testing.T) {
+	tmpDir := t.TempDir()
+	var paths []string
+	for _, name := range []string{"a.go", "b.go", "c.go", "d.go"} {
+		paths = append(paths, createTestFile(t, tmpDir, name, `package test
+type User struct{}`))
+	}
+	broken := createTestFile(t, tmpDir, "broken.go", `package test
+type {`)
+	paths = append(paths, broken)
+
+	store := NewFileStore()
+	store.ParseAll(paths, 4)
+
+	// 同じファイルを並列に取得しても、パースは1度だけで同じ結果が返される
+	first := make(map[string]*ParsedFile)
+	for _, path := range paths[:4] {
+		pf, err := store.Parse(path)
+		if err != nil {
+			t.Fatalf("パースエラー: %v", err)
+		}
+		first[path] = pf
+	}
+	got := make([]*ParsedFile, 40)
+	utils.ParallelFor(len(got), 8, func(i int) {
+		got[i], _ = store.Parse(paths[i%4])
+	})
+	for i, pf := range got {
+		if pf != first[paths[i%4]] {
+			t.Errorf("%s が再パースされています", paths[i%4])
+		}
+	}
+
+	if _, err := store.Parse(broken); err == nil {
+		t.Error("構文エラーのあるファイルでエラーが返されていません")
+	}
+}
+
+func TestFileStore_Refresh(t *testing.T) {
+	tmpDir := t.TempDir()
+	unchanged := createTestFile(t, tmpDir, "user.go", `package test
+type User struct{}`)
+	modified := createTestFile(t, tmpDir, "broken.go", `package test
+func {`)
+	removed := createTestFile(t, tmpDir, "old.go", `package test`)
+
+	store := NewFileStore()
+	before, _ := store.Parse(unchanged)
+	if _, err := store.Parse(modified); err == nil {
+		t.Fatal("パースエラーが返されませんでした")
+	}
+	store.Parse(removed)
+
+	if err := os.WriteFile(modified, []byte("package test\n\ntype Fixed struct{}\n"), 0644); err != nil {
+		t.Fatalf("テストファイル作成エラー: %v", err)
+	}
+	if err := os.Remove(removed); err != nil {
+		t.Fatal(err)
+	}
+
+	dropped := store.Refresh()
+	if want := []string{modified, removed}; !slices.Equal(dropped, want) {
+		t.Errorf("Refresh() = %v, 期待値: %v", dropped, want)
+	}
+	// 変更されていないファイルは再パースしない
+	if after, _ := store.Parse(unchanged); after != before {
+		t.Error("変更されていないファイルが再パースされています")
+	}
+	// 変更されたファイルは新しい内容で再パースする
+	if pf, err := store.Parse(modified); err != nil || pf.File.Scope.Lookup("Fixed") == nil {
+		t.Errorf("変更されたファイルが再パースされていません: %v", err)
+	}
+	if _, err := store.Parse(removed); err == nil {
+		t.Error("削除されたファイルでエラーが返されませんでした")
+	}
+}
+
+func TestFileStore_TestPackagePath(t *testing.T) {
+	tmpDir := t.TempDir()
+	createTestFile(t, tmpDir, "go.mod", "module example.com/app\n")
+	utils.ClearModuleCache()
+
+	store := NewFileStore()
+	// 外部テストパッケージだけがテスト対象のimportパスに"_test"を付けたパッケージになる
+	tests := map[string]struct{ code, expected string }{
+		"user.go":          {"package app", "example.com/app"},
+		"user_test.go":     {"package app", "example.com/app"},
+		"user_ext_test.go": {"package app_test", "example.com/app_test"},
+	}
+	for name, tt := range tests {
+		pf, err := store.Parse(createTestFile(t, tmpDir, name, tt.code))
+		if err != nil {
+			t.Fatalf("パースエラー: %v", err)
+		}
+		if pf.PackagePath != tt.expected {
+			t.Errorf("%s のPackagePath = %q, expected %q", name, pf.PackagePath, tt.expected)
+		}
+		// 外部テストパッケージでもテスト対象と同じモジュールでimportを分類する
+		if module := moduleOf(tmpDir, pf.PackagePath, nil); module == nil || module.Path != "example.com/app" {
+			t.Errorf("%s のモジュールが見つかりません", name)
+		}
+	}
+	// importパスが変わっていないため再パースされない
+	if dropped := store.Refresh(); len(dropped) != 0 {
+		t.Errorf("変更のないファイル %v が破棄されました", dropped)
+	}
+}
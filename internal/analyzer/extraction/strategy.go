@@ -3,8 +3,10 @@ package extraction
 import (
 	"go/ast"
 	"go/token"
+	gotypes "go/types"
 
 	"github.com/harakeishi/depsee/internal/types"
+	"github.com/harakeishi/depsee/internal/utils"
 )
 
 // DependencyInfo represents a dependency between two nodes
@@ -12,18 +14,52 @@ type DependencyInfo = types.DependencyInfo
 
 // ExtractionStrategy defines the interface for dependency extraction strategies
 type ExtractionStrategy interface {
-	// ExtractDependencies extracts dependencies from the AST
-	ExtractDependencies(file *ast.File, fset *token.FileSet, packageName string) ([]DependencyInfo, error)
+	// ExtractDependencies extracts dependencies from the AST.
+	// packagePath identifies the file's package in node IDs: its import path,
+	// or its package name when the import path is unknown.
+	ExtractDependencies(file *ast.File, fset *token.FileSet, packagePath string) ([]DependencyInfo, error)
 
 	// Name returns the name of the strategy
 	Name() string
 }
 
+// PackageExtractionStrategy is implemented by strategies that work on a whole package.
+// StrategyBasedExtractor runs them once per package instead of once per file.
+type PackageExtractionStrategy interface {
+	ExtractionStrategy
+
+	// ExtractPackageDependencies extracts dependencies from all files of a package
+	ExtractPackageDependencies(pkg *ParsedPackage) ([]DependencyInfo, error)
+}
+
+// PackageCache keeps the dependencies extracted from packages across runs.
+// StrategyBasedExtractor consults it before running the strategies against a package.
+// Implementations must be safe for concurrent use.
+type PackageCache interface {
+	// LoadPackage returns the cached dependencies of pkg, if any
+	LoadPackage(pkg *ParsedPackage) ([]DependencyInfo, bool)
+
+	// StorePackage records the dependencies extracted from pkg
+	StorePackage(pkg *ParsedPackage, deps []DependencyInfo)
+}
+
+// forkableStrategy is implemented by strategies that can be bound to another context.
+// StrategyBasedExtractor forks its strategies to extract packages on several goroutines.
+type forkableStrategy interface {
+	withContext(ctx *Context) ExtractionStrategy
+}
+
 // Context holds shared state for extraction strategies
 type Context struct {
 	FileSet     *token.FileSet
 	PackageName string
+	PackagePath string            // import path of the current file's package (empty when unknown)
 	ImportMap   map[string]string // maps import aliases to package paths
+	TypesInfo   *gotypes.Info     // type-checked information (nil when running in name-based mode)
+	Module      *utils.Module     // module of the current file, used to classify imports (nil when there is no go.mod)
+
+	declarations map[string]*packageDecls // package-level declarations per package ID, for name-based type inference
+	modules      map[string]bool          // directories of the analyzed modules, see AnalyzedModules
 }
 
 // NewContext creates a new extraction context
@@ -34,3 +70,30 @@ func NewContext(fset *token.FileSet, packageName string) *Context {
 		ImportMap:   make(map[string]string),
 	}
 }
+
+// ImportKind classifies importPath relative to the module of the current file.
+// Without a context the import is classified without any module information.
+func (c *Context) ImportKind(importPath string) utils.ImportKind {
+	if c == nil {
+		return (*utils.Module)(nil).Classify(importPath)
+	}
+	return c.Module.Classify(importPath)
+}
+
+// fork returns a copy of the context for use on another goroutine.
+// The declaration index is shared and must not be modified while forks are in use.
+func (c *Context) fork() *Context {
+	forked := *c
+	forked.ImportMap = make(map[string]string)
+	return &forked
+}
+
+// ParsedFile holds an already parsed Go file and its optional type information
+type ParsedFile struct {
+	Path        string
+	PackagePath string // import path of the file's package (empty when unknown)
+	File        *ast.File
+	FileSet     *token.FileSet
+	TypesInfo   *gotypes.Info // nil when the file has not been type-checked
+	Hash        string        // SHA-256 of the file content (empty when unknown)
+}
@@ -2,17 +2,23 @@ package extraction
 
 import (
 	"go/ast"
-	"go/parser"
 	"go/token"
+	"path/filepath"
 
 	"github.com/harakeishi/depsee/internal/logger"
 	"github.com/harakeishi/depsee/internal/types"
+	"github.com/harakeishi/depsee/internal/utils"
 )
 
 // StrategyBasedExtractor adapts the new strategy-based extraction to the old interface
 type StrategyBasedExtractor struct {
 	strategies []ExtractionStrategy
 	targetDir  string
+	ctx        *Context     // shared context, refreshed for every file before strategies run
+	store      *FileStore   // parses files given by path (ExtractFromFiles)
+	workers    int          // number of packages extracted concurrently (0 or less: GOMAXPROCS)
+	cache      PackageCache // dependencies of unchanged packages (nil: always extract)
+	goroutines bool         // attribute long-lived goroutine closures to nodes of their own
 }
 
 // NewStrategyBasedExtractor creates a new strategy-based extractor
@@ -20,72 +26,221 @@ func NewStrategyBasedExtractor(targetDir string) *StrategyBasedExtractor {
 	return &StrategyBasedExtractor{
 		strategies: make([]ExtractionStrategy, 0),
 		targetDir:  targetDir,
+		ctx:        NewContext(token.NewFileSet(), ""),
+		store:      NewFileStore(),
 	}
 }
 
+// Context returns the context shared by the extractor's strategies.
+// Strategies added later with AddStrategy should be created with it.
+func (e *StrategyBasedExtractor) Context() *Context {
+	return e.ctx
+}
+
 // AddStrategy adds an extraction strategy
 func (e *StrategyBasedExtractor) AddStrategy(strategy ExtractionStrategy) {
 	e.strategies = append(e.strategies, strategy)
 }
 
-// ExtractFromFiles extracts dependencies from a list of Go files
+// SetWorkers sets the number of goroutines used to parse files and extract packages.
+// Zero or less uses GOMAXPROCS. Results do not depend on the number of workers.
+func (e *StrategyBasedExtractor) SetWorkers(n int) {
+	e.workers = n
+}
+
+// SetPackageCache sets the cache consulted before extracting each package
+func (e *StrategyBasedExtractor) SetPackageCache(cache PackageCache) {
+	e.cache = cache
+}
+
+// SetGoroutineNodes makes long-lived goroutine entry closures nodes of their own.
+// Dependencies inside such a closure are attributed to it instead of the enclosing declaration,
+// and the spawning function gets a spawn dependency on the closure.
+func (e *StrategyBasedExtractor) SetGoroutineNodes(enabled bool) {
+	e.goroutines = enabled
+}
+
+// StrategyNames returns the names of the strategies in the order they run
+func (e *StrategyBasedExtractor) StrategyNames() []string {
+	names := make([]string, 0, len(e.strategies))
+	for _, strategy := range e.strategies {
+		names = append(names, strategy.Name())
+	}
+	return names
+}
+
+// fork returns a copy of the extractor with its own context and strategies,
+// or nil when a strategy cannot be bound to another context
+func (e *StrategyBasedExtractor) fork() *StrategyBasedExtractor {
+	ctx := e.ctx.fork()
+	strategies := make([]ExtractionStrategy, 0, len(e.strategies))
+	for _, strategy := range e.strategies {
+		forkable, ok := strategy.(forkableStrategy)
+		if !ok {
+			return nil
+		}
+		strategies = append(strategies, forkable.withContext(ctx))
+	}
+	return &StrategyBasedExtractor{
+		strategies: strategies,
+		targetDir:  e.targetDir,
+		ctx:        ctx,
+		store:      e.store,
+		workers:    1,
+		cache:      e.cache,
+		goroutines: e.goroutines,
+	}
+}
+
+// ExtractFromFiles extracts dependencies from a list of Go files.
+// Each file is parsed once through the extractor's file store.
 func (e *StrategyBasedExtractor) ExtractFromFiles(files []string) ([]DependencyInfo, error) {
-	var allDependencies []DependencyInfo
-	
 	logger.Debug("新しいstrategyベース依存関係抽出開始", "files", len(files), "strategies", len(e.strategies))
-	
+
+	e.store.ParseAll(files, e.workers)
+
+	var parsed []ParsedFile
 	for _, filePath := range files {
-		deps, err := e.extractFromFile(filePath)
+		pf, err := e.store.Parse(filePath)
 		if err != nil {
 			logger.Error("ファイル依存関係抽出エラー", "file", filePath, "error", err)
 			continue // エラーがあっても他のファイルは処理を続行
 		}
+		parsed = append(parsed, *pf)
+	}
+
+	return e.ExtractFromParsedFiles(parsed)
+}
+
+// ExtractFromParsedFiles extracts dependencies from files that have already been parsed.
+// Files carrying TypesInfo are handed to the strategies in type-checked mode.
+// File strategies run once per file; package strategies run once per package.
+// Packages are extracted concurrently by forked extractors and merged in package order,
+// so the result is the same as a sequential run.
+func (e *StrategyBasedExtractor) ExtractFromParsedFiles(files []ParsedFile) ([]DependencyInfo, error) {
+	var allDependencies []DependencyInfo
+
+	logger.Debug("解析済みファイルからの依存関係抽出開始", "files", len(files), "strategies", len(e.strategies))
+
+	// Index declarations of all files first so that types declared in sibling files are known
+	e.ctx.indexDeclarations(files)
+
+	packages := GroupPackages(files)
+	e.ctx.modules = AnalyzedModules(packages)
+	results := make([][]DependencyInfo, len(packages))
+	workers := utils.Workers(e.workers)
+	if workers > 1 && len(packages) > 1 && e.fork() != nil {
+		utils.ParallelFor(len(packages), workers, func(i int) {
+			results[i] = e.fork().extractFromPackage(packages[i])
+		})
+	} else {
+		for i, pkg := range packages {
+			results[i] = e.extractFromPackage(pkg)
+		}
+	}
+	for _, deps := range results {
 		allDependencies = append(allDependencies, deps...)
 	}
-	
-	logger.Debug("新しいstrategyベース依存関係抽出完了", "total_dependencies", len(allDependencies))
+
+	logger.Debug("解析済みファイルからの依存関係抽出完了", "total_dependencies", len(allDependencies))
 	return allDependencies, nil
 }
 
-// extractFromFile extracts dependencies from a single file
+// extractFromFile extracts dependencies from a single file, treated as a package of its own
 func (e *StrategyBasedExtractor) extractFromFile(filePath string) ([]DependencyInfo, error) {
-	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
+	pf, err := e.store.Parse(filePath)
 	if err != nil {
 		return nil, err
 	}
-	
-	packageName := file.Name.Name
-	ctx := NewContext(fset, packageName)
-	
-	// Extract imports for the context
-	ctx.ImportMap = e.extractImportMap(file)
-	
+
+	packages := GroupPackages([]ParsedFile{*pf})
+	e.ctx.modules = AnalyzedModules(packages)
+	return e.extractFromPackage(packages[0]), nil
+}
+
+// extractFromPackage runs the file strategies against every file of pkg,
+// then every package strategy once against the whole package.
+// Cached dependencies are returned without running any strategy.
+func (e *StrategyBasedExtractor) extractFromPackage(pkg *ParsedPackage) []DependencyInfo {
+	if e.cache != nil {
+		if deps, ok := e.cache.LoadPackage(pkg); ok {
+			logger.Debug("キャッシュから依存関係を取得", "package", pkg.ID(), "dependencies", len(deps))
+			return deps
+		}
+	}
+
 	var allDependencies []DependencyInfo
-	
+
+	for _, pf := range pkg.Files {
+		allDependencies = append(allDependencies, e.extractFromParsedFile(pf)...)
+	}
+
 	for _, strategy := range e.strategies {
-		deps, err := strategy.ExtractDependencies(file, fset, packageName)
+		packageStrategy, ok := strategy.(PackageExtractionStrategy)
+		if !ok {
+			continue
+		}
+		deps, err := packageStrategy.ExtractPackageDependencies(pkg)
 		if err != nil {
-			logger.Error("戦略依存関係抽出エラー", "strategy", strategy.Name(), "file", filePath, "error", err)
+			logger.Error("戦略依存関係抽出エラー", "strategy", strategy.Name(), "package", pkg.ID(), "error", err)
 			continue
 		}
 		allDependencies = append(allDependencies, deps...)
-		
-		logger.Debug("戦略依存関係抽出", "strategy", strategy.Name(), "file", filePath, "dependencies", len(deps))
+
+		logger.Debug("戦略依存関係抽出", "strategy", strategy.Name(), "package", pkg.ID(), "dependencies", len(deps))
 	}
-	
-	return allDependencies, nil
+
+	if e.cache != nil {
+		e.cache.StorePackage(pkg, allDependencies)
+	}
+	return allDependencies
+}
+
+// extractFromParsedFile runs every file strategy against a single parsed file
+func (e *StrategyBasedExtractor) extractFromParsedFile(pf ParsedFile) []DependencyInfo {
+	packageName := pf.File.Name.Name
+	packagePath := types.PackageID(pf.PackagePath, packageName)
+
+	// Refresh the shared context for this file
+	e.ctx.FileSet = pf.FileSet
+	e.ctx.PackageName = packageName
+	e.ctx.PackagePath = pf.PackagePath
+	e.ctx.ImportMap = e.extractImportMap(pf.File)
+	e.ctx.TypesInfo = pf.TypesInfo
+	e.ctx.Module = moduleOf(filepath.Dir(pf.Path), pf.PackagePath, e.ctx.modules)
+
+	var allDependencies []DependencyInfo
+
+	for _, strategy := range e.strategies {
+		if _, ok := strategy.(PackageExtractionStrategy); ok {
+			continue // run once per package by extractFromPackage
+		}
+		deps, err := strategy.ExtractDependencies(pf.File, pf.FileSet, packagePath)
+		if err != nil {
+			logger.Error("戦略依存関係抽出エラー", "strategy", strategy.Name(), "file", pf.Path, "error", err)
+			continue
+		}
+		allDependencies = append(allDependencies, deps...)
+
+		logger.Debug("戦略依存関係抽出", "strategy", strategy.Name(), "file", pf.Path, "dependencies", len(deps))
+	}
+
+	if e.goroutines {
+		allDependencies = attributeGoroutines(allDependencies, GoroutineClosures(pf.File, packagePath), pf.FileSet)
+	}
+
+	return allDependencies
 }
 
 // extractImportMap extracts import mappings from the AST file
 func (e *StrategyBasedExtractor) extractImportMap(file *ast.File) map[string]string {
 	importMap := make(map[string]string)
-	
+
 	for _, imp := range file.Imports {
 		importPath := imp.Path.Value
 		// Remove quotes
 		importPath = importPath[1 : len(importPath)-1]
-		
+
 		var alias string
 		if imp.Name != nil {
 			alias = imp.Name.Name
@@ -102,40 +257,30 @@ func (e *StrategyBasedExtractor) extractImportMap(file *ast.File) map[string]str
 			}
 			alias = parts
 		}
-		
+
 		importMap[alias] = importPath
 	}
-	
-	return importMap
-}
 
-// createStrategyContext creates a context for a specific strategy
-func (e *StrategyBasedExtractor) createStrategyContext(strategy ExtractionStrategy, baseCtx *Context) *Context {
-	// For strategies that need targetDir, we need to pass it somehow
-	// This is a simple approach - in a more complex system you might use dependency injection
-	switch strategy.(type) {
-	case *PackageDependencyExtractor:
-		// PackageDependencyExtractor already has targetDir set during creation
-		return baseCtx
-	default:
-		return baseCtx
-	}
+	return importMap
 }
 
 // DefaultStrategyBasedExtractor creates a default strategy-based extractor with all strategies
 func DefaultStrategyBasedExtractor(targetDir string) *StrategyBasedExtractor {
 	extractor := NewStrategyBasedExtractor(targetDir)
-	
-	// Create a shared context (this will be overridden per file)
-	ctx := NewContext(token.NewFileSet(), "")
-	
+
+	// Share the extractor's context (refreshed per file)
+	ctx := extractor.ctx
+
 	// Add all strategies
 	extractor.AddStrategy(NewFieldDependencyExtractor(ctx))
 	extractor.AddStrategy(NewSignatureDependencyExtractor(ctx))
+	extractor.AddStrategy(NewNamedTypeDependencyExtractor(ctx))
+	extractor.AddStrategy(NewConstraintDependencyExtractor(ctx))
 	extractor.AddStrategy(NewBodyCallDependencyExtractor(ctx))
+	extractor.AddStrategy(NewBodyTypeUsageDependencyExtractor(ctx))
 	extractor.AddStrategy(NewPackageDependencyExtractor(ctx, targetDir))
 	extractor.AddStrategy(NewCrossPackageDependencyExtractor(ctx))
-	
+
 	return extractor
 }
 
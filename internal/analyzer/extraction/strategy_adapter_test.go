@@ -1,8 +1,11 @@
 package extraction
 
 import (
+	"go/ast"
+	"go/token"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/harakeishi/depsee/internal/types"
@@ -34,7 +37,10 @@ func TestDefaultStrategyBasedExtractor(t *testing.T) {
 	expectedStrategies := []string{
 		"FieldDependency",
 		"SignatureDependency", 
+		"NamedTypeDependency",
+		"ConstraintDependency",
 		"BodyCallDependency",
+		"BodyTypeUsageDependency",
 		"PackageDependency",
 		"CrossPackageDependency",
 	}
@@ -274,3 +280,178 @@ func GetProfile() Profile {
 		t.Logf("依存関係[%d]: %s -> %s (%s)", i, dep.From, dep.To, dep.Type)
 	}
 }
+
+// countingPackageStrategy records how often it is run per file and per package
+type countingPackageStrategy struct {
+	fileRuns    int
+	packageRuns map[string]int
+}
+
+func (s *countingPackageStrategy) ExtractDependencies(file *ast.File, fset *token.FileSet, packagePath string) ([]DependencyInfo, error) {
+	s.fileRuns++
+	return nil, nil
+}
+
+func (s *countingPackageStrategy) ExtractPackageDependencies(pkg *ParsedPackage) ([]DependencyInfo, error) {
+	s.packageRuns[pkg.Name]++
+	return nil, nil
+}
+
+func (s *countingPackageStrategy) Name() string {
+	return "CountingPackage"
+}
+
+func TestStrategyBasedExtractor_PackageStrategyRunsOncePerPackage(t *testing.T) {
+	tmpDir := t.TempDir()
+	subDir := filepath.Join(tmpDir, "sub")
+	if err := os.MkdirAll(subDir, 0755); err != nil {
+		t.Fatalf("ディレクトリ作成エラー: %v", err)
+	}
+	files := []string{
+		createTestFile(t, tmpDir, "a.go", "package test"),
+		createTestFile(t, tmpDir, "b.go", "package test"),
+		createTestFile(t, tmpDir, "c.go", "package test"),
+		createTestFile(t, subDir, "d.go", "package sub"),
+	}
+
+	strategy := &countingPackageStrategy{packageRuns: make(map[string]int)}
+	extractor := NewStrategyBasedExtractor(tmpDir)
+	extractor.AddStrategy(strategy)
+	if _, err := extractor.ExtractFromFiles(files); err != nil {
+		t.Fatalf("抽出エラー: %v", err)
+	}
+
+	if strategy.fileRuns != 0 {
+		t.Errorf("パッケージ戦略がファイル単位で実行されました: %d", strategy.fileRuns)
+	}
+	if strategy.packageRuns["test"] != 1 || strategy.packageRuns["sub"] != 1 {
+		t.Errorf("パッケージ戦略の実行回数が期待値と異なります: %v", strategy.packageRuns)
+	}
+}
+
+func TestPackageDependencyExtractor_ExtractPackageDependencies(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := []string{
+		createTestFile(t, tmpDir, "a.go", `package app
+import (
+	"fmt"
+	"example.com/app/model"
+)`),
+		createTestFile(t, tmpDir, "b.go", `package app
+import "example.com/app/model"`),
+	}
+
+	store := NewFileStore()
+	var parsed []ParsedFile
+	for _, path := range files {
+		pf, err := store.Parse(path)
+		if err != nil {
+			t.Fatalf("パースエラー: %v", err)
+		}
+		parsed = append(parsed, *pf)
+	}
+
+	extractor := NewPackageDependencyExtractor(NewContext(store.FileSet(), "app"), tmpDir)
+	deps, err := extractor.ExtractPackageDependencies(GroupPackages(parsed)[0])
+	if err != nil {
+		t.Fatalf("抽出エラー: %v", err)
+	}
+
+	// importごとに1つの依存関係になり、標準ライブラリは含まれない
+	if len(deps) != 2 {
+		t.Fatalf("依存関係数が一致しません。期待値: 2, 実際: %d", len(deps))
+	}
+	for _, dep := range deps {
+		if dep.Type != types.PackageDependency || !dep.Position.IsValid() {
+			t.Errorf("予期しない依存関係: %+v", dep)
+		}
+	}
+}
+
+func TestStrategyBasedExtractor_ClassifiesImportsByModule(t *testing.T) {
+	root := t.TempDir()
+	appDir := filepath.Join(root, "app")
+	for _, dir := range []string{appDir, filepath.Join(appDir, "model"), filepath.Join(root, "lib", "util")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	createTestFile(t, appDir, "go.mod", `module example.com/app
+
+require (
+	example.com/lib v0.0.0
+	github.com/spf13/cobra v1.8.0
+)
+
+replace example.com/lib => ../lib
+`)
+	path := createTestFile(t, appDir, "main.go", `package main
+
+import (
+	"fmt"
+
+	"example.com/app/model"
+	"example.com/lib/util"
+	"github.com/spf13/cobra"
+)
+
+type Root struct {
+	Cmd    *cobra.Command
+	Helper *util.Helper
+	User   *model.User
+}
+
+func main() {
+	fmt.Println(cobra.ArbitraryArgs, util.Run(), model.New())
+}
+`)
+
+	createTestFile(t, filepath.Join(root, "lib"), "go.mod", "module example.com/lib\n")
+	utilPath := createTestFile(t, filepath.Join(root, "lib", "util"), "util.go", `package util
+
+type Helper struct{}
+
+func Run() int { return 0 }
+`)
+
+	deps, err := DefaultStrategyBasedExtractor(appDir).ExtractFromFiles([]string{path, utilPath})
+	if err != nil {
+		t.Fatalf("依存関係抽出エラー: %v", err)
+	}
+	keys := dependencyKeys(deps)
+
+	// 同じモジュールとreplaceでローカルに置き換えられた解析対象のモジュールは依存関係になる
+	for _, key := range []string{
+		"example.com/app.Root->example.com/app/model.User:field",
+		"example.com/app.Root->example.com/lib/util.Helper:field",
+		"example.com/app.main->example.com/app/model.New:cross_package",
+		"example.com/app.main->example.com/lib/util.Run:cross_package",
+		"package:example.com/app->package:example.com/app/model:package",
+		"package:example.com/app->package:example.com/lib/util:package",
+	} {
+		if keys[key] == 0 {
+			t.Errorf("期待していた依存関係が見つかりません: %s", key)
+		}
+	}
+	// 標準ライブラリとサードパーティモジュールへのノードは作らない
+	for key := range keys {
+		if strings.Contains(key, "cobra") || strings.Contains(key, "fmt") {
+			t.Errorf("リポジトリ外のパッケージへの依存関係が抽出されています: %s", key)
+		}
+	}
+
+	// replaceの置き換え先が解析対象でない場合はノードが存在しないため、サードパーティと同じく依存関係にしない
+	deps, err = DefaultStrategyBasedExtractor(appDir).ExtractFromFiles([]string{path})
+	if err != nil {
+		t.Fatalf("依存関係抽出エラー: %v", err)
+	}
+	keys = dependencyKeys(deps)
+	if keys["example.com/app.Root->example.com/app/model.User:field"] == 0 {
+		t.Errorf("同じモジュールへの依存関係が見つかりません: %v", keys)
+	}
+	for key := range keys {
+		if strings.Contains(key, "example.com/lib") {
+			t.Errorf("解析対象でないモジュールへの依存関係が抽出されています: %s", key)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package extraction
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// typeRefs returns every named type referenced inside a type expression, in source order.
+// Each reference is either "Name" or "pkg.Name". The full type grammar is covered:
+// pointers, slices, fixed-size arrays, maps, channels, function types, variadic parameters,
+// struct and interface literals, parenthesized types and generic instantiations.
+// Field, parameter and method names as well as array lengths are never reported.
+func typeRefs(expr ast.Expr) []string {
+	var refs []string
+	var visit func(expr ast.Expr)
+	visitFields := func(list *ast.FieldList) {
+		if list == nil {
+			return
+		}
+		for _, field := range list.List {
+			visit(field.Type)
+		}
+	}
+	visit = func(expr ast.Expr) {
+		switch t := expr.(type) {
+		case *ast.Ident:
+			refs = append(refs, t.Name)
+		case *ast.SelectorExpr:
+			if x, ok := t.X.(*ast.Ident); ok {
+				refs = append(refs, x.Name+"."+t.Sel.Name)
+			}
+		case *ast.StarExpr:
+			visit(t.X)
+		case *ast.ParenExpr:
+			visit(t.X)
+		case *ast.Ellipsis:
+			visit(t.Elt)
+		case *ast.ArrayType:
+			visit(t.Elt) // array lengths are constants, not types
+		case *ast.MapType:
+			visit(t.Key)
+			visit(t.Value)
+		case *ast.ChanType:
+			visit(t.Value)
+		case *ast.FuncType:
+			visitFields(t.TypeParams)
+			visitFields(t.Params)
+			visitFields(t.Results)
+		case *ast.StructType:
+			visitFields(t.Fields)
+		case *ast.InterfaceType:
+			visitFields(t.Methods)
+		case *ast.IndexExpr, *ast.IndexListExpr:
+			generic, args := splitInstantiation(t)
+			visit(generic)
+			for _, arg := range args {
+				visit(arg)
+			}
+		case *ast.UnaryExpr:
+			visit(t.X) // ~T in constraints
+		case *ast.BinaryExpr:
+			visit(t.X) // A | B in constraints
+			visit(t.Y)
+		}
+	}
+	visit(expr)
+	return refs
+}
+
+// localTypeNamesIn collects the unqualified, non-builtin type names referenced in a type expression
+func localTypeNamesIn(expr ast.Expr) []string {
+	var names []string
+	for _, ref := range typeRefs(expr) {
+		if !strings.Contains(ref, ".") && !isBasicType(ref) {
+			names = append(names, ref)
+		}
+	}
+	return names
+}
@@ -0,0 +1,111 @@
+package extraction
+
+import (
+	"go/parser"
+	"go/token"
+	"slices"
+	"testing"
+
+	"github.com/harakeishi/depsee/internal/types"
+)
+
+func TestTypeRefs(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected []string
+	}{
+		{expr: "map[string][]*pkg.T", expected: []string{"string", "pkg.T"}},
+		{expr: "chan *Event", expected: []string{"Event"}},
+		{expr: "<-chan Event", expected: []string{"Event"}},
+		{expr: "func(r *Request, opts ...Option) error", expected: []string{"Request", "Option", "error"}},
+		{expr: "[Size]Item", expected: []string{"Item"}},
+		{expr: "struct{ Inner *Inner; Tags []Tag }", expected: []string{"Inner", "Tag"}},
+		{expr: "interface{ Do(Task) Result }", expected: []string{"Task", "Result"}},
+		{expr: "(*Wrapper)", expected: []string{"Wrapper"}},
+		{expr: "Pair[Key, []Value]", expected: []string{"Pair", "Key", "Value"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := parser.ParseExpr(tt.expr)
+			if err != nil {
+				t.Fatalf("式の解析エラー: %v", err)
+			}
+			if got := typeRefs(expr); !slices.Equal(got, tt.expected) {
+				t.Errorf("typeRefs(%s) = %v, want %v", tt.expr, got, tt.expected)
+			}
+		})
+	}
+}
+
+const nestedTypeTestCode = `package test
+type Event struct{}
+type Request struct{}
+type Option struct{}
+type Item struct{}
+type Inner struct{}
+type Handler struct {
+	events   chan *Event
+	handle   func(*Request, ...Option) error
+	items    [4]Item
+	index    map[string][]*Item
+	nested   struct{ inner *Inner }
+	wrapped  (*Inner)
+}
+type Processor interface {
+	Process(req *Request, opts ...Option) (<-chan Event, error)
+	Self() Processor
+}
+func Serve(handlers map[string]func(*Request) error, opts ...Option) {}
+`
+
+func TestNestedTypeExpressionDependencies(t *testing.T) {
+	id := func(name string) types.NodeID { return types.NewNodeID("test", name) }
+	expected := []DependencyInfo{
+		{From: id("Handler"), To: id("Event"), Type: types.FieldDependency},
+		{From: id("Handler"), To: id("Request"), Type: types.FieldDependency},
+		{From: id("Handler"), To: id("Option"), Type: types.FieldDependency},
+		{From: id("Handler"), To: id("Item"), Type: types.FieldDependency},
+		{From: id("Handler"), To: id("Inner"), Type: types.FieldDependency},
+		{From: id("Processor"), To: id("Request"), Type: types.SignatureDependency},
+		{From: id("Processor"), To: id("Option"), Type: types.SignatureDependency},
+		{From: id("Processor"), To: id("Event"), Type: types.SignatureDependency},
+		{From: types.NewMethodNodeID("test", "Processor", "Process"), To: id("Processor"), Type: types.ReceiverDependency},
+		{From: types.NewMethodNodeID("test", "Processor", "Self"), To: id("Processor"), Type: types.ReceiverDependency},
+		{From: id("Serve"), To: id("Request"), Type: types.SignatureDependency},
+		{From: id("Serve"), To: id("Option"), Type: types.SignatureDependency},
+	}
+
+	t.Run("名前ベース", func(t *testing.T) {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "test.go", nestedTypeTestCode, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("コード解析エラー: %v", err)
+		}
+		ctx := NewContext(fset, "test")
+		var deps []DependencyInfo
+		for _, strategy := range []ExtractionStrategy{NewFieldDependencyExtractor(ctx), NewSignatureDependencyExtractor(ctx)} {
+			found, err := strategy.ExtractDependencies(file, fset, "test")
+			if err != nil {
+				t.Fatalf("依存関係抽出エラー: %v", err)
+			}
+			deps = append(deps, found...)
+		}
+		assertDependencies(t, deps, expected)
+	})
+
+	t.Run("型チェック", func(t *testing.T) {
+		file, fset, info := parseAndCheck(t, nestedTypeTestCode)
+		ctx := NewContext(fset, "test")
+		ctx.TypesInfo = info
+		var deps []DependencyInfo
+		for _, strategy := range []ExtractionStrategy{NewFieldDependencyExtractor(ctx), NewSignatureDependencyExtractor(ctx)} {
+			found, err := strategy.ExtractDependencies(file, fset, "test")
+			if err != nil {
+				t.Fatalf("依存関係抽出エラー: %v", err)
+			}
+			deps = append(deps, found...)
+		}
+		assertDependencies(t, deps, expected)
+	})
+}
@@ -0,0 +1,73 @@
+package extraction
+
+import (
+	"go/ast"
+	"go/token"
+	gotypes "go/types"
+
+	"github.com/harakeishi/depsee/internal/types"
+)
+
+// IsTypeChecked reports whether strategies should resolve references through go/types
+func (c *Context) IsTypeChecked() bool {
+	return c != nil && c.TypesInfo != nil
+}
+
+// position resolves pos against the current file set.
+// The zero Position is returned when the position is unknown.
+func (c *Context) position(pos token.Pos) token.Position {
+	if c == nil || c.FileSet == nil || !pos.IsValid() {
+		return token.Position{}
+	}
+	return c.FileSet.Position(pos)
+}
+
+// packageScope returns the package scope of the type-checked file
+func (c *Context) packageScope(file *ast.File) *gotypes.Scope {
+	if scope := c.TypesInfo.Scopes[file]; scope != nil {
+		return scope.Parent()
+	}
+	return nil
+}
+
+// typeNamesIn returns every package-level named type referenced inside a type expression.
+// Builtins, type parameters and locally declared types are never returned.
+func (c *Context) typeNamesIn(expr ast.Expr) []*gotypes.TypeName {
+	var names []*gotypes.TypeName
+	if expr == nil {
+		return names
+	}
+	ast.Inspect(expr, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if tn, ok := c.TypesInfo.Uses[ident].(*gotypes.TypeName); ok && isPackageLevel(tn) {
+			names = append(names, tn)
+		}
+		return true
+	})
+	return names
+}
+
+// isPackageLevel reports whether obj is declared at package scope of a real package
+func isPackageLevel(obj gotypes.Object) bool {
+	return obj != nil && obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope()
+}
+
+// typeNodeID returns the node ID of a package-level named type resolved through go/types.
+// Types of the current package use packagePath; types of other packages are resolved with
+// the same filtering rules as cross-package calls, so standard library and external types are rejected.
+func (c *Context) typeNodeID(tn *gotypes.TypeName, pkgScope *gotypes.Scope, packagePath string) (types.NodeID, bool) {
+	if tn.Parent() == pkgScope {
+		return types.NewNodeID(packagePath, tn.Name()), true
+	}
+	if tn.Pkg() == nil {
+		return "", false
+	}
+	targetPkg := crossPackageTarget(c, tn.Pkg().Path(), tn.Pkg().Name())
+	if targetPkg == "" {
+		return "", false
+	}
+	return types.NewNodeID(targetPkg, tn.Name()), true
+}
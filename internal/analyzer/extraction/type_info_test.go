@@ -0,0 +1,186 @@
+package extraction
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	gotypes "go/types"
+	"testing"
+
+	"github.com/harakeishi/depsee/internal/types"
+)
+
+// parseAndCheck はテスト用のコードをパースし、型チェックした結果を返す
+func parseAndCheck(t *testing.T, code string) (*ast.File, *token.FileSet, *gotypes.Info) {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", code, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("コード解析エラー: %v", err)
+	}
+	info := &gotypes.Info{
+		Types:      make(map[ast.Expr]gotypes.TypeAndValue),
+		Defs:       make(map[*ast.Ident]gotypes.Object),
+		Uses:       make(map[*ast.Ident]gotypes.Object),
+		Scopes:     make(map[ast.Node]*gotypes.Scope),
+		Instances:  make(map[*ast.Ident]gotypes.Instance),
+		Selections: make(map[*ast.SelectorExpr]*gotypes.Selection),
+	}
+	conf := gotypes.Config{Importer: importer.Default()}
+	if _, err := conf.Check(file.Name.Name, fset, []*ast.File{file}, info); err != nil {
+		t.Fatalf("型チェックエラー: %v", err)
+	}
+	return file, fset, info
+}
+
+// dependencyKeys は依存関係を比較用の文字列集合に変換する
+func dependencyKeys(deps []DependencyInfo) map[string]int {
+	keys := make(map[string]int)
+	for _, dep := range deps {
+		keys[string(dep.From)+"->"+string(dep.To)+":"+dep.Type.String()]++
+	}
+	return keys
+}
+
+func assertDependencies(t *testing.T, got []DependencyInfo, expected []DependencyInfo) {
+	t.Helper()
+	gotKeys := dependencyKeys(got)
+	expectedKeys := dependencyKeys(expected)
+	for key := range expectedKeys {
+		if gotKeys[key] == 0 {
+			t.Errorf("期待していた依存関係が見つかりません: %s", key)
+		}
+	}
+	for key := range gotKeys {
+		if expectedKeys[key] == 0 {
+			t.Errorf("予期しない依存関係: %s", key)
+		}
+	}
+}
+
+func TestTypeChecked_FieldDependency(t *testing.T) {
+	code := `package test
+type User struct {
+	*Audit
+	Profile  *Profile
+	Posts    []*Post
+	Index    map[string][]*Post
+	Events   chan *Post
+}
+type Audit struct{}
+type Profile struct {
+	Name string
+}
+type Post struct {
+	Title string
+}
+type Named interface {
+	Name() string
+}
+type Entity interface {
+	Named
+	~struct{ Name string } | Post
+}
+func local() {
+	type Inner struct {
+		P Profile
+	}
+}`
+	file, fset, info := parseAndCheck(t, code)
+	ctx := NewContext(fset, "test")
+	ctx.TypesInfo = info
+
+	deps, err := NewFieldDependencyExtractor(ctx).ExtractDependencies(file, fset, "test")
+	if err != nil {
+		t.Fatalf("依存関係抽出エラー: %v", err)
+	}
+
+	assertDependencies(t, deps, []DependencyInfo{
+		{From: types.NewNodeID("test", "User"), To: types.NewNodeID("test", "Profile"), Type: types.FieldDependency},
+		{From: types.NewNodeID("test", "User"), To: types.NewNodeID("test", "Post"), Type: types.FieldDependency},
+		{From: types.NewNodeID("test", "User"), To: types.NewNodeID("test", "Audit"), Type: types.EmbeddingDependency},
+		{From: types.NewNodeID("test", "Entity"), To: types.NewNodeID("test", "Named"), Type: types.EmbeddingDependency},
+	})
+}
+
+func TestTypeChecked_SignatureDependency(t *testing.T) {
+	code := `package test
+type User struct{}
+type error2 = error
+func Find(id int, fallback *User) (*User, error) {
+	type User int
+	return nil, nil
+}`
+	file, fset, info := parseAndCheck(t, code)
+	ctx := NewContext(fset, "test")
+	ctx.TypesInfo = info
+
+	deps, err := NewSignatureDependencyExtractor(ctx).ExtractDependencies(file, fset, "test")
+	if err != nil {
+		t.Fatalf("依存関係抽出エラー: %v", err)
+	}
+
+	assertDependencies(t, deps, []DependencyInfo{
+		{From: types.NewNodeID("test", "Find"), To: types.NewNodeID("test", "User"), Type: types.SignatureDependency},
+	})
+}
+
+func TestTypeChecked_BodyCallDependency(t *testing.T) {
+	code := `package test
+type Config struct{}
+func helper() {}
+func process() {}
+func Run(items []int) {
+	helper()
+	process := func() {}
+	process()
+	_ = len(items)
+	_ = Config(Config{})
+}`
+	file, fset, info := parseAndCheck(t, code)
+	ctx := NewContext(fset, "test")
+	ctx.TypesInfo = info
+
+	deps, err := NewBodyCallDependencyExtractor(ctx).ExtractDependencies(file, fset, "test")
+	if err != nil {
+		t.Fatalf("依存関係抽出エラー: %v", err)
+	}
+
+	// ローカル変数のprocess、組み込み関数len、型変換Configは依存関係にならない
+	assertDependencies(t, deps, []DependencyInfo{
+		{From: types.NewNodeID("test", "Run"), To: types.NewNodeID("test", "helper"), Type: types.BodyCallDependency},
+	})
+}
+
+func TestTypeChecked_CrossPackageDependency(t *testing.T) {
+	code := `package test
+import "strings"
+type replacer struct{}
+func (replacer) Replace(s string) string { return s }
+func Run() {
+	_ = strings.TrimSpace(" a ")
+	{
+		strings := replacer{}
+		_ = strings.Replace("a")
+	}
+}`
+	file, fset, info := parseAndCheck(t, code)
+	ctx := NewContext(fset, "test")
+	ctx.TypesInfo = info
+
+	extractor := NewCrossPackageDependencyExtractor(ctx)
+	imports := map[string]string{"strings": "example.com/strings"}
+	var calls []CrossPackageCall
+	ast.Inspect(file, func(n ast.Node) bool {
+		if fn, ok := n.(*ast.FuncDecl); ok && fn.Name.Name == "Run" {
+			calls = extractor.extractCrossPackageCalls(fn.Body, imports)
+		}
+		return true
+	})
+
+	// strings変数によるシャドウイングは除外される
+	if len(calls) != 1 || calls[0].ToID != types.NewNodeID("strings", "TrimSpace") {
+		t.Errorf("期待値: [strings.TrimSpace], 実際: %v", calls)
+	}
+}
@@ -0,0 +1,276 @@
+package analyzer
+
+import (
+	gotypes "go/types"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/harakeishi/depsee/internal/types"
+)
+
+// qualifierPattern は型文字列中のパッケージ修飾子（例: "model."）にマッチします。
+var qualifierPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*\.`)
+
+// implementationIndex は名前ベースで実装関係を判定するための索引です。
+// 解析結果の型・メソッド・インターフェースをノードIDで引けるようにまとめます。
+type implementationIndex struct {
+	interfaces   map[types.NodeID]*InterfaceInfo
+	methods      map[types.NodeID][]FuncInfo // レシーバ型ノードID -> メソッド一覧
+	embeds       map[types.NodeID][]string   // 構造体ノードID -> 埋め込みフィールドの型名一覧
+	packageIDs   map[string][]string         // 短いパッケージ名 -> パッケージ識別子一覧
+	nodePackages map[types.NodeID][2]string  // ノードID -> {パッケージ識別子, パッケージ名}
+}
+
+// extractImplementations は解析結果から型とインターフェースの実装関係を抽出します。
+// 型のメソッドセット（ポインタレシーバのメソッドと埋め込みフィールドから昇格したメソッドを含む）が
+// インターフェースのメソッドセット（埋め込みインターフェースを展開したもの）を満たす場合に、
+// 型からインターフェースへの依存関係を生成します。メソッドを持たないインターフェースは対象外です。
+func extractImplementations(result *Result) []DependencyInfo {
+	idx := newImplementationIndex(result)
+
+	var ifaceIDs []types.NodeID
+	for id := range idx.interfaces {
+		ifaceIDs = append(ifaceIDs, id)
+	}
+	sortNodeIDs(ifaceIDs)
+
+	candidates := make(map[types.NodeID]struct{})
+	for _, s := range result.Structs {
+		candidates[s.NodeID()] = struct{}{}
+	}
+	for id := range idx.methods {
+		if _, isInterface := idx.interfaces[id]; !isInterface {
+			candidates[id] = struct{}{}
+		}
+	}
+	var typeIDs []types.NodeID
+	for id := range candidates {
+		typeIDs = append(typeIDs, id)
+	}
+	sortNodeIDs(typeIDs)
+
+	var dependencies []DependencyInfo
+	for _, ifaceID := range ifaceIDs {
+		required, ok := idx.interfaceMethodSet(ifaceID, map[types.NodeID]bool{})
+		if !ok || len(required) == 0 {
+			continue
+		}
+		for _, typeID := range typeIDs {
+			provided := idx.typeMethodSet(typeID, map[types.NodeID]bool{})
+			if satisfies(provided, required) {
+				dependencies = append(dependencies, DependencyInfo{
+					From: typeID,
+					To:   ifaceID,
+					Type: types.ImplementationDependency,
+				})
+			}
+		}
+	}
+	return dependencies
+}
+
+// newImplementationIndex は解析結果から実装関係判定用の索引を構築します。
+func newImplementationIndex(result *Result) *implementationIndex {
+	idx := &implementationIndex{
+		interfaces:   make(map[types.NodeID]*InterfaceInfo),
+		methods:      make(map[types.NodeID][]FuncInfo),
+		embeds:       make(map[types.NodeID][]string),
+		packageIDs:   make(map[string][]string),
+		nodePackages: make(map[types.NodeID][2]string),
+	}
+	seenPackages := make(map[string]bool)
+	for _, pkg := range result.Packages {
+		pkgID := types.PackageID(pkg.Path, pkg.Name)
+		if !seenPackages[pkgID] {
+			seenPackages[pkgID] = true
+			idx.packageIDs[pkg.Name] = append(idx.packageIDs[pkg.Name], pkgID)
+		}
+	}
+	for i := range result.Interfaces {
+		iface := &result.Interfaces[i]
+		id := iface.NodeID()
+		idx.interfaces[id] = iface
+		idx.nodePackages[id] = [2]string{types.PackageID(iface.PackagePath, iface.Package), iface.Package}
+	}
+	for _, s := range result.Structs {
+		id := s.NodeID()
+		idx.nodePackages[id] = [2]string{types.PackageID(s.PackagePath, s.Package), s.Package}
+		for _, field := range s.Fields {
+			if field.Name == "" {
+				idx.embeds[id] = append(idx.embeds[id], field.Type)
+			}
+		}
+	}
+	for _, m := range result.Methods {
+		id := m.ReceiverNodeID()
+		idx.methods[id] = append(idx.methods[id], m)
+		idx.nodePackages[id] = [2]string{types.PackageID(m.PackagePath, m.Package), m.Package}
+	}
+	return idx
+}
+
+// resolveTypeRef は型文字列を、その型が参照された場所のパッケージを基準にノードIDへ解決します。
+// 修飾子付きの型は短いパッケージ名が一意に定まる場合のみ解決します。
+func (idx *implementationIndex) resolveTypeRef(from types.NodeID, typeStr string) (types.NodeID, bool) {
+	typeStr = strings.TrimPrefix(typeStr, "*")
+	pkg := idx.nodePackages[from]
+	if dot := strings.LastIndex(typeStr, "."); dot != -1 {
+		candidates := idx.packageIDs[typeStr[:dot]]
+		if len(candidates) != 1 {
+			return "", false
+		}
+		return types.NewNodeID(candidates[0], typeStr[dot+1:]), true
+	}
+	return types.NewNodeID(pkg[0], typeStr), true
+}
+
+// interfaceMethodSet はインターフェースのメソッドセットを埋め込みインターフェースも含めて返します。
+// 解析対象外のインターフェースを埋め込んでいてメソッドセットが確定しない場合はfalseを返します。
+func (idx *implementationIndex) interfaceMethodSet(id types.NodeID, visiting map[types.NodeID]bool) (map[string]string, bool) {
+	iface, ok := idx.interfaces[id]
+	if !ok || visiting[id] {
+		return nil, false
+	}
+	visiting[id] = true
+	defer delete(visiting, id)
+
+	set := make(map[string]string)
+	for _, m := range iface.Methods {
+		set[m.Name] = signatureKey(m)
+	}
+	for _, embed := range iface.Embeds {
+		switch embed {
+		case "error":
+			set["Error"] = "()(string)"
+			continue
+		case "any", "comparable":
+			continue
+		}
+		embedID, ok := idx.resolveTypeRef(id, embed)
+		if !ok {
+			return nil, false
+		}
+		embedded, ok := idx.interfaceMethodSet(embedID, visiting)
+		if !ok {
+			return nil, false
+		}
+		for name, sig := range embedded {
+			set[name] = sig
+		}
+	}
+	return set, true
+}
+
+// typeMethodSet はポインタ型*Tのメソッドセットを返します。
+// 値レシーバとポインタレシーバの両方のメソッドに加え、埋め込みフィールドから昇格したメソッドを含みます。
+// 型自身が宣言したメソッドは昇格したメソッドより優先されます。
+func (idx *implementationIndex) typeMethodSet(id types.NodeID, visiting map[types.NodeID]bool) map[string]string {
+	set := make(map[string]string)
+	if visiting[id] {
+		return set
+	}
+	visiting[id] = true
+	defer delete(visiting, id)
+
+	for _, embed := range idx.embeds[id] {
+		embedID, ok := idx.resolveTypeRef(id, embed)
+		if !ok {
+			continue
+		}
+		promoted := idx.typeMethodSet(embedID, visiting)
+		if _, isInterface := idx.interfaces[embedID]; isInterface {
+			promoted, _ = idx.interfaceMethodSet(embedID, map[types.NodeID]bool{})
+		}
+		for name, sig := range promoted {
+			set[name] = sig
+		}
+	}
+	for _, m := range idx.methods[id] {
+		set[m.Name] = signatureKey(m)
+	}
+	return set
+}
+
+// satisfies は提供されるメソッドセットが要求されるメソッドセットを全て満たすかを判定します。
+func satisfies(provided, required map[string]string) bool {
+	for name, sig := range required {
+		if provided[name] != sig {
+			return false
+		}
+	}
+	return true
+}
+
+// signatureKey はメソッドシグネチャを比較用の文字列に変換します。
+// 引数名は無視し、パッケージ修飾子を取り除いた型名で比較します。
+func signatureKey(f FuncInfo) string {
+	var params, results []string
+	for _, p := range f.Params {
+		params = append(params, qualifierPattern.ReplaceAllString(p.Type, ""))
+	}
+	for _, r := range f.Results {
+		results = append(results, qualifierPattern.ReplaceAllString(r.Type, ""))
+	}
+	return "(" + strings.Join(params, ",") + ")(" + strings.Join(results, ",") + ")"
+}
+
+// extractTypedImplementations は型チェック済みパッケージからgo/typesを用いて実装関係を抽出します。
+// 型Tまたは*Tがインターフェースを実装する場合に依存関係を生成します。
+// ジェネリック型と型パラメータを持つインターフェースは対象外です。
+func extractTypedImplementations(packages map[string]*gotypes.Package) []DependencyInfo {
+	type namedType struct {
+		id    types.NodeID
+		typ   gotypes.Type
+		iface *gotypes.Interface
+	}
+	var ifaces, concretes []namedType
+
+	var paths []string
+	for path := range packages {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		scope := packages[path].Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*gotypes.TypeName)
+			if !ok || tn.IsAlias() {
+				continue
+			}
+			named, ok := tn.Type().(*gotypes.Named)
+			if !ok || named.TypeParams().Len() > 0 {
+				continue
+			}
+			nt := namedType{id: types.NewNodeID(path, name), typ: named}
+			if iface, ok := named.Underlying().(*gotypes.Interface); ok {
+				if iface.NumMethods() > 0 && iface.IsMethodSet() {
+					nt.iface = iface
+					ifaces = append(ifaces, nt)
+				}
+				continue
+			}
+			concretes = append(concretes, nt)
+		}
+	}
+
+	var dependencies []DependencyInfo
+	for _, iface := range ifaces {
+		for _, c := range concretes {
+			if gotypes.Implements(c.typ, iface.iface) || gotypes.Implements(gotypes.NewPointer(c.typ), iface.iface) {
+				dependencies = append(dependencies, DependencyInfo{
+					From: c.id,
+					To:   iface.id,
+					Type: types.ImplementationDependency,
+				})
+			}
+		}
+	}
+	return dependencies
+}
+
+// sortNodeIDs はノードIDを辞書順に並べ替えます。
+func sortNodeIDs(ids []types.NodeID) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+}
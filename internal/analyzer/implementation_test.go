@@ -0,0 +1,151 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/harakeishi/depsee/internal/types"
+)
+
+const implementationTestCode = `package test
+
+type Reader interface {
+	Read(p []byte) (int, error)
+}
+
+type Closer interface {
+	Close() error
+}
+
+type ReadCloser interface {
+	Reader
+	Closer
+}
+
+type Stringer interface {
+	String() string
+}
+
+type Failure interface {
+	error
+}
+
+type Empty interface{}
+
+type File struct{}
+
+func (f *File) Read(buf []byte) (int, error) { return 0, nil }
+func (f File) Close() error                  { return nil }
+
+type Wrapped struct {
+	File
+}
+
+type Name string
+
+func (n Name) String() string { return string(n) }
+
+func (n Name) Error() string { return string(n) }
+
+type Broken struct{}
+
+func (b Broken) Read(p []byte) error { return nil }
+`
+
+// analyzeImplementations はテスト用のコードを解析し、実装関係の依存関係のみを返す
+func analyzeImplementations(t *testing.T, typeCheck bool) map[string]bool {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "test.go"), []byte(implementationTestCode), 0644); err != nil {
+		t.Fatalf("テストファイル作成エラー: %v", err)
+	}
+
+	ga := &GoAnalyzer{}
+	ga.SetOptions(Options{TypeCheck: typeCheck})
+	if err := ga.ListTartgetFiles(dir); err != nil {
+		t.Fatalf("ファイル一覧取得エラー: %v", err)
+	}
+	if err := ga.Analyze(); err != nil {
+		t.Fatalf("解析エラー: %v", err)
+	}
+
+	got := make(map[string]bool)
+	for _, dep := range ga.Result.Dependencies {
+		if dep.Type == types.ImplementationDependency {
+			got[string(dep.From)+"->"+string(dep.To)] = true
+		}
+	}
+	return got
+}
+
+func TestExtractImplementations(t *testing.T) {
+	expected := []string{
+		"test.File->test.Reader",
+		"test.File->test.Closer",
+		"test.File->test.ReadCloser",
+		"test.Wrapped->test.Reader",
+		"test.Wrapped->test.Closer",
+		"test.Wrapped->test.ReadCloser",
+		"test.Name->test.Stringer",
+		"test.Name->test.Failure",
+	}
+
+	for _, mode := range []struct {
+		name      string
+		typeCheck bool
+	}{
+		{name: "名前ベース", typeCheck: false},
+		{name: "型チェック", typeCheck: true},
+	} {
+		t.Run(mode.name, func(t *testing.T) {
+			got := analyzeImplementations(t, mode.typeCheck)
+			for _, key := range expected {
+				if !got[key] {
+					t.Errorf("期待していた実装関係が見つかりません: %s", key)
+				}
+			}
+			if len(got) != len(expected) {
+				t.Errorf("実装関係の数が一致しません: 期待値 %d, 実際 %d (%v)", len(expected), len(got), got)
+			}
+		})
+	}
+}
+
+func TestExtractImplementations_AcrossPackages(t *testing.T) {
+	// 実装する型とインターフェースが別パッケージにある場合（パッケージの並び順に関係なく検出する）
+	dir := t.TempDir()
+	writeModule(t, dir, map[string]string{
+		"go.mod":          "module example.com/app\n",
+		"adapter/db.go":   "package adapter\n\nimport \"example.com/app/model\"\n\ntype DB struct{}\n\nfunc (d *DB) Save(u *model.User) error { return nil }\n",
+		"model/model.go":  "package model\n\ntype User struct{}\n\ntype Saver interface {\n\tSave(u *User) error\n}\n",
+		"store/store.go":  "package store\n\ntype Repository struct{}\n\nfunc (r *Repository) Load() error { return nil }\n",
+		"svc/service.go":  "package svc\n\ntype Loader interface {\n\tLoad() error\n}\n",
+		"svc/consumer.go": "package svc\n\nimport \"example.com/app/store\"\n\nvar _ = store.Repository{}\n",
+	})
+
+	for _, typeCheck := range []bool{false, true} {
+		ga := &GoAnalyzer{}
+		ga.SetOptions(Options{TypeCheck: typeCheck})
+		if err := ga.ListTartgetFiles(dir); err != nil {
+			t.Fatalf("ファイル一覧取得エラー: %v", err)
+		}
+		if err := ga.Analyze(); err != nil {
+			t.Fatalf("解析エラー: %v", err)
+		}
+		got := make(map[string]bool)
+		for _, dep := range ga.Result.Dependencies {
+			if dep.Type == types.ImplementationDependency {
+				got[string(dep.From)+"->"+string(dep.To)] = true
+			}
+		}
+		for _, key := range []string{
+			"example.com/app/adapter.DB->example.com/app/model.Saver",
+			"example.com/app/store.Repository->example.com/app/svc.Loader",
+		} {
+			if !got[key] {
+				t.Errorf("TypeCheck=%v: 期待していた実装関係が見つかりません: %s (%v)", typeCheck, key, got)
+			}
+		}
+	}
+}
@@ -8,6 +8,10 @@ type Analyzer interface {
 	// 対象パッケージの指定や除外パッケージ・ディレクトリの設定が可能です。
 	SetFilters(filters Filters)
 
+	// SetOptions は解析方法の設定を行います。
+	// 型チェックモードなど、依存関係の解決方法を切り替えることができます。
+	SetOptions(options Options)
+
 	// ListTartgetFiles は指定されたディレクトリから解析対象のGoファイルをリストアップします。
 	// 設定されたフィルタに基づいて対象ファイルを決定します。
 	ListTartgetFiles(dir string) error
@@ -0,0 +1,95 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/build"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Platform は解析対象とするプラットフォーム（GOOSとGOARCHの組）を表します。
+type Platform struct {
+	GOOS   string
+	GOARCH string
+}
+
+// String はプラットフォームを "linux/amd64" の形式で返します。
+func (p Platform) String() string {
+	return p.GOOS + "/" + p.GOARCH
+}
+
+// ParsePlatforms はカンマ区切りの "GOOS/GOARCH" の一覧（例: "linux/amd64,windows/amd64"）をパースします。
+func ParsePlatforms(spec string) ([]Platform, error) {
+	var platforms []Platform
+	seen := make(map[Platform]bool)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		goos, goarch, ok := strings.Cut(item, "/")
+		if !ok || goos == "" || goarch == "" || strings.Contains(goarch, "/") {
+			return nil, fmt.Errorf("プラットフォームの指定が不正です（GOOS/GOARCH形式で指定してください）: %s", item)
+		}
+		p := Platform{GOOS: goos, GOARCH: goarch}
+		if !seen[p] {
+			seen[p] = true
+			platforms = append(platforms, p)
+		}
+	}
+	return platforms, nil
+}
+
+// buildContext はビルド制約の評価に使うgo/buildのコンテキストです。
+type buildContext struct {
+	platform Platform
+	ctxt     build.Context
+}
+
+// buildContexts は解析設定からビルド制約を評価するコンテキストの一覧を作成します。
+// Platformsが指定されている場合はプラットフォームごとに、そうでない場合はGOOS・GOARCH（空の場合は環境変数またはホストの値）の1つを作成します。
+func (o Options) buildContexts() []buildContext {
+	platforms := o.Platforms
+	if len(platforms) == 0 {
+		p := Platform{GOOS: o.GOOS, GOARCH: o.GOARCH}
+		if p.GOOS == "" {
+			p.GOOS = build.Default.GOOS
+		}
+		if p.GOARCH == "" {
+			p.GOARCH = build.Default.GOARCH
+		}
+		platforms = []Platform{p}
+	}
+
+	contexts := make([]buildContext, 0, len(platforms))
+	for _, p := range platforms {
+		ctxt := build.Default
+		ctxt.GOOS = p.GOOS
+		ctxt.GOARCH = p.GOARCH
+		ctxt.BuildTags = o.BuildTags
+		// goコマンドと同様に、クロスコンパイルではCGO_ENABLEDが指定されない限りcgoを無効とする
+		if os.Getenv("CGO_ENABLED") == "" && (p.GOOS != build.Default.GOOS || p.GOARCH != build.Default.GOARCH) {
+			ctxt.CgoEnabled = false
+		}
+		contexts = append(contexts, buildContext{platform: p, ctxt: ctxt})
+	}
+	return contexts
+}
+
+// matchPlatforms はファイルのビルド制約（//go:build 行と _linux・_amd64 等のファイル名の接尾辞）を各コンテキストで評価し、
+// ファイルがビルド対象となるプラットフォームの一覧を返します。
+func matchPlatforms(path string, contexts []buildContext) ([]string, error) {
+	dir, name := filepath.Split(path)
+	var matched []string
+	for _, c := range contexts {
+		ok, err := c.ctxt.MatchFile(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, c.platform.String())
+		}
+	}
+	return matched, nil
+}
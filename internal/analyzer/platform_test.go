@@ -0,0 +1,133 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParsePlatforms(t *testing.T) {
+	platforms, err := ParsePlatforms(" linux/amd64, windows/amd64,linux/amd64,,darwin/arm64")
+	if err != nil {
+		t.Fatalf("パースエラー: %v", err)
+	}
+	expected := []Platform{{"linux", "amd64"}, {"windows", "amd64"}, {"darwin", "arm64"}}
+	if !reflect.DeepEqual(platforms, expected) {
+		t.Errorf("ParsePlatforms() = %v, expected %v", platforms, expected)
+	}
+
+	for _, spec := range []string{"linux", "linux/", "/amd64", "linux/amd64/v3"} {
+		if _, err := ParsePlatforms(spec); err == nil {
+			t.Errorf("ParsePlatforms(%q) はエラーになるべきです", spec)
+		}
+	}
+}
+
+func TestListTargetFiles_BuildConstraints(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, map[string]string{
+		"go.mod":          "module example.com/app\n",
+		"file.go":         "package app\n\ntype File struct{}\n",
+		"file_linux.go":   "package app\n\nfunc open() *File { return nil }\n",
+		"file_windows.go": "package app\n\nfunc open() *File { return nil }\n",
+		"poll_unix.go":    "//go:build unix\n\npackage app\n\nfunc poll() {}\n",
+		"debug.go":        "//go:build debug\n\npackage app\n\nfunc dump() {}\n",
+		"gen.go":          "//go:build ignore\n\npackage main\n",
+	})
+	list := func(options Options) []string {
+		t.Helper()
+		ga := &GoAnalyzer{Options: options}
+		if err := ga.ListTartgetFiles(dir); err != nil {
+			t.Fatalf("ファイル一覧取得エラー: %v", err)
+		}
+		var names []string
+		for _, path := range ga.filesPath {
+			names = append(names, filepath.Base(path))
+		}
+		return names
+	}
+
+	tests := []struct {
+		name     string
+		options  Options
+		expected []string
+	}{
+		{"linux", Options{GOOS: "linux", GOARCH: "amd64"}, []string{"file.go", "file_linux.go", "poll_unix.go"}},
+		{"windows", Options{GOOS: "windows", GOARCH: "amd64"}, []string{"file.go", "file_windows.go"}},
+		{"ビルドタグ指定", Options{GOOS: "windows", GOARCH: "amd64", BuildTags: []string{"debug"}}, []string{"debug.go", "file.go", "file_windows.go"}},
+		{"複数プラットフォーム", Options{Platforms: []Platform{{"linux", "amd64"}, {"windows", "amd64"}}}, []string{"file.go", "file_linux.go", "file_windows.go", "poll_unix.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if names := list(tt.options); !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("解析対象ファイル = %v, expected %v", names, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAnalyze_Platforms(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, map[string]string{
+		"go.mod":          "module example.com/app\n",
+		"file.go":         "package app\n\ntype File struct{}\n",
+		"file_linux.go":   "package app\n\nfunc open() *File { return nil }\n",
+		"file_windows.go": "package app\n\nfunc open() *File { return nil }\n",
+		"poll_unix.go":    "//go:build unix\n\npackage app\n\nfunc poll() {}\n",
+	})
+
+	ga := &GoAnalyzer{Options: Options{Platforms: []Platform{{"linux", "amd64"}, {"darwin", "arm64"}, {"windows", "amd64"}}}}
+	if err := ga.ListTartgetFiles(dir); err != nil {
+		t.Fatalf("ファイル一覧取得エラー: %v", err)
+	}
+	if err := ga.Analyze(); err != nil {
+		t.Fatalf("解析エラー: %v", err)
+	}
+
+	if expected := []string{"linux/amd64", "darwin/arm64", "windows/amd64"}; !reflect.DeepEqual(ga.Result.Platforms, expected) {
+		t.Errorf("Platforms = %v, expected %v", ga.Result.Platforms, expected)
+	}
+	// 全てのプラットフォームでビルドされるファイルは記録しない
+	expected := map[string][]string{
+		filepath.Join(dir, "file_linux.go"):   {"linux/amd64"},
+		filepath.Join(dir, "file_windows.go"): {"windows/amd64"},
+		filepath.Join(dir, "poll_unix.go"):    {"linux/amd64", "darwin/arm64"},
+	}
+	if !reflect.DeepEqual(ga.Result.FilePlatforms, expected) {
+		t.Errorf("FilePlatforms = %v, expected %v", ga.Result.FilePlatforms, expected)
+	}
+}
+
+func TestAnalyze_PlatformsTypeCheck(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, map[string]string{
+		"go.mod":          "module example.com/app\n",
+		"file.go":         "package app\n\ntype File struct{}\n",
+		"file_linux.go":   "package app\n\nfunc open() *File { return nil }\n",
+		"file_windows.go": "package app\n\nfunc open() *File { return nil }\n",
+	})
+
+	ga := &GoAnalyzer{Options: Options{TypeCheck: true, Platforms: []Platform{{"linux", "amd64"}, {"windows", "amd64"}}}}
+	if err := ga.ListTartgetFiles(dir); err != nil {
+		t.Fatalf("ファイル一覧取得エラー: %v", err)
+	}
+	if err := ga.Analyze(); err != nil {
+		t.Fatalf("解析エラー: %v", err)
+	}
+
+	// プラットフォームごとに型チェックし、それぞれのプラットフォームのファイルの宣言が使われる
+	if len(ga.resolvers) != 2 {
+		t.Fatalf("型解決器の数 = %d, expected 2", len(ga.resolvers))
+	}
+	for i, file := range []string{"file_linux.go", "file_windows.go"} {
+		obj := ga.resolvers[i].packages["example.com/app"].Scope().Lookup("open")
+		if obj == nil || filepath.Base(ga.resolvers[i].fset.Position(obj.Pos()).Filename) != file {
+			t.Errorf("%d番目の型チェックの open が %s で宣言されたものではありません", i, file)
+		}
+	}
+	for _, pf := range ga.parsedFiles {
+		if pf.TypesInfo == nil {
+			t.Errorf("%s に型情報が関連付けられていません", pf.Path)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package stability
 
 import (
+	"sort"
+
 	"github.com/harakeishi/depsee/internal/graph"
 	"github.com/harakeishi/depsee/internal/types"
 )
@@ -13,24 +15,49 @@ type Analyzer interface {
 	// AnalyzeNode calculates stability for a specific node
 	AnalyzeNode(nodeID types.NodeID, g *graph.DependencyGraph) *NodeStability
 	
-	// AnalyzePackage calculates stability for a specific package
-	AnalyzePackage(packageName string, g *graph.DependencyGraph) *PackageStability
+	// AnalyzePackage calculates stability for a specific package.
+	// packagePath is the package identifier used by graph nodes (import path, or name when unknown).
+	AnalyzePackage(packagePath string, g *graph.DependencyGraph) *PackageStability
 	
 	// DetectSDPViolations finds violations of the Stable Dependencies Principle
 	DetectSDPViolations(g *graph.DependencyGraph) []SDPViolation
+
+	// SetWeights enables the weighted metrics, computed in addition to the unweighted ones.
+	// nil disables them.
+	SetWeights(weights Weights)
+
+	// SetIncludeExternal controls whether external nodes (third-party modules and standard packages)
+	// take part in the instability and SDP calculations. They are left out by default and only
+	// reported through Result.ExternalFanIns.
+	SetIncludeExternal(include bool)
 }
 
 // analyzer is the default implementation of Analyzer
-type analyzer struct{}
+type analyzer struct {
+	weights         Weights // nil unless weighted metrics are enabled
+	includeExternal bool    // external nodes count towards instability
+}
 
 // NewAnalyzer creates a new stability analyzer
 func NewAnalyzer() Analyzer {
 	return &analyzer{}
 }
 
+// SetWeights enables or disables the weighted metrics
+func (a *analyzer) SetWeights(weights Weights) {
+	a.weights = weights
+}
+
+// SetIncludeExternal enables or disables external nodes in the instability calculations
+func (a *analyzer) SetIncludeExternal(include bool) {
+	a.includeExternal = include
+}
+
 // Analyze performs complete stability analysis
 func (a *analyzer) Analyze(g *graph.DependencyGraph) *Result {
 	result := NewResult()
+	result.ExternalFanIns = externalFanIns(g)
+	g = a.target(g)
 	
 	// Calculate in/out degrees
 	inDegree := make(map[types.NodeID]int)
@@ -63,6 +90,25 @@ func (a *analyzer) Analyze(g *graph.DependencyGraph) *Result {
 		}
 	}
 	
+	// Calculate weighted node stability
+	if a.weights != nil {
+		result.Weights = a.weights
+		weightedIn := make(map[types.NodeID]float64)
+		weightedOut := make(map[types.NodeID]float64)
+		for from, tos := range g.Edges {
+			for to, edge := range tos {
+				weight := a.weights.EdgeWeight(edge)
+				weightedOut[from] += weight
+				weightedIn[to] += weight
+			}
+		}
+		for id, s := range result.NodeStabilities {
+			s.WeightedOutDegree = weightedOut[id]
+			s.WeightedInDegree = weightedIn[id]
+			s.WeightedInstability = instabilityOf(s.WeightedOutDegree, s.WeightedInDegree)
+		}
+	}
+	
 	// Calculate package stability
 	result.PackageStabilities = a.calculatePackageStability(g)
 	
@@ -74,6 +120,7 @@ func (a *analyzer) Analyze(g *graph.DependencyGraph) *Result {
 
 // AnalyzeNode calculates stability for a specific node
 func (a *analyzer) AnalyzeNode(nodeID types.NodeID, g *graph.DependencyGraph) *NodeStability {
+	g = a.target(g)
 	inDegree := 0
 	outDegree := 0
 	
@@ -96,24 +143,47 @@ func (a *analyzer) AnalyzeNode(nodeID types.NodeID, g *graph.DependencyGraph) *N
 		instability = float64(outDegree) / float64(inDegree+outDegree)
 	}
 	
-	return &NodeStability{
+	result := &NodeStability{
 		NodeID:      nodeID,
 		OutDegree:   outDegree,
 		InDegree:    inDegree,
 		Instability: instability,
 	}
+	if a.weights != nil {
+		a.applyNodeWeights(result, g)
+	}
+	return result
+}
+
+// applyNodeWeights sets the weighted metrics of a single node
+func (a *analyzer) applyNodeWeights(s *NodeStability, g *graph.DependencyGraph) {
+	for _, edge := range g.Edges[s.NodeID] {
+		s.WeightedOutDegree += a.weights.EdgeWeight(edge)
+	}
+	for _, tos := range g.Edges {
+		if edge, exists := tos[s.NodeID]; exists {
+			s.WeightedInDegree += a.weights.EdgeWeight(edge)
+		}
+	}
+	s.WeightedInstability = instabilityOf(s.WeightedOutDegree, s.WeightedInDegree)
 }
 
 // AnalyzePackage calculates stability for a specific package
-func (a *analyzer) AnalyzePackage(packageName string, g *graph.DependencyGraph) *PackageStability {
+func (a *analyzer) AnalyzePackage(packagePath string, g *graph.DependencyGraph) *PackageStability {
+	g = a.target(g)
 	packageDeps := make(map[string]struct{})
 	packageRevDeps := make(map[string]struct{})
+	packageName := packagePath
+	var weightedOut, weightedIn float64
 	
 	for from, tos := range g.Edges {
 		fromNode := g.Nodes[from]
 		if fromNode == nil {
 			continue
 		}
+		if fromNode.Package == packagePath {
+			packageName = fromNode.DisplayPackage()
+		}
 		
 		for to := range tos {
 			toNode := g.Nodes[to]
@@ -122,13 +192,19 @@ func (a *analyzer) AnalyzePackage(packageName string, g *graph.DependencyGraph)
 			}
 			
 			// Track package dependencies
-			if fromNode.Package == packageName && toNode.Package != packageName {
+			if fromNode.Package == packagePath && toNode.Package != packagePath {
 				packageDeps[toNode.Package] = struct{}{}
+				if a.weights != nil {
+					weightedOut += a.weights.EdgeWeight(tos[to])
+				}
 			}
 			
 			// Track reverse dependencies
-			if toNode.Package == packageName && fromNode.Package != packageName {
+			if toNode.Package == packagePath && fromNode.Package != packagePath {
 				packageRevDeps[fromNode.Package] = struct{}{}
+				if a.weights != nil {
+					weightedIn += a.weights.EdgeWeight(tos[to])
+				}
 			}
 		}
 	}
@@ -143,16 +219,24 @@ func (a *analyzer) AnalyzePackage(packageName string, g *graph.DependencyGraph)
 		instability = float64(outDegree) / float64(inDegree+outDegree)
 	}
 	
-	return &PackageStability{
+	result := &PackageStability{
 		PackageName: packageName,
+		PackagePath: packagePath,
 		OutDegree:   outDegree,
 		InDegree:    inDegree,
 		Instability: instability,
 	}
+	if a.weights != nil {
+		result.WeightedOutDegree = weightedOut
+		result.WeightedInDegree = weightedIn
+		result.WeightedInstability = instabilityOf(weightedOut, weightedIn)
+	}
+	return result
 }
 
 // DetectSDPViolations finds SDP violations in the graph
 func (a *analyzer) DetectSDPViolations(g *graph.DependencyGraph) []SDPViolation {
+	g = a.target(g)
 	stabilities := make(map[types.NodeID]*NodeStability)
 	
 	// First calculate all node stabilities
@@ -165,8 +249,10 @@ func (a *analyzer) DetectSDPViolations(g *graph.DependencyGraph) []SDPViolation
 
 // calculatePackageStability calculates stability for all packages
 func (a *analyzer) calculatePackageStability(g *graph.DependencyGraph) map[string]*PackageStability {
-	packages := make(map[string]struct{})
+	packages := make(map[string]string) // パッケージの識別子 -> 表示用パッケージ名
 	packageDeps := make(map[string]map[string]struct{})
+	weightedOut := make(map[string]float64) // パッケージ間のエッジの重みの合計
+	weightedIn := make(map[string]float64)
 	
 	// Collect all packages and their dependencies
 	for from, tos := range g.Edges {
@@ -184,13 +270,13 @@ func (a *analyzer) calculatePackageStability(g *graph.DependencyGraph) map[strin
 			fromPkg = fromNode.Package
 		}
 		
-		packages[fromPkg] = struct{}{}
+		packages[fromPkg] = fromNode.DisplayPackage()
 		
 		if packageDeps[fromPkg] == nil {
 			packageDeps[fromPkg] = make(map[string]struct{})
 		}
 		
-		for to := range tos {
+		for to, edge := range tos {
 			toNode := g.Nodes[to]
 			if toNode == nil {
 				continue
@@ -205,10 +291,15 @@ func (a *analyzer) calculatePackageStability(g *graph.DependencyGraph) map[strin
 				toPkg = toNode.Package
 			}
 			
-			packages[toPkg] = struct{}{}
+			packages[toPkg] = toNode.DisplayPackage()
 			
 			if fromPkg != toPkg {
 				packageDeps[fromPkg][toPkg] = struct{}{}
+				if a.weights != nil {
+					weight := a.weights.EdgeWeight(edge)
+					weightedOut[fromPkg] += weight
+					weightedIn[toPkg] += weight
+				}
 			}
 		}
 	}
@@ -226,7 +317,7 @@ func (a *analyzer) calculatePackageStability(g *graph.DependencyGraph) map[strin
 	
 	// Create package stability results
 	result := make(map[string]*PackageStability)
-	for pkg := range packages {
+	for pkg, name := range packages {
 		ce := packageOutDegree[pkg]
 		ca := packageInDegree[pkg]
 		
@@ -238,11 +329,17 @@ func (a *analyzer) calculatePackageStability(g *graph.DependencyGraph) map[strin
 		}
 		
 		result[pkg] = &PackageStability{
-			PackageName: pkg,
+			PackageName: name,
+			PackagePath: pkg,
 			OutDegree:   ce,
 			InDegree:    ca,
 			Instability: instability,
 		}
+		if a.weights != nil {
+			result[pkg].WeightedOutDegree = weightedOut[pkg]
+			result[pkg].WeightedInDegree = weightedIn[pkg]
+			result[pkg].WeightedInstability = instabilityOf(weightedOut[pkg], weightedIn[pkg])
+		}
 	}
 	
 	return result
@@ -258,7 +355,7 @@ func (a *analyzer) detectSDPViolations(g *graph.DependencyGraph, nodeStabilities
 			continue
 		}
 		
-		for to := range tos {
+		for to, edge := range tos {
 			toStability, toExists := nodeStabilities[to]
 			if !toExists {
 				continue
@@ -272,11 +369,105 @@ func (a *analyzer) detectSDPViolations(g *graph.DependencyGraph, nodeStabilities
 					FromInstability:   fromStability.Instability,
 					ToInstability:     toStability.Instability,
 					ViolationSeverity: toStability.Instability - fromStability.Instability,
+					DependencyTypes:   edge.DependencyTypes(),
+					Count:             edge.Count,
+					Positions:         edge.Positions,
 				}
 				violations = append(violations, violation)
 			}
 		}
 	}
-	
+
+	// Report violations in a stable order regardless of map iteration
+	sort.Slice(violations, func(i, j int) bool {
+		if violations[i].From != violations[j].From {
+			return violations[i].From < violations[j].From
+		}
+		return violations[i].To < violations[j].To
+	})
 	return violations
-}
\ No newline at end of file
+}
+
+// target returns the graph the instability calculations run on.
+// Test nodes form a separate layer and are always left out, so that references from tests
+// do not make production code look stable; external nodes are left out unless they are included.
+func (a *analyzer) target(g *graph.DependencyGraph) *graph.DependencyGraph {
+	return withoutNodes(g, func(n *graph.Node) bool {
+		return n.Test || (!a.includeExternal && n.IsExternal())
+	})
+}
+
+// withoutNodes returns g without the nodes matching exclude and the edges to or from them.
+// g is returned as is when no node matches.
+func withoutNodes(g *graph.DependencyGraph, exclude func(n *graph.Node) bool) *graph.DependencyGraph {
+	excluded := make(map[types.NodeID]bool)
+	for id, n := range g.Nodes {
+		if exclude(n) {
+			excluded[id] = true
+		}
+	}
+	if len(excluded) == 0 {
+		return g
+	}
+
+	kept := graph.NewDependencyGraph()
+	for id, n := range g.Nodes {
+		if !excluded[id] {
+			kept.Nodes[id] = n
+		}
+	}
+	for from, tos := range g.Edges {
+		if excluded[from] {
+			continue
+		}
+		for to, edge := range tos {
+			if excluded[to] {
+				continue
+			}
+			if kept.Edges[from] == nil {
+				kept.Edges[from] = make(map[types.NodeID]*graph.Edge)
+			}
+			kept.Edges[from][to] = edge
+		}
+	}
+	return kept
+}
+
+// externalFanIns counts, for every external node, the analyzed nodes and packages depending on it
+func externalFanIns(g *graph.DependencyGraph) map[types.NodeID]*ExternalFanIn {
+	fanIns := make(map[types.NodeID]*ExternalFanIn)
+	packages := make(map[types.NodeID]map[string]struct{})
+	for id, n := range g.Nodes {
+		if n.IsExternal() {
+			fanIns[id] = &ExternalFanIn{NodeID: id, Path: n.Package}
+			packages[id] = make(map[string]struct{})
+		}
+	}
+	for from, tos := range g.Edges {
+		fromNode := g.Nodes[from]
+		if fromNode == nil || fromNode.IsExternal() {
+			continue
+		}
+		for to, edge := range tos {
+			fanIn, ok := fanIns[to]
+			if !ok {
+				continue
+			}
+			fanIn.InDegree++
+			fanIn.Count += edge.Count
+			packages[to][fromNode.Package] = struct{}{}
+		}
+	}
+	for id, fanIn := range fanIns {
+		fanIn.PackageInDegree = len(packages[id])
+	}
+	return fanIns
+}
+
+// instabilityOf returns I = Ce / (Ca + Ce), treating isolated elements as unstable
+func instabilityOf(ce, ca float64) float64 {
+	if ce+ca == 0 {
+		return 1.0
+	}
+	return ce / (ce + ca)
+}
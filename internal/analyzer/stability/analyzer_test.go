@@ -1,6 +1,7 @@
 package stability
 
 import (
+	"go/token"
 	"math"
 	"testing"
 
@@ -575,6 +576,40 @@ func TestDetectSDPViolations(t *testing.T) {
 	}
 }
 
+func TestDetectSDPViolationsWithEdgeDetails(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.AddNode(&graph.Node{ID: "test.Stable", Kind: graph.NodeStruct, Name: "Stable", Package: "test"})
+	g.AddNode(&graph.Node{ID: "test.Unstable", Kind: graph.NodeStruct, Name: "Unstable", Package: "test"})
+	// Stable: Ce=1, Ca=2 (I=0.33), Unstable: Ce=2, Ca=1 (I=0.67) により Stable -> Unstable が違反となる
+	g.AddEdge("test.A", "test.Stable")
+	g.AddEdge("test.B", "test.Stable")
+	g.AddEdge("test.Unstable", "test.C")
+	g.AddEdge("test.Unstable", "test.D")
+	pos := token.Position{Filename: "stable.go", Line: 8, Column: 2}
+	g.AddDependency(types.DependencyInfo{From: "test.Stable", To: "test.Unstable", Type: types.FieldDependency, Position: pos})
+
+	result := NewAnalyzer().Analyze(g)
+
+	var found *SDPViolation
+	for i, violation := range result.SDPViolations {
+		if violation.From == "test.Stable" && violation.To == "test.Unstable" {
+			found = &result.SDPViolations[i]
+		}
+	}
+	if found == nil {
+		t.Fatalf("SDP違反 test.Stable -> test.Unstable が検出されませんでした: %+v", result.SDPViolations)
+	}
+	if len(found.DependencyTypes) != 1 || found.DependencyTypes[0] != types.FieldDependency {
+		t.Errorf("依存の種類が正しくありません: %v", found.DependencyTypes)
+	}
+	if found.Count != 1 {
+		t.Errorf("出現回数が正しくありません: %d", found.Count)
+	}
+	if len(found.Positions) != 1 || found.Positions[0] != pos {
+		t.Errorf("出現位置が正しくありません: %v", found.Positions)
+	}
+}
+
 func TestDetectSDPViolationsNoViolations(t *testing.T) {
 	g := graph.NewDependencyGraph()
 
@@ -619,3 +654,162 @@ func TestDetectSDPViolationsEmptyGraph(t *testing.T) {
 		t.Errorf("空のグラフでSDP違反が検出されました。違反数: %d", len(result.SDPViolations))
 	}
 }
+
+func TestAnalyzeWeighted(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	for _, node := range []*graph.Node{
+		{ID: "pkg1.A", Kind: graph.NodeStruct, Name: "A", Package: "pkg1"},
+		{ID: "pkg1.B", Kind: graph.NodeFunc, Name: "B", Package: "pkg1"},
+		{ID: "pkg2.C", Kind: graph.NodeStruct, Name: "C", Package: "pkg2"},
+	} {
+		g.AddNode(node)
+	}
+	// A -> C はフィールド1回、B -> C は呼び出し4回、C -> A はシグネチャ1回
+	g.AddTypedEdge("pkg1.A", "pkg2.C", types.FieldDependency)
+	for i := 0; i < 4; i++ {
+		g.AddTypedEdge("pkg1.B", "pkg2.C", types.BodyCallDependency)
+	}
+	g.AddTypedEdge("pkg2.C", "pkg1.A", types.SignatureDependency)
+
+	analyzer := NewAnalyzer()
+	analyzer.SetWeights(Weights{
+		types.FieldDependency:     3,
+		types.SignatureDependency: 2,
+		types.BodyCallDependency:  1,
+	})
+	result := analyzer.Analyze(g)
+
+	if result.Weights == nil {
+		t.Fatal("Expected weights to be reported")
+	}
+
+	c := result.NodeStabilities["pkg2.C"]
+	// 通常の値は重み付きモードでも変わらない
+	if c.OutDegree != 1 || c.InDegree != 2 || math.Abs(c.Instability-1.0/3.0) > 0.001 {
+		t.Errorf("Unexpected unweighted metrics for C: %+v", c)
+	}
+	if c.WeightedOutDegree != 2 || c.WeightedInDegree != 7 {
+		t.Errorf("Expected weighted Ce=2, Ca=7 for C, got Ce=%v, Ca=%v", c.WeightedOutDegree, c.WeightedInDegree)
+	}
+	if math.Abs(c.WeightedInstability-2.0/9.0) > 0.001 {
+		t.Errorf("Expected weighted instability 0.222 for C, got %.3f", c.WeightedInstability)
+	}
+
+	// AnalyzeNode でも同じ値が得られる
+	single := analyzer.AnalyzeNode("pkg2.C", g)
+	if single.WeightedOutDegree != c.WeightedOutDegree || single.WeightedInDegree != c.WeightedInDegree {
+		t.Errorf("AnalyzeNode weighted metrics mismatch: %+v", single)
+	}
+
+	// パッケージはパッケージ間のエッジの重みの合計で数える
+	pkg1 := result.PackageStabilities["pkg1"]
+	if pkg1.OutDegree != 1 || pkg1.WeightedOutDegree != 7 || pkg1.WeightedInDegree != 2 {
+		t.Errorf("Unexpected metrics for pkg1: %+v", pkg1)
+	}
+	single2 := analyzer.AnalyzePackage("pkg1", g)
+	if single2.WeightedOutDegree != 7 || single2.WeightedInDegree != 2 {
+		t.Errorf("AnalyzePackage weighted metrics mismatch: %+v", single2)
+	}
+}
+
+func TestAnalyzeUnweightedHasNoWeightedMetrics(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.AddNode(&graph.Node{ID: "test.A", Kind: graph.NodeStruct, Name: "A", Package: "test"})
+	g.AddNode(&graph.Node{ID: "test.B", Kind: graph.NodeStruct, Name: "B", Package: "test"})
+	g.AddTypedEdge("test.A", "test.B", types.FieldDependency)
+
+	result := NewAnalyzer().Analyze(g)
+	if result.Weights != nil {
+		t.Error("Expected no weights in unweighted mode")
+	}
+	if a := result.NodeStabilities["test.A"]; a.WeightedOutDegree != 0 || a.WeightedInstability != 0 {
+		t.Errorf("Expected no weighted metrics in unweighted mode: %+v", a)
+	}
+}
+
+func TestAnalyzeExternalNodes(t *testing.T) {
+	cobra := types.NewExternalNodeID("github.com/spf13/cobra")
+	newGraph := func() *graph.DependencyGraph {
+		g := graph.NewDependencyGraph()
+		g.AddNode(&graph.Node{ID: "cmd.Run", Kind: graph.NodeFunc, Name: "Run", Package: "cmd"})
+		g.AddNode(&graph.Node{ID: "cmd.Root", Kind: graph.NodeStruct, Name: "Root", Package: "cmd"})
+		g.AddNode(&graph.Node{ID: "app.Main", Kind: graph.NodeFunc, Name: "Main", Package: "app"})
+		g.AddNode(&graph.Node{ID: cobra, Kind: graph.NodeExternal, Name: "github.com/spf13/cobra", Package: "github.com/spf13/cobra"})
+		g.AddEdge("cmd.Run", "cmd.Root")
+		g.AddTypedEdge("cmd.Run", cobra, types.ExternalDependency)
+		g.AddTypedEdge("cmd.Run", cobra, types.ExternalDependency)
+		g.AddTypedEdge("cmd.Root", cobra, types.ExternalDependency)
+		g.AddTypedEdge("app.Main", cobra, types.ExternalDependency)
+		return g
+	}
+
+	t.Run("既定では不安定度から除外", func(t *testing.T) {
+		result := NewAnalyzer().Analyze(newGraph())
+
+		if _, exists := result.NodeStabilities[cobra]; exists {
+			t.Error("External node should not have a node stability")
+		}
+		// Root: Ce=0 (cobraは数えない), Ca=1 → I=0
+		if s := result.NodeStabilities["cmd.Root"]; s.OutDegree != 0 || s.Instability != 0 {
+			t.Errorf("Root: expected Ce=0 and I=0, got Ce=%d I=%.2f", s.OutDegree, s.Instability)
+		}
+		if _, exists := result.PackageStabilities["github.com/spf13/cobra"]; exists {
+			t.Error("External module should not have a package stability")
+		}
+		if len(result.SDPViolations) != 0 {
+			t.Errorf("Expected no SDP violations, got %v", result.SDPViolations)
+		}
+
+		fanIn := result.ExternalFanIns[cobra]
+		if fanIn == nil {
+			t.Fatal("External fan-in not found")
+		}
+		if fanIn.InDegree != 3 || fanIn.PackageInDegree != 2 || fanIn.Count != 4 {
+			t.Errorf("Expected InDegree=3, PackageInDegree=2, Count=4, got %+v", fanIn)
+		}
+	})
+
+	t.Run("指定時は不安定度に含める", func(t *testing.T) {
+		analyzer := NewAnalyzer()
+		analyzer.SetIncludeExternal(true)
+		result := analyzer.Analyze(newGraph())
+
+		// Root: Ce=1 (cobra), Ca=1 → I=0.5
+		if s := result.NodeStabilities["cmd.Root"]; s.OutDegree != 1 || s.Instability != 0.5 {
+			t.Errorf("Root: expected Ce=1 and I=0.5, got Ce=%d I=%.2f", s.OutDegree, s.Instability)
+		}
+		if s := result.NodeStabilities[cobra]; s == nil || s.InDegree != 3 {
+			t.Errorf("Expected external node stability with Ca=3, got %+v", s)
+		}
+		if result.ExternalFanIns[cobra] == nil {
+			t.Error("External fan-in should be reported in either mode")
+		}
+	})
+}
+
+func TestAnalyzeTestNodes(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.AddNode(&graph.Node{ID: "app.NewUser", Kind: graph.NodeFunc, Name: "NewUser", Package: "app"})
+	g.AddNode(&graph.Node{ID: "app.User", Kind: graph.NodeStruct, Name: "User", Package: "app"})
+	g.AddNode(&graph.Node{ID: "app.TestNewUser", Kind: graph.NodeFunc, Name: "TestNewUser", Package: "app", Test: true})
+	g.AddNode(&graph.Node{ID: "app_test.TestUser", Kind: graph.NodeFunc, Name: "TestUser", Package: "app_test", Test: true})
+	g.AddEdge("app.NewUser", "app.User")
+	g.AddEdge("app.TestNewUser", "app.NewUser")
+	g.AddEdge("app_test.TestUser", "app.NewUser")
+
+	result := NewAnalyzer().Analyze(g)
+
+	// テストのノードとテストからのエッジは不安定度の算出に含めない
+	for _, id := range []types.NodeID{"app.TestNewUser", "app_test.TestUser"} {
+		if _, exists := result.NodeStabilities[id]; exists {
+			t.Errorf("Test node %s should not have a node stability", id)
+		}
+	}
+	// NewUser: Ce=1, Ca=0 (テストからの参照は数えない) → I=1
+	if s := result.NodeStabilities["app.NewUser"]; s.InDegree != 0 || s.Instability != 1 {
+		t.Errorf("NewUser: expected Ca=0 and I=1, got Ca=%d I=%.2f", s.InDegree, s.Instability)
+	}
+	if _, exists := result.PackageStabilities["app_test"]; exists {
+		t.Error("External test package should not have a package stability")
+	}
+}
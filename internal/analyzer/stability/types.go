@@ -1,6 +1,10 @@
 package stability
 
-import "github.com/harakeishi/depsee/internal/types"
+import (
+	"go/token"
+
+	"github.com/harakeishi/depsee/internal/types"
+)
 
 // NodeStability represents stability metrics for a node
 type NodeStability struct {
@@ -8,30 +12,56 @@ type NodeStability struct {
 	OutDegree   int     // Ce: 出次数（このノードが依存している数）
 	InDegree    int     // Ca: 入次数（このノードに依存している数）
 	Instability float64 // I = Ce / (Ca + Ce): 不安定度（0=安定、1=不安定）
+
+	// 重み付きモードでのみ設定される（依存の種類ごとの重み×出現回数で数える）
+	WeightedOutDegree   float64 // 重み付きCe
+	WeightedInDegree    float64 // 重み付きCa
+	WeightedInstability float64 // 重み付き不安定度
 }
 
 // PackageStability represents stability metrics for a package
 type PackageStability struct {
-	PackageName string
+	PackageName string  // 表示用のパッケージ名
+	PackagePath string  // パッケージの識別子（importパス、不明な場合はパッケージ名）
 	OutDegree   int     // Ce: パッケージが依存している他パッケージの数
 	InDegree    int     // Ca: パッケージに依存している他パッケージの数
 	Instability float64 // I = Ce / (Ca + Ce): 不安定度（0=安定、1=不安定）
+
+	// 重み付きモードでのみ設定される（パッケージ間のエッジの重みの合計で数える）
+	WeightedOutDegree   float64 // 重み付きCe
+	WeightedInDegree    float64 // 重み付きCa
+	WeightedInstability float64 // 重み付き不安定度
+}
+
+// ExternalFanIn represents how much the analyzed code depends on an external node
+// (a third-party module or a standard library package)
+type ExternalFanIn struct {
+	NodeID          types.NodeID
+	Path            string // モジュールパス（標準ライブラリの場合はimportパス）
+	InDegree        int    // Ca: 外部依存ノードに依存している解析対象のノード数
+	PackageInDegree int    // 外部依存ノードに依存している解析対象のパッケージ数
+	Count           int    // 外部依存ノードへの参照の出現回数の合計
 }
 
 // SDPViolation represents a Stable Dependencies Principle violation
 type SDPViolation struct {
-	From              types.NodeID // 依存元ノード
-	To                types.NodeID // 依存先ノード
-	FromInstability   float64      // 依存元の不安定度
-	ToInstability     float64      // 依存先の不安定度
-	ViolationSeverity float64      // 違反の深刻度（不安定度の差）
+	From              types.NodeID           // 依存元ノード
+	To                types.NodeID           // 依存先ノード
+	FromInstability   float64                // 依存元の不安定度
+	ToInstability     float64                // 依存先の不安定度
+	ViolationSeverity float64                // 違反の深刻度（不安定度の差）
+	DependencyTypes   []types.DependencyType // 違反エッジを構成する依存の種類
+	Count             int                    // 違反エッジの依存の出現回数
+	Positions         []token.Position       // 違反エッジの依存を生じさせている位置
 }
 
 // Result contains the complete stability analysis results
 type Result struct {
 	NodeStabilities    map[types.NodeID]*NodeStability
-	PackageStabilities map[string]*PackageStability
-	SDPViolations      []SDPViolation // SDP違反のリスト
+	PackageStabilities map[string]*PackageStability    // パッケージの識別子（importパス）をキーとする
+	SDPViolations      []SDPViolation                  // SDP違反のリスト
+	Weights            Weights                         // 重み付きモードで使用した重み（通常モードではnil）
+	ExternalFanIns     map[types.NodeID]*ExternalFanIn // 外部依存ノードごとの被依存数（外部依存ノードがない場合は空）
 }
 
 // NewResult creates a new stability result
@@ -40,5 +70,6 @@ func NewResult() *Result {
 		NodeStabilities:    make(map[types.NodeID]*NodeStability),
 		PackageStabilities: make(map[string]*PackageStability),
 		SDPViolations:      make([]SDPViolation, 0),
+		ExternalFanIns:     make(map[types.NodeID]*ExternalFanIn),
 	}
-}
\ No newline at end of file
+}
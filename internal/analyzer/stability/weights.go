@@ -0,0 +1,107 @@
+package stability
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/harakeishi/depsee/internal/graph"
+	"github.com/harakeishi/depsee/internal/types"
+)
+
+// defaultWeight is the weight of dependency kinds missing from Weights
+// and of occurrences recorded without a kind
+const defaultWeight = 1.0
+
+// Weights maps dependency kinds to the weight of a single occurrence
+// used by the weighted stability metrics
+type Weights map[types.DependencyType]float64
+
+// DefaultWeights returns the default weights: type definitions and embeddings > fields >
+// signatures, receivers, constraints, implementations and instantiations > calls, function values,
+// type usages, goroutine spawns, global accesses and external references
+func DefaultWeights() Weights {
+	return Weights{
+		types.UnderlyingDependency:     4,
+		types.EmbeddingDependency:      4,
+		types.FieldDependency:          3,
+		types.SignatureDependency:      2,
+		types.ReceiverDependency:       2,
+		types.ConstraintDependency:     2,
+		types.ImplementationDependency: 2,
+		types.InstantiationDependency:  2,
+		types.BodyCallDependency:       1,
+		types.CrossPackageDependency:   1,
+		types.PackageDependency:        1,
+		types.ReadDependency:           1,
+		types.WriteDependency:          1,
+		types.ExternalDependency:       1,
+		types.FuncValueDependency:      1,
+		types.TypeUsageDependency:      1,
+		types.SpawnDependency:          1,
+	}
+}
+
+// ParseWeights parses a comma separated list of kind=weight pairs (e.g. "field=3,body_call=0.5")
+// and applies it on top of DefaultWeights
+func ParseWeights(spec string) (Weights, error) {
+	weights := DefaultWeights()
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("重みの指定が不正です（kind=weight形式で指定してください）: %s", pair)
+		}
+		depType, ok := types.ParseDependencyType(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("未知の依存の種類です: %s", name)
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("重みには0以上の数値を指定してください: %s", pair)
+		}
+		weights[depType] = weight
+	}
+	return weights, nil
+}
+
+// Of returns the weight of a single occurrence of depType
+func (w Weights) Of(depType types.DependencyType) float64 {
+	if weight, ok := w[depType]; ok {
+		return weight
+	}
+	return defaultWeight
+}
+
+// EdgeWeight returns the weight of an edge: the sum of the weights of all its occurrences
+func (w Weights) EdgeWeight(e *graph.Edge) float64 {
+	var weight float64
+	typed := 0
+	for depType, count := range e.Kinds {
+		weight += w.Of(depType) * float64(count)
+		typed += count
+	}
+	if untyped := e.Count - typed; untyped > 0 {
+		weight += defaultWeight * float64(untyped)
+	}
+	return weight
+}
+
+// String formats the weights as kind=weight pairs sorted by kind
+func (w Weights) String() string {
+	var depTypes []types.DependencyType
+	for depType := range w {
+		depTypes = append(depTypes, depType)
+	}
+	sort.Slice(depTypes, func(i, j int) bool { return depTypes[i] < depTypes[j] })
+
+	pairs := make([]string, 0, len(depTypes))
+	for _, depType := range depTypes {
+		pairs = append(pairs, depType.String()+"="+strconv.FormatFloat(w[depType], 'g', -1, 64))
+	}
+	return strings.Join(pairs, ",")
+}
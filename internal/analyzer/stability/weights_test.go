@@ -0,0 +1,44 @@
+package stability
+
+import (
+	"testing"
+
+	"github.com/harakeishi/depsee/internal/graph"
+	"github.com/harakeishi/depsee/internal/types"
+)
+
+func TestParseWeights(t *testing.T) {
+	weights, err := ParseWeights("field=5, body_call=0.5")
+	if err != nil {
+		t.Fatalf("ParseWeights returned error: %v", err)
+	}
+	if weights.Of(types.FieldDependency) != 5 {
+		t.Errorf("Expected field weight 5, got %v", weights.Of(types.FieldDependency))
+	}
+	if weights.Of(types.BodyCallDependency) != 0.5 {
+		t.Errorf("Expected body_call weight 0.5, got %v", weights.Of(types.BodyCallDependency))
+	}
+	// 指定されなかった種類は既定の重みのまま
+	if weights.Of(types.SignatureDependency) != DefaultWeights()[types.SignatureDependency] {
+		t.Errorf("Expected default signature weight, got %v", weights.Of(types.SignatureDependency))
+	}
+
+	for _, spec := range []string{"field", "unknown=1", "field=abc", "field=-1"} {
+		if _, err := ParseWeights(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
+
+func TestWeightsEdgeWeight(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.AddTypedEdge("test.A", "test.B", types.FieldDependency)
+	g.AddTypedEdge("test.A", "test.B", types.BodyCallDependency)
+	g.AddTypedEdge("test.A", "test.B", types.BodyCallDependency)
+	g.AddEdge("test.A", "test.B") // 種類なし（既定の重み）
+
+	weights := Weights{types.FieldDependency: 3, types.BodyCallDependency: 0.5}
+	if got := weights.EdgeWeight(g.Edge("test.A", "test.B")); got != 5 {
+		t.Errorf("Expected edge weight 5 (3 + 0.5*2 + 1), got %v", got)
+	}
+}
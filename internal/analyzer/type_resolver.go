@@ -1,11 +1,14 @@
 package analyzer
 
 import (
+	"fmt"
 	"go/ast"
-	"go/importer"
+	"go/build"
 	"go/parser"
 	"go/token"
 	"go/types"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/harakeishi/depsee/internal/logger"
@@ -14,24 +17,147 @@ import (
 // TypeResolver は型情報を解決するための構造体です。
 // Go言語の型チェッカーを使用して正確な型情報を取得し、
 // 型名の解決やパッケージ情報の管理を行います。
+// TypeResolver自身がimport先を解決するインポーターとなり、解析対象のパッケージは型チェック済みのものを返します。
+// そのため別パッケージの型同士も同一の型オブジェクトとして比較できます。
 type TypeResolver struct {
-	fset     *token.FileSet             // ファイル位置情報の管理
-	packages map[string]*types.Package  // パッケージ名から型情報へのマッピング
+	fset     *token.FileSet            // ファイル位置情報の管理
+	packages map[string]*types.Package // パッケージ名から型情報へのマッピング
 	info     *types.Info               // 型チェック結果の詳細情報
+	sources  map[string][]*ast.File    // AddPackageで登録された解析対象のパッケージのファイル
+	checking map[string]bool           // 型チェック中のパッケージ（importの循環検出用）
+	imported map[string]*types.Package // 解析対象外のimport先パッケージ（nilは読み込み中）
+	build    build.Context             // 解析対象外のimport先パッケージを探すビルドコンテキスト
 }
 
 // NewTypeResolver は新しいTypeResolverを作成します。
 // 型解決に必要な内部構造を初期化します。
 func NewTypeResolver() *TypeResolver {
+	return NewTypeResolverWithFileSet(token.NewFileSet())
+}
+
+// NewTypeResolverWithFileSet は既存のFileSetを共有するTypeResolverを作成します。
+// 解析済みのASTをそのまま型チェックする場合に使用します。
+func NewTypeResolverWithFileSet(fset *token.FileSet) *TypeResolver {
 	return &TypeResolver{
-		fset:     token.NewFileSet(),
+		fset:     fset,
 		packages: make(map[string]*types.Package),
 		info: &types.Info{
-			Types: make(map[ast.Expr]types.TypeAndValue),
-			Defs:  make(map[*ast.Ident]types.Object),
-			Uses:  make(map[*ast.Ident]types.Object),
+			Types:      make(map[ast.Expr]types.TypeAndValue),
+			Defs:       make(map[*ast.Ident]types.Object),
+			Uses:       make(map[*ast.Ident]types.Object),
+			Scopes:     make(map[ast.Node]*types.Scope),
+			Instances:  make(map[*ast.Ident]types.Instance),
+			Selections: make(map[*ast.SelectorExpr]*types.Selection),
 		},
+		sources:  make(map[string][]*ast.File),
+		checking: make(map[string]bool),
+		imported: make(map[string]*types.Package),
+		build:    build.Default,
+	}
+}
+
+// SetDir はimport先のパッケージを探す基準のディレクトリ（解析対象のモジュールのルート）を設定します。
+// goコマンドはこのディレクトリのgo.mod・go.workを使うため、プロセスの作業ディレクトリに依存せずにimportを解決できます。
+func (tr *TypeResolver) SetDir(dir string) {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	tr.build.Dir = dir
+}
+
+// SetBuildContext はimport先のパッケージを探すビルドコンテキスト（GOOS・GOARCH・ビルドタグ）を設定します。
+// SetDirで設定したディレクトリは引き継ぎます。
+func (tr *TypeResolver) SetBuildContext(ctxt build.Context) {
+	ctxt.Dir = tr.build.Dir
+	tr.build = ctxt
+}
+
+// AddPackage は解析対象のパッケージを登録します。
+// 登録したパッケージは他のパッケージからimportされた時点で型チェックされるため、
+// CheckAllで全パッケージを型チェックするとimport先が常に先に型チェックされます。
+func (tr *TypeResolver) AddPackage(pkgPath string, files []*ast.File) {
+	tr.sources[pkgPath] = files
+}
+
+// CheckAll はAddPackageで登録した全パッケージを型チェックします。
+// import先として型チェック済みのパッケージは再度型チェックしません。
+func (tr *TypeResolver) CheckAll(pkgPaths []string) {
+	for _, pkgPath := range pkgPaths {
+		if _, done := tr.packages[pkgPath]; done {
+			continue
+		}
+		tr.CheckFiles(pkgPath, tr.sources[pkgPath])
+	}
+}
+
+// Import はimportパスのパッケージの型情報を返します（types.Importerの実装）。
+func (tr *TypeResolver) Import(path string) (*types.Package, error) {
+	return tr.ImportFrom(path, "", 0)
+}
+
+// ImportFrom はimportしているファイルのディレクトリを基準に、importパスのパッケージの型情報を返します（types.ImporterFromの実装）。
+// 解析対象のパッケージは型チェック済みのもの（未チェックの場合はその場で型チェックしたもの）を返し、
+// それ以外は設定したディレクトリを基準に探したソースから関数本体を省略して型チェックします。
+func (tr *TypeResolver) ImportFrom(path, dir string, _ types.ImportMode) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+	if pkg, ok := tr.packages[path]; ok {
+		return pkg, nil
+	}
+	if files, ok := tr.sources[path]; ok {
+		if tr.checking[path] {
+			return nil, fmt.Errorf("import cycle: %s", path)
+		}
+		if pkg := tr.CheckFiles(path, files); pkg != nil {
+			return pkg, nil
+		}
+		return nil, fmt.Errorf("type-check failed: %s", path)
+	}
+	return tr.importSource(path, dir)
+}
+
+// importSource は解析対象外のパッケージをソースから読み込んで型チェックします。
+func (tr *TypeResolver) importSource(path, dir string) (*types.Package, error) {
+	if pkg, ok := tr.imported[path]; ok {
+		if pkg == nil {
+			return nil, fmt.Errorf("import cycle: %s", path)
+		}
+		return pkg, nil
+	}
+	if dir != "" && !filepath.IsAbs(dir) {
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+	}
+	bp, err := tr.build.Import(path, dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	tr.imported[path] = nil
+	var files []*ast.File
+	for _, name := range append(bp.GoFiles, bp.CgoFiles...) {
+		f, err := parser.ParseFile(tr.fset, filepath.Join(bp.Dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			delete(tr.imported, path)
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	conf := types.Config{
+		Importer:         tr,
+		IgnoreFuncBodies: true,
+		FakeImportC:      true,
+		Error:            func(error) {}, // 解析対象外のパッケージの型エラーは無視する
+	}
+	pkg, err := conf.Check(bp.ImportPath, tr.fset, files, nil)
+	if pkg == nil {
+		delete(tr.imported, path)
+		return nil, err
 	}
+	tr.imported[path] = pkg
+	return pkg, nil
 }
 
 // ResolvePackage は指定されたディレクトリのパッケージの型情報を解決します。
@@ -51,36 +177,53 @@ func (tr *TypeResolver) ResolvePackage(dir string) error {
 			continue // テストパッケージはスキップ
 		}
 
-		logger.Debug("パッケージ型チェック開始", "package", pkgName)
-
 		// ASTファイルのスライスを作成
 		var files []*ast.File
 		for _, file := range pkg.Files {
 			files = append(files, file)
 		}
 
-		// 型チェッカーの設定
-		conf := types.Config{
-			Importer: importer.ForCompiler(tr.fset, "source", nil),
-			Error: func(err error) {
-				logger.Warn("型チェックエラー", "error", err)
-			},
-		}
+		tr.CheckFiles(pkgName, files)
+	}
 
-		// 型チェック実行
-		typePkg, err := conf.Check(pkgName, tr.fset, files, tr.info)
-		if err != nil {
-			logger.Warn("型チェック部分失敗", "package", pkgName, "error", err)
-			// エラーがあっても部分的な情報は使用可能
-		}
+	return nil
+}
 
-		if typePkg != nil {
-			tr.packages[pkgName] = typePkg
-			logger.Debug("パッケージ型情報取得完了", "package", pkgName)
-		}
+// CheckFiles はパース済みのファイル群を1つのパッケージとして型チェックします。
+// ファイルはTypeResolverと同じFileSetでパースされている必要があります。
+// 型エラーがあっても部分的な型情報はInfoに蓄積されるため、エラーはログ出力のみ行います。
+func (tr *TypeResolver) CheckFiles(pkgPath string, files []*ast.File) *types.Package {
+	logger.Debug("パッケージ型チェック開始", "package", pkgPath, "files", len(files))
+
+	tr.checking[pkgPath] = true
+	defer delete(tr.checking, pkgPath)
+
+	errorCount := 0
+	conf := types.Config{
+		Importer: tr,
+		Error: func(err error) {
+			errorCount++
+			logger.Debug("型チェックエラー", "package", pkgPath, "error", err)
+		},
 	}
 
-	return nil
+	// 型チェック実行（エラーがあっても部分的な情報は使用可能）
+	typePkg, _ := conf.Check(pkgPath, tr.fset, files, tr.info)
+	if errorCount > 0 {
+		logger.Warn("型チェック部分失敗", "package", pkgPath, "errors", errorCount)
+	}
+
+	if typePkg != nil {
+		tr.packages[pkgPath] = typePkg
+		logger.Debug("パッケージ型情報取得完了", "package", pkgPath)
+	}
+	return typePkg
+}
+
+// Info は型チェックで蓄積された型情報を返します。
+// CheckFilesで型チェックした全パッケージの情報を含みます。
+func (tr *TypeResolver) Info() *types.Info {
+	return tr.info
 }
 
 // ResolveType は型表現から正確な型名を解決します。
@@ -99,29 +242,66 @@ func (tr *TypeResolver) ResolveType(expr ast.Expr) string {
 }
 
 // formatType は型情報を文字列に変換します。
-// 名前付き型、ポインタ、スライス、配列、マップ、インターフェース、基本型を
-// 適切な文字列表現に変換します。
+// exprToTypeStringと同じ表記で、名前付き型（型引数を含む）、ポインタ、スライス、配列、マップ、チャネル、
+// 関数型、構造体・インターフェースリテラル、型制約の和集合、基本型を文字列表現に変換します。
 func (tr *TypeResolver) formatType(t types.Type) string {
 	switch typ := t.(type) {
 	case *types.Named:
-		obj := typ.Obj()
-		if obj.Pkg() != nil {
-			return obj.Pkg().Name() + "." + obj.Name()
-		}
-		return obj.Name()
+		return tr.formatTypeName(typ.Obj()) + tr.formatTypeArgs(typ.TypeArgs())
+	case *types.Alias:
+		return tr.formatTypeName(typ.Obj()) + tr.formatTypeArgs(typ.TypeArgs())
+	case *types.TypeParam:
+		return typ.Obj().Name()
 	case *types.Pointer:
 		return "*" + tr.formatType(typ.Elem())
 	case *types.Slice:
 		return "[]" + tr.formatType(typ.Elem())
 	case *types.Array:
-		return "[" + string(rune(typ.Len())) + "]" + tr.formatType(typ.Elem())
+		return "[" + strconv.FormatInt(typ.Len(), 10) + "]" + tr.formatType(typ.Elem())
 	case *types.Map:
 		return "map[" + tr.formatType(typ.Key()) + "]" + tr.formatType(typ.Elem())
+	case *types.Chan:
+		switch typ.Dir() {
+		case types.SendOnly:
+			return "chan<- " + tr.formatType(typ.Elem())
+		case types.RecvOnly:
+			return "<-chan " + tr.formatType(typ.Elem())
+		default:
+			return "chan " + tr.formatType(typ.Elem())
+		}
+	case *types.Signature:
+		return "func" + tr.formatSignature(typ)
+	case *types.Struct:
+		fields := make([]string, typ.NumFields())
+		for i := range fields {
+			field := typ.Field(i)
+			if field.Embedded() {
+				fields[i] = tr.formatType(field.Type())
+				continue
+			}
+			fields[i] = field.Name() + " " + tr.formatType(field.Type())
+		}
+		return "struct{" + strings.Join(fields, "; ") + "}"
 	case *types.Interface:
-		if typ.Empty() {
-			return "interface{}"
+		var elems []string
+		for i := 0; i < typ.NumExplicitMethods(); i++ {
+			method := typ.ExplicitMethod(i)
+			elems = append(elems, method.Name()+tr.formatSignature(method.Type().(*types.Signature)))
+		}
+		for i := 0; i < typ.NumEmbeddeds(); i++ {
+			elems = append(elems, tr.formatType(typ.EmbeddedType(i)))
 		}
-		return "interface{...}"
+		return "interface{" + strings.Join(elems, "; ") + "}"
+	case *types.Union:
+		terms := make([]string, typ.Len())
+		for i := range terms {
+			term := typ.Term(i)
+			terms[i] = tr.formatType(term.Type())
+			if term.Tilde() {
+				terms[i] = "~" + terms[i]
+			}
+		}
+		return strings.Join(terms, " | ")
 	case *types.Basic:
 		return typ.Name()
 	default:
@@ -129,6 +309,53 @@ func (tr *TypeResolver) formatType(t types.Type) string {
 	}
 }
 
+// formatTypeName は名前付き型・エイリアスの名前をパッケージ名で修飾した文字列に変換します。
+func (tr *TypeResolver) formatTypeName(obj *types.TypeName) string {
+	if obj.Pkg() != nil {
+		return obj.Pkg().Name() + "." + obj.Name()
+	}
+	return obj.Name()
+}
+
+// formatTypeArgs はインスタンス化の型引数を "[User, int]" 形式の文字列に変換します。
+// 型引数がない場合は空文字を返します。
+func (tr *TypeResolver) formatTypeArgs(args *types.TypeList) string {
+	if args.Len() == 0 {
+		return ""
+	}
+	out := make([]string, args.Len())
+	for i := range out {
+		out[i] = tr.formatType(args.At(i))
+	}
+	return "[" + strings.Join(out, ", ") + "]"
+}
+
+// formatSignature は関数のシグネチャを "func" を除いた "(int, ...string) (Result, error)" 形式の文字列に変換します。
+func (tr *TypeResolver) formatSignature(sig *types.Signature) string {
+	params := make([]string, sig.Params().Len())
+	for i := range params {
+		paramType := sig.Params().At(i).Type()
+		if slice, ok := paramType.(*types.Slice); ok && sig.Variadic() && i == len(params)-1 {
+			params[i] = "..." + tr.formatType(slice.Elem())
+			continue
+		}
+		params[i] = tr.formatType(paramType)
+	}
+	results := make([]string, sig.Results().Len())
+	for i := range results {
+		results[i] = tr.formatType(sig.Results().At(i).Type())
+	}
+	out := "(" + strings.Join(params, ", ") + ")"
+	switch len(results) {
+	case 0:
+		return out
+	case 1:
+		return out + " " + results[0]
+	default:
+		return out + " (" + strings.Join(results, ", ") + ")"
+	}
+}
+
 // fallbackTypeResolution はgo/typesが使用できない場合のフォールバック処理です。
 // 型チェック情報が利用できない場合に、ASTから直接型情報を抽出します。
 func (tr *TypeResolver) fallbackTypeResolution(expr ast.Expr) string {
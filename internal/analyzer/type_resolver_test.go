@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+const typeResolverTestCode = `package test
+
+type User struct{}
+
+type Pair[K comparable, V any] struct{}
+
+type Number interface {
+	~int | float64
+}
+
+var (
+	array     [4]User
+	sendChan  chan<- *User
+	recvChan  <-chan int
+	callback  func(int, ...string) (User, error)
+	instance  Pair[string, *User]
+	literal   struct{Name string; User}
+	reader    interface{Read([]byte) (int, error); Close() error}
+	index     map[string][]User
+	empty     interface{}
+)
+`
+
+func TestTypeResolver_ResolveType(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", typeResolverTestCode, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("コード解析エラー: %v", err)
+	}
+	resolver := NewTypeResolverWithFileSet(fset)
+	if pkg := resolver.CheckFiles("test", []*ast.File{file}); pkg == nil {
+		t.Fatal("型チェックに失敗しました")
+	}
+
+	// インターフェースのメソッドは名前順
+	expected := map[string]string{
+		"array":    "[4]test.User",
+		"sendChan": "chan<- *test.User",
+		"recvChan": "<-chan int",
+		"callback": "func(int, ...string) (test.User, error)",
+		"instance": "test.Pair[string, *test.User]",
+		"literal":  "struct{Name string; test.User}",
+		"reader":   "interface{Close() error; Read([]byte) (int, error)}",
+		"index":    "map[string][]test.User",
+		"empty":    "interface{}",
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.ValueSpec)
+		if !ok {
+			return true
+		}
+		name := spec.Names[0].Name
+		if got := resolver.ResolveType(spec.Type); got != expected[name] {
+			t.Errorf("ResolveType(%s) = %q, want %q", name, got, expected[name])
+		}
+		delete(expected, name)
+		return true
+	})
+	if len(expected) > 0 {
+		t.Errorf("解決されなかった変数があります: %v", expected)
+	}
+}
+
+func TestTypeResolver_FormatUnion(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", typeResolverTestCode, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("コード解析エラー: %v", err)
+	}
+	resolver := NewTypeResolverWithFileSet(fset)
+	pkg := resolver.CheckFiles("test", []*ast.File{file})
+	if pkg == nil {
+		t.Fatal("型チェックに失敗しました")
+	}
+	number := pkg.Scope().Lookup("Number").Type().Underlying()
+	if got := resolver.formatType(number); got != "interface{~int | float64}" {
+		t.Errorf("formatType(Number) = %q", got)
+	}
+}
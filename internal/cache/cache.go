@@ -0,0 +1,202 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// formatVersion はキャッシュの保存形式のバージョン
+// 保存する構造体の形式を変更した場合は値を上げて既存のキャッシュを無効化する
+const formatVersion = "1"
+
+// entryExt はキャッシュエントリのファイル拡張子
+const entryExt = ".json"
+
+// staleAge は他のソルトのエントリを削除するまでの、最後に使われてからの期間
+const staleAge = 30 * 24 * time.Hour
+
+// Cache は解析結果をディレクトリ配下にJSONで保存する永続キャッシュ
+// キーはバージョンと解析設定を含むソルトと、呼び出し元が指定する要素（ファイル内容のハッシュ等）から算出する
+// エントリはソルトごとのサブディレクトリに保存するため、解析設定ごとのキャッシュが互いを削除することはない
+// 複数のゴルーチンから同時に使用できる
+type Cache struct {
+	dir    string
+	salt   string
+	mu     sync.Mutex
+	used   map[string]bool // この実行で読み書きしたキー
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// New はdirにエントリを保存するキャッシュを作成する
+// settingsにはdepseeのバージョンや解析設定など、変わった場合に全エントリを無効化すべき値を指定する
+func New(dir string, settings ...string) *Cache {
+	return &Cache{
+		dir:  dir,
+		salt: Hash(append([]string{formatVersion}, settings...)...),
+		used: make(map[string]bool),
+	}
+}
+
+// Dir はキャッシュの保存先ディレクトリを返す
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Key はキャッシュのソルトとpartsからエントリのキーを算出する
+func (c *Cache) Key(parts ...string) string {
+	return Hash(append([]string{c.salt}, parts...)...)
+}
+
+// Has はkeyのエントリが保存されているかどうかを返す
+func (c *Cache) Has(key string) bool {
+	_, err := os.Stat(c.path(key))
+	return err == nil
+}
+
+// Load はkeyのエントリをvに読み込む
+// エントリが存在しないか読み込めない場合はfalseを返す
+func (c *Cache) Load(key string, v any) bool {
+	c.markUsed(key)
+	data, err := os.ReadFile(c.path(key))
+	if err != nil || json.Unmarshal(data, v) != nil {
+		c.misses.Add(1)
+		return false
+	}
+	c.hits.Add(1)
+	return true
+}
+
+// Store はvをkeyのエントリとして保存する
+// 一時ファイルに書き込んでから置き換えるため、読み込み中のエントリが壊れることはない
+func (c *Cache) Store(key string, v any) error {
+	c.markUsed(key)
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.entryDir(), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.entryDir(), key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// Prune はこのソルトのエントリのうち、この実行で読み書きしなかったものを削除する
+// 変更されたファイルの古いエントリが蓄積しないようにする
+// 他のソルト（解析設定やバージョンの異なる実行）のエントリは、最後に使われてからstaleAge以上経過した場合のみ削除する
+func (c *Cache) Prune() error {
+	if err := c.pruneEntries(); err != nil {
+		return err
+	}
+	return c.pruneStaleSalts()
+}
+
+// pruneEntries はこのソルトのエントリのうち、この実行で読み書きしなかったものを削除する
+func (c *Cache) pruneEntries() error {
+	entries, err := os.ReadDir(c.entryDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, entryExt) || c.used[strings.TrimSuffix(name, entryExt)] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.entryDir(), name)); err != nil {
+			return err
+		}
+	}
+	// 全てのエントリをキャッシュから読み込んだ場合もディレクトリの更新日時で最後に使われた日時が分かるようにする
+	now := time.Now()
+	return os.Chtimes(c.entryDir(), now, now)
+}
+
+// pruneStaleSalts は最後に使われてからstaleAge以上経過した他のソルトのサブディレクトリを削除する
+// ソルトの形式（SHA-256の16進文字列）ではないファイル・ディレクトリは削除しない
+func (c *Cache) pruneStaleSalts() error {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || name == c.salt || !isHash(name) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < staleAge {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(c.dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stats はこの実行でのヒット数とミス数を返す
+func (c *Cache) Stats() (hits, misses int64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+// entryDir はこのソルトのエントリを保存するディレクトリを返す
+func (c *Cache) entryDir() string {
+	return filepath.Join(c.dir, c.salt)
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.entryDir(), key+entryExt)
+}
+
+func (c *Cache) markUsed(key string) {
+	c.mu.Lock()
+	c.used[key] = true
+	c.mu.Unlock()
+}
+
+// Hash はpartsを区切り文字で連結した値のSHA-256を16進文字列で返す
+func Hash(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// isHash はnameがHashの返す形式（SHA-256の16進文字列）かどうかを返す
+func isHash(name string) bool {
+	if len(name) != hex.EncodedLen(sha256.Size) {
+		return false
+	}
+	_, err := hex.DecodeString(name)
+	return err == nil
+}
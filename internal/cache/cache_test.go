@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type entry struct {
+	Name  string
+	Items []string
+}
+
+func TestCache_StoreAndLoad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	c := New(dir, "v1")
+	key := c.Key("file", "a.go", "hash")
+
+	var got entry
+	if c.Load(key, &got) {
+		t.Fatal("Load() returned true before Store()")
+	}
+	want := entry{Name: "a", Items: []string{}}
+	if err := c.Store(key, want); err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+	if !c.Has(key) {
+		t.Error("Has() = false after Store()")
+	}
+
+	// 別インスタンスからも読み込める
+	other := New(dir, "v1")
+	if !other.Load(key, &got) {
+		t.Fatal("Load() returned false after Store()")
+	}
+	if got.Name != want.Name || got.Items == nil || len(got.Items) != 0 {
+		t.Errorf("Load() = %+v, expected %+v", got, want)
+	}
+	if hits, misses := c.Stats(); hits != 0 || misses != 1 {
+		t.Errorf("Stats() = (%d, %d), expected (0, 1)", hits, misses)
+	}
+	if hits, misses := other.Stats(); hits != 1 || misses != 0 {
+		t.Errorf("Stats() = (%d, %d), expected (1, 0)", hits, misses)
+	}
+}
+
+func TestCache_KeyDependsOnSettings(t *testing.T) {
+	dir := t.TempDir()
+	base := New(dir, "v1", "type-check=false").Key("file", "a.go", "hash")
+
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{"version", New(dir, "v2", "type-check=false").Key("file", "a.go", "hash")},
+		{"settings", New(dir, "v1", "type-check=true").Key("file", "a.go", "hash")},
+		{"content", New(dir, "v1", "type-check=false").Key("file", "a.go", "other")},
+		{"boundaries", New(dir, "v1", "type-check=false").Key("file", "a.gohash")},
+	}
+	for _, tt := range tests {
+		if tt.key == base {
+			t.Errorf("%s の変更でキーが変わりません", tt.name)
+		}
+	}
+	if again := New(dir, "v1", "type-check=false").Key("file", "a.go", "hash"); again != base {
+		t.Error("同じ設定・要素からのキーが一致しません")
+	}
+}
+
+func TestCache_Prune(t *testing.T) {
+	dir := t.TempDir()
+	first := New(dir, "v1")
+	kept, stale := first.Key("kept"), first.Key("stale")
+	for _, key := range []string{kept, stale} {
+		if err := first.Store(key, entry{Name: key}); err != nil {
+			t.Fatalf("Store() error: %v", err)
+		}
+	}
+	other := filepath.Join(dir, "README")
+	if err := os.WriteFile(other, []byte("not an entry"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 次の実行ではkeptだけを使う
+	second := New(dir, "v1")
+	var got entry
+	second.Load(kept, &got)
+	if err := second.Prune(); err != nil {
+		t.Fatalf("Prune() error: %v", err)
+	}
+
+	if !second.Has(kept) {
+		t.Error("使用したエントリが削除されています")
+	}
+	if second.Has(stale) {
+		t.Error("使用しなかったエントリが残っています")
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Error("エントリ以外のファイルが削除されています")
+	}
+}
+
+func TestCache_PruneOtherSettings(t *testing.T) {
+	dir := t.TempDir()
+	recent, old := New(dir, "type-check=true"), New(dir, "v0")
+	recentKey, oldKey := recent.Key("file"), old.Key("file")
+	if err := recent.Store(recentKey, entry{Name: "recent"}); err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+	if err := old.Store(oldKey, entry{Name: "old"}); err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+	stale := time.Now().Add(-2 * staleAge)
+	if err := os.Chtimes(old.entryDir(), stale, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	// 別の解析設定での実行は、最近使われた他の設定のエントリを削除しない
+	current := New(dir, "type-check=false")
+	if err := current.Store(current.Key("file"), entry{Name: "current"}); err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+	if err := current.Prune(); err != nil {
+		t.Fatalf("Prune() error: %v", err)
+	}
+
+	if !recent.Has(recentKey) {
+		t.Error("最近使われた他の解析設定のエントリが削除されています")
+	}
+	if old.Has(oldKey) {
+		t.Error("長期間使われていない他の解析設定のエントリが残っています")
+	}
+	if !current.Has(current.Key("file")) {
+		t.Error("使用したエントリが削除されています")
+	}
+}
+
+func TestCache_PruneMissingDir(t *testing.T) {
+	c := New(filepath.Join(t.TempDir(), "missing"), "v1")
+	if err := c.Prune(); err != nil {
+		t.Errorf("Prune() error: %v", err)
+	}
+}
@@ -1,6 +1,13 @@
 package graph
 
 import (
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
 	"github.com/harakeishi/depsee/internal/analyzer"
 	"github.com/harakeishi/depsee/internal/logger"
 	"github.com/harakeishi/depsee/internal/types"
@@ -13,30 +20,131 @@ const (
 	NodeInterface
 	NodeFunc
 	NodePackage
+	NodeMethod
+	NodeDefinedType    // 基本型などを基底とする定義型（type Status int）
+	NodeFuncType       // 関数型（type HandlerFunc func(...)）
+	NodeCollectionType // スライス・配列・マップ・チャネル型（type IDs []ID）
+	NodeAlias          // 型エイリアス（type X = Y）
+	NodeVar            // パッケージレベルの変数
+	NodeConst          // パッケージレベルの定数
+	NodeInit           // init関数（パッケージごとに1つ）
+	NodeExternal       // サードパーティモジュール（モジュールごとに1つにまとめた外部依存ノード）
+	NodeStdlib         // 標準ライブラリのパッケージ（外部依存ノード）
+	NodeGenerated      // パッケージ内の生成コードのノードを1つにまとめたノード
+	NodeGoroutine      // goroutineとして起動される関数リテラル（GoroutineNodesオプション指定時のみ）
 )
 
 type Node struct {
-	ID      types.NodeID
-	Kind    NodeKind
-	Name    string
-	Package string
+	ID          types.NodeID
+	Kind        NodeKind
+	Name        string       // 表示名（メソッドの場合は "Type.Method"）
+	Package     string       // パッケージの識別子（importパス、不明な場合はパッケージ名）
+	PackageName string       // 表示用の短いパッケージ名
+	Module      string       // 所属モジュールのパス（不明な場合や外部依存ノードの場合は空文字）
+	Platforms   []string     // ノードが宣言されているプラットフォーム（複数のプラットフォームを解析し、一部にのみ存在する場合のみ設定）
+	Test        bool         // テストファイル（_test.go）でのみ宣言されているテストのノードかどうか
+	Generated   bool         // 生成コード（"// Code generated ... DO NOT EDIT."）のファイルでのみ宣言されているノードかどうか
+	Receiver    types.NodeID // メソッドの場合のレシーバ型ノードID
+	SpawnedAt   string       // goroutineノードの場合の起動箇所（"ファイル名:行"）
+}
+
+// DisplayPackage は表示用のパッケージ名を返す
+func (n *Node) DisplayPackage() string {
+	if n.PackageName != "" {
+		return n.PackageName
+	}
+	return n.Package
 }
 
+// IsExternal は解析対象外のモジュール・標準ライブラリを表す外部依存ノードかどうかを返す
+func (n *Node) IsExternal() bool {
+	return n.Kind == NodeExternal || n.Kind == NodeStdlib
+}
+
+// Edge は2つのノード間の依存関係を表す
+// 同じ2ノード間の依存は1本のエッジにまとめられ、依存の種類・出現回数・出現位置を保持する
 type Edge struct {
-	From types.NodeID
-	To   types.NodeID
-	// 依存の種類（フィールド/シグネチャ/本体/実装）も必要に応じて
+	From      types.NodeID
+	To        types.NodeID
+	Kinds     map[types.DependencyType]int // エッジを構成する依存の種類ごとの出現回数
+	Count     int                          // 依存の出現回数（種類を記録せずに追加されたものを含む）
+	Positions []token.Position             // 依存を生じさせているソース上の位置（出現順）
+	Platforms []string                     // エッジが存在するプラットフォーム（複数のプラットフォームを解析し、一部にのみ存在する場合のみ設定）
+}
+
+func newEdge(from, to types.NodeID) *Edge {
+	return &Edge{
+		From:  from,
+		To:    to,
+		Kinds: make(map[types.DependencyType]int),
+	}
+}
+
+// DependencyTypes はエッジを構成する依存の種類を昇順で返す
+// 種類を記録せずに追加されたエッジの場合は空を返す
+func (e *Edge) DependencyTypes() []types.DependencyType {
+	var depTypes []types.DependencyType
+	for depType := range e.Kinds {
+		depTypes = append(depTypes, depType)
+	}
+	sort.Slice(depTypes, func(i, j int) bool { return depTypes[i] < depTypes[j] })
+	return depTypes
+}
+
+// IsImplementationOnly はエッジがインターフェース実装関係のみから構成されるかを判定する
+func (e *Edge) IsImplementationOnly() bool {
+	_, ok := e.Kinds[types.ImplementationDependency]
+	return ok && len(e.Kinds) == 1
+}
+
+// Label はエッジを構成する依存の種類と出現回数を "field,body_call x3" の形式で返す
+// 一部のプラットフォームにのみ存在するエッジは "field x1 [linux/amd64, darwin/arm64]" のようにプラットフォームを付ける
+func (e *Edge) Label() string {
+	var kinds []string
+	for _, depType := range e.DependencyTypes() {
+		kinds = append(kinds, depType.String())
+	}
+	label := strings.Join(kinds, ",")
+	if label == "" {
+		label = "unknown"
+	}
+	label = fmt.Sprintf("%s x%d", label, e.Count)
+	if len(e.Platforms) > 0 {
+		label += fmt.Sprintf(" [%s]", strings.Join(e.Platforms, ", "))
+	}
+	return label
+}
+
+// merge は他のエッジの種類・出現回数・出現位置・プラットフォームをこのエッジに取り込む
+func (e *Edge) merge(other *Edge) {
+	if e.Count == 0 {
+		e.Platforms = other.Platforms
+	} else if len(e.Platforms) > 0 && len(other.Platforms) > 0 {
+		// どちらかが全てのプラットフォームに存在する場合は、まとめたエッジも全てのプラットフォームに存在する
+		for _, p := range other.Platforms {
+			if !slices.Contains(e.Platforms, p) {
+				e.Platforms = append(e.Platforms, p)
+			}
+		}
+	} else {
+		e.Platforms = nil
+	}
+	for depType, count := range other.Kinds {
+		e.Kinds[depType] += count
+	}
+	e.Count += other.Count
+	e.Positions = append(e.Positions, other.Positions...)
 }
 
 type DependencyGraph struct {
 	Nodes map[types.NodeID]*Node
-	Edges map[types.NodeID]map[types.NodeID]struct{} // From→Toの多重辺排除
+	Edges map[types.NodeID]map[types.NodeID]*Edge // From→Toの多重辺は1本のエッジにまとめる
 }
 
 func NewDependencyGraph() *DependencyGraph {
 	return &DependencyGraph{
 		Nodes: make(map[types.NodeID]*Node),
-		Edges: make(map[types.NodeID]map[types.NodeID]struct{}),
+		Edges: make(map[types.NodeID]map[types.NodeID]*Edge),
 	}
 }
 
@@ -44,11 +152,110 @@ func (g *DependencyGraph) AddNode(node *Node) {
 	g.Nodes[node.ID] = node
 }
 
-func (g *DependencyGraph) AddEdge(from, to types.NodeID) {
+// edge はFrom→Toのエッジを返す。存在しない場合は作成する
+func (g *DependencyGraph) edge(from, to types.NodeID) *Edge {
 	if g.Edges[from] == nil {
-		g.Edges[from] = make(map[types.NodeID]struct{})
+		g.Edges[from] = make(map[types.NodeID]*Edge)
+	}
+	e, ok := g.Edges[from][to]
+	if !ok {
+		e = newEdge(from, to)
+		g.Edges[from][to] = e
+	}
+	return e
+}
+
+// AddEdge は依存の種類を記録せずにエッジを追加する（出現回数は1増える）
+func (g *DependencyGraph) AddEdge(from, to types.NodeID) {
+	g.edge(from, to).Count++
+}
+
+// AddTypedEdge は依存の種類を記録しながらエッジを追加する
+func (g *DependencyGraph) AddTypedEdge(from, to types.NodeID, depType types.DependencyType) {
+	e := g.edge(from, to)
+	e.Kinds[depType]++
+	e.Count++
+}
+
+// AddDependency は依存関係情報をエッジとして追加する
+// 依存の種類に加え、位置が分かる場合は出現位置も記録する
+func (g *DependencyGraph) AddDependency(dep types.DependencyInfo) {
+	g.AddTypedEdge(dep.From, dep.To, dep.Type)
+	if dep.Position.IsValid() {
+		e := g.Edges[dep.From][dep.To]
+		e.Positions = append(e.Positions, dep.Position)
+	}
+}
+
+// Edge はFrom→Toのエッジを返す。存在しない場合はnilを返す
+func (g *DependencyGraph) Edge(from, to types.NodeID) *Edge {
+	return g.Edges[from][to]
+}
+
+// SortedNodeIDs はノードのIDを昇順で返す
+// 出力の順序がマップの反復順序に依存しないようにするために使う
+func (g *DependencyGraph) SortedNodeIDs() []types.NodeID {
+	ids := make([]types.NodeID, 0, len(g.Nodes))
+	for id := range g.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// SortedEdges は全てのエッジを依存元・依存先のIDの昇順で返す
+func (g *DependencyGraph) SortedEdges() []*Edge {
+	var edges []*Edge
+	for _, tos := range g.Edges {
+		for _, e := range tos {
+			edges = append(edges, e)
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+	return edges
+}
+
+// EdgeDependencyTypes はエッジを構成する依存の種類を昇順で返す
+// 種類を記録せずに追加されたエッジの場合は空を返す
+func (g *DependencyGraph) EdgeDependencyTypes(from, to types.NodeID) []types.DependencyType {
+	if e := g.Edge(from, to); e != nil {
+		return e.DependencyTypes()
+	}
+	return nil
+}
+
+// IsImplementationOnly はエッジがインターフェース実装関係のみから構成されるかを判定する
+func (g *DependencyGraph) IsImplementationOnly(from, to types.NodeID) bool {
+	e := g.Edge(from, to)
+	return e != nil && e.IsImplementationOnly()
+}
+
+// UntestedNodes はテストのノードから直接参照されていない本番コードのノードのIDを昇順で返す
+// パッケージノードと外部依存ノードは対象外
+func (g *DependencyGraph) UntestedNodes() []types.NodeID {
+	referenced := make(map[types.NodeID]bool)
+	for from, tos := range g.Edges {
+		if n := g.Nodes[from]; n == nil || !n.Test {
+			continue
+		}
+		for to := range tos {
+			referenced[to] = true
+		}
 	}
-	g.Edges[from][to] = struct{}{}
+	var untested []types.NodeID
+	for id, n := range g.Nodes {
+		if n.Test || n.IsExternal() || n.Kind == NodePackage || referenced[id] {
+			continue
+		}
+		untested = append(untested, id)
+	}
+	sort.Slice(untested, func(i, j int) bool { return untested[i] < untested[j] })
+	return untested
 }
 
 // BuildDependencyGraph: 静的解析結果から依存グラフを構築
@@ -61,9 +268,12 @@ func BuildDependencyGraph(result *analyzer.Result) *DependencyGraph {
 	registerNodes(result, g)
 
 	// 依存関係情報からエッジを構築
-	for _, dep := range result.Dependencies {
-		g.AddEdge(dep.From, dep.To)
-	}
+	addDependencies(result, g)
+	registerExternalNodes(g)
+	assignModules(result, g)
+	assignPlatforms(result, g)
+	assignTests(result, g)
+	assignGenerated(result, g)
 
 	logger.Info("依存グラフ構築完了", "nodes", len(g.Nodes), "edges", countEdges(g))
 	return g
@@ -80,25 +290,130 @@ func BuildDependencyGraphWithPackages(result *analyzer.Result, targetDir string)
 
 	// パッケージノードを追加
 	for _, pkg := range result.Packages {
-		nodeID := types.NewPackageNodeID(pkg.Name)
+		packageID := types.PackageID(pkg.Path, pkg.Name)
 		node := &Node{
-			ID:      nodeID,
-			Kind:    NodePackage,
-			Name:    pkg.Name,
-			Package: pkg.Name,
+			ID:          types.NewPackageNodeID(packageID),
+			Kind:        NodePackage,
+			Name:        pkg.Name,
+			Package:     packageID,
+			PackageName: pkg.Name,
 		}
 		g.AddNode(node)
 	}
 
 	// 依存関係情報からエッジを構築
-	for _, dep := range result.Dependencies {
-		g.AddEdge(dep.From, dep.To)
-	}
+	addDependencies(result, g)
+	registerExternalNodes(g)
+	assignModules(result, g)
+	assignPlatforms(result, g)
+	assignTests(result, g)
+	assignGenerated(result, g)
 
 	logger.Info("パッケージ間依存関係を含む依存グラフ構築完了", "nodes", len(g.Nodes), "edges", countEdges(g))
 	return g
 }
 
+// CollapseMethods はメソッドノードをレシーバ型ノードに畳み込む
+// メソッドに出入りするエッジはレシーバ型ノードに付け替え、自己ループは除外する
+// 付け替えにより重なったエッジは依存の種類・出現回数・出現位置を合算する
+// レシーバ型ノードがグラフに存在しないメソッドはそのまま残す
+func (g *DependencyGraph) CollapseMethods() {
+	owner := make(map[types.NodeID]types.NodeID)
+	for id, n := range g.Nodes {
+		if n.Kind != NodeMethod {
+			continue
+		}
+		if _, exists := g.Nodes[n.Receiver]; exists {
+			owner[id] = n.Receiver
+		}
+	}
+	if len(owner) == 0 {
+		return
+	}
+	g.redirect(owner)
+	logger.Debug("メソッドノード畳み込み完了", "methods", len(owner))
+}
+
+// CollapseGenerated は生成コードのノードをパッケージごとに1つのノードに畳み込む
+// 畳み込んだノードに出入りするエッジはまとめたノードに付け替え、自己ループは除外する
+// テストのノードはテストのレイヤーとして表示するため対象外
+func (g *DependencyGraph) CollapseGenerated() {
+	owner := make(map[types.NodeID]types.NodeID)
+	counts := make(map[types.NodeID]int)
+	for id, n := range g.Nodes {
+		if !n.Generated || n.Test || n.Kind == NodePackage || n.Kind == NodeGenerated || n.IsExternal() {
+			continue
+		}
+		collapsed := types.NewGeneratedNodeID(n.Package)
+		if _, exists := g.Nodes[collapsed]; !exists {
+			g.AddNode(&Node{
+				ID:          collapsed,
+				Kind:        NodeGenerated,
+				Package:     n.Package,
+				PackageName: n.PackageName,
+				Module:      n.Module,
+				Generated:   true,
+			})
+		}
+		owner[id] = collapsed
+		counts[collapsed]++
+	}
+	if len(owner) == 0 {
+		return
+	}
+	for id, count := range counts {
+		g.Nodes[id].Name = fmt.Sprintf("%d宣言", count)
+	}
+	g.redirect(owner)
+	logger.Debug("生成コードのノード畳み込み完了", "nodes", len(owner), "packages", len(counts))
+}
+
+// redirect はownerに含まれるノードに出入りするエッジを付け替え先のノードに付け替えてから、元のノードを削除する
+// 自己ループは除外し、付け替えにより重なったエッジは依存の種類・出現回数・出現位置を合算する
+func (g *DependencyGraph) redirect(owner map[types.NodeID]types.NodeID) {
+	resolve := func(id types.NodeID) types.NodeID {
+		if r, ok := owner[id]; ok {
+			return r
+		}
+		return id
+	}
+
+	edges := g.Edges
+	g.Edges = make(map[types.NodeID]map[types.NodeID]*Edge)
+	for from, tos := range edges {
+		for to, e := range tos {
+			newFrom, newTo := resolve(from), resolve(to)
+			if newFrom == newTo {
+				continue
+			}
+			g.edge(newFrom, newTo).merge(e)
+		}
+	}
+	for _, tos := range g.Edges {
+		for _, e := range tos {
+			sortPositions(e.Positions)
+		}
+	}
+
+	for id := range owner {
+		delete(g.Nodes, id)
+	}
+}
+
+// sortPositions は出現位置をファイル・行・列の順に並べ替える
+func sortPositions(positions []token.Position) {
+	sort.Slice(positions, func(i, j int) bool {
+		a, b := positions[i], positions[j]
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
+
 // countEdges はエッジ数をカウント
 func countEdges(g *DependencyGraph) int {
 	count := 0
@@ -112,37 +427,368 @@ func countEdges(g *DependencyGraph) int {
 func registerNodes(result *analyzer.Result, g *DependencyGraph) {
 	// 構造体ノード登録
 	for _, s := range result.Structs {
-		id := types.NewNodeID(s.Package, s.Name)
 		node := &Node{
-			ID:      id,
-			Kind:    NodeStruct,
-			Name:    s.Name,
-			Package: s.Package,
+			ID:          s.NodeID(),
+			Kind:        NodeStruct,
+			Name:        s.Name,
+			Package:     types.PackageID(s.PackagePath, s.Package),
+			PackageName: s.Package,
 		}
 		g.AddNode(node)
 	}
 
 	// インターフェースノード登録
 	for _, i := range result.Interfaces {
-		id := types.NewNodeID(i.Package, i.Name)
 		node := &Node{
-			ID:      id,
-			Kind:    NodeInterface,
-			Name:    i.Name,
-			Package: i.Package,
+			ID:          i.NodeID(),
+			Kind:        NodeInterface,
+			Name:        i.Name,
+			Package:     types.PackageID(i.PackagePath, i.Package),
+			PackageName: i.Package,
+		}
+		g.AddNode(node)
+	}
+
+	// 名前付き型ノード登録
+	for _, t := range result.Types {
+		node := &Node{
+			ID:          t.NodeID(),
+			Kind:        nodeKindOf(t.Kind),
+			Name:        t.Name,
+			Package:     types.PackageID(t.PackagePath, t.Package),
+			PackageName: t.Package,
 		}
 		g.AddNode(node)
 	}
 
+	// メソッドノード登録
+	for _, m := range result.Methods {
+		node := &Node{
+			ID:          m.NodeID(),
+			Kind:        NodeMethod,
+			Name:        m.Receiver + "." + m.Name,
+			Package:     types.PackageID(m.PackagePath, m.Package),
+			PackageName: m.Package,
+			Receiver:    m.ReceiverNodeID(),
+		}
+		g.AddNode(node)
+	}
+
+	// init関数ノード登録（同一パッケージの複数のinit関数は同じノードになる）
+	for _, f := range result.Inits {
+		node := &Node{
+			ID:          f.NodeID(),
+			Kind:        NodeInit,
+			Name:        f.Name,
+			Package:     types.PackageID(f.PackagePath, f.Package),
+			PackageName: f.Package,
+		}
+		g.AddNode(node)
+	}
+
+	// 変数・定数ノード登録
+	for _, v := range result.Values {
+		kind := NodeVar
+		if v.Kind == types.ConstKind {
+			kind = NodeConst
+		}
+		node := &Node{
+			ID:          v.NodeID(),
+			Kind:        kind,
+			Name:        v.Name,
+			Package:     types.PackageID(v.PackagePath, v.Package),
+			PackageName: v.Package,
+		}
+		g.AddNode(node)
+	}
+
+	// インターフェースメソッドノード登録
+	for _, i := range result.Interfaces {
+		for _, m := range i.Methods {
+			node := &Node{
+				ID:          m.NodeID(),
+				Kind:        NodeMethod,
+				Name:        m.Receiver + "." + m.Name,
+				Package:     types.PackageID(m.PackagePath, m.Package),
+				PackageName: m.Package,
+				Receiver:    m.ReceiverNodeID(),
+			}
+			g.AddNode(node)
+		}
+	}
+
 	// 関数ノード登録
 	for _, f := range result.Functions {
-		id := types.NewNodeID(f.Package, f.Name)
 		node := &Node{
-			ID:      id,
-			Kind:    NodeFunc,
-			Name:    f.Name,
-			Package: f.Package,
+			ID:          f.NodeID(),
+			Kind:        NodeFunc,
+			Name:        f.Name,
+			Package:     types.PackageID(f.PackagePath, f.Package),
+			PackageName: f.Package,
+		}
+		g.AddNode(node)
+	}
+
+	// goroutineノード登録（起動元とは起動箇所を示すspawnのエッジでつながる）
+	for _, gr := range result.Goroutines {
+		node := &Node{
+			ID:          gr.NodeID(),
+			Kind:        NodeGoroutine,
+			Name:        gr.Name,
+			Package:     types.PackageID(gr.PackagePath, gr.Package),
+			PackageName: gr.Package,
+			SpawnedAt:   fmt.Sprintf("%s:%d", filepath.Base(gr.Position.Filename), gr.Position.Line),
 		}
 		g.AddNode(node)
 	}
 }
+
+// assignModules は各ノードに所属パッケージのモジュールを設定する
+// モジュールは解析結果のパッケージ情報から求めるため、go.workで複数のモジュールを解析した場合もパッケージごとに正しく設定される
+func assignModules(result *analyzer.Result, g *DependencyGraph) {
+	modules := make(map[string]string)
+	for _, pkg := range result.Packages {
+		if pkg.Module != "" {
+			modules[types.PackageID(pkg.Path, pkg.Name)] = pkg.Module
+		}
+	}
+	for _, n := range g.Nodes {
+		if !n.IsExternal() {
+			n.Module = modules[n.Package]
+		}
+	}
+}
+
+// addDependencies は依存関係情報をエッジとして追加する
+// 複数のプラットフォームを解析した場合、プラットフォームごとのファイル（foo_linux.go と foo_windows.go 等）に宣言された
+// 同じ宣言のコピーから生じる依存は重複して数えず、出現回数が最も多いプラットフォームのコピーのみを数える
+// 他のプラットフォームのコピーは出現位置のみ記録し、一部のプラットフォームにのみ存在するエッジにはそのプラットフォームを設定する
+func addDependencies(result *analyzer.Result, g *DependencyGraph) {
+	if len(result.Platforms) == 0 {
+		for _, dep := range result.Dependencies {
+			g.AddDependency(dep)
+		}
+		return
+	}
+
+	// 依存の位置するファイルがビルド対象となるプラットフォーム（位置が不明な場合は全てのプラットフォーム）
+	platformsOf := func(dep types.DependencyInfo) []string {
+		if platforms, ok := result.FilePlatforms[dep.Position.Filename]; ok {
+			return platforms
+		}
+		return result.Platforms
+	}
+
+	type key struct {
+		from, to types.NodeID
+		depType  types.DependencyType
+	}
+	var keys []key
+	groups := make(map[key][]types.DependencyInfo)
+	for _, dep := range result.Dependencies {
+		k := key{dep.From, dep.To, dep.Type}
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], dep)
+	}
+
+	edgePlatforms := make(map[*Edge]map[string]bool)
+	for _, k := range keys {
+		deps := groups[k]
+		counts := make(map[string]int)
+		for _, dep := range deps {
+			for _, p := range platformsOf(dep) {
+				counts[p]++
+			}
+		}
+		best := result.Platforms[0]
+		for _, p := range result.Platforms {
+			if counts[p] > counts[best] {
+				best = p
+			}
+		}
+
+		for _, dep := range deps {
+			platforms := platformsOf(dep)
+			if slices.Contains(platforms, best) {
+				g.AddDependency(dep)
+			} else if dep.Position.IsValid() {
+				e := g.edge(dep.From, dep.To)
+				e.Positions = append(e.Positions, dep.Position)
+			}
+			e := g.edge(dep.From, dep.To)
+			if edgePlatforms[e] == nil {
+				edgePlatforms[e] = make(map[string]bool)
+			}
+			for _, p := range platforms {
+				edgePlatforms[e][p] = true
+			}
+		}
+	}
+
+	for e, platforms := range edgePlatforms {
+		if len(platforms) == len(result.Platforms) {
+			continue
+		}
+		for _, p := range result.Platforms {
+			if platforms[p] {
+				e.Platforms = append(e.Platforms, p)
+			}
+		}
+	}
+}
+
+// assignPlatforms は一部のプラットフォームでのみ宣言されているノードに、そのプラットフォームの一覧を設定する
+// 同じノードが複数のファイル（foo_linux.go と foo_windows.go 等）で宣言されている場合は、それらのプラットフォームを合わせて判定する
+func assignPlatforms(result *analyzer.Result, g *DependencyGraph) {
+	if len(result.Platforms) == 0 {
+		return
+	}
+	declared := make(map[types.NodeID]map[string]bool) // ノードID -> 宣言されているプラットフォーム
+	everywhere := make(map[types.NodeID]bool)          // 全てのプラットフォームでビルドされるファイルで宣言されているノード
+	declare := func(id types.NodeID, file string) {
+		platforms, ok := result.FilePlatforms[file]
+		if !ok {
+			everywhere[id] = true
+			return
+		}
+		if declared[id] == nil {
+			declared[id] = make(map[string]bool)
+		}
+		for _, p := range platforms {
+			declared[id][p] = true
+		}
+	}
+	forEachDeclaration(result, declare)
+
+	for id, platforms := range declared {
+		node, exists := g.Nodes[id]
+		if !exists || everywhere[id] || len(platforms) == len(result.Platforms) {
+			continue
+		}
+		node.Platforms = nil
+		for _, p := range result.Platforms {
+			if platforms[p] {
+				node.Platforms = append(node.Platforms, p)
+			}
+		}
+	}
+}
+
+// assignTests はテストファイル（_test.go）でのみ宣言されているノードをテストのノードとする
+// 外部テストパッケージ（pkg_test）のパッケージノードもテストのノードになる
+func assignTests(result *analyzer.Result, g *DependencyGraph) {
+	isTest := func(file string) bool { return strings.HasSuffix(file, "_test.go") }
+	production := make(map[types.NodeID]bool) // テスト以外のファイルでも宣言されているノード
+	tests := make(map[types.NodeID]bool)
+	classify := func(id types.NodeID, file string) {
+		if isTest(file) {
+			tests[id] = true
+		} else {
+			production[id] = true
+		}
+	}
+	forEachDeclaration(result, classify)
+	for _, pkg := range result.Packages {
+		classify(types.NewPackageNodeID(types.PackageID(pkg.Path, pkg.Name)), pkg.File)
+	}
+	for id := range tests {
+		if node, exists := g.Nodes[id]; exists && !production[id] {
+			node.Test = true
+		}
+	}
+}
+
+// assignGenerated は生成コードのファイルでのみ宣言されているノードを生成コードのノードとする
+// パッケージノードは手書きのファイルを含む場合があるため対象外
+func assignGenerated(result *analyzer.Result, g *DependencyGraph) {
+	if len(result.GeneratedFiles) == 0 {
+		return
+	}
+	generatedFiles := make(map[string]bool, len(result.GeneratedFiles))
+	for _, file := range result.GeneratedFiles {
+		generatedFiles[file] = true
+	}
+	handwritten := make(map[types.NodeID]bool) // 生成コード以外のファイルでも宣言されているノード
+	generated := make(map[types.NodeID]bool)
+	forEachDeclaration(result, func(id types.NodeID, file string) {
+		if generatedFiles[file] {
+			generated[id] = true
+		} else {
+			handwritten[id] = true
+		}
+	})
+	for id := range generated {
+		if node, exists := g.Nodes[id]; exists && !handwritten[id] {
+			node.Generated = true
+		}
+	}
+}
+
+// forEachDeclaration は解析結果の全ての宣言について、ノードIDと宣言されているファイルを渡してfnを呼び出す
+func forEachDeclaration(result *analyzer.Result, fn func(id types.NodeID, file string)) {
+	for _, s := range result.Structs {
+		fn(s.NodeID(), s.File)
+	}
+	for _, i := range result.Interfaces {
+		fn(i.NodeID(), i.File)
+		for _, m := range i.Methods {
+			fn(m.NodeID(), i.File)
+		}
+	}
+	for _, t := range result.Types {
+		fn(t.NodeID(), t.File)
+	}
+	for _, v := range result.Values {
+		fn(v.NodeID(), v.File)
+	}
+	for _, gr := range result.Goroutines {
+		fn(gr.NodeID(), gr.File)
+	}
+	for _, funcs := range [][]analyzer.FuncInfo{result.Functions, result.Methods, result.Inits} {
+		for _, f := range funcs {
+			fn(f.NodeID(), f.File)
+		}
+	}
+}
+
+// registerExternalNodes は外部依存ノードへのエッジの依存先をノードとして登録する
+// 外部依存ノードは解析結果に宣言を持たないため、依存関係の依存先のIDから作成する
+func registerExternalNodes(g *DependencyGraph) {
+	for _, tos := range g.Edges {
+		for to := range tos {
+			if _, exists := g.Nodes[to]; exists {
+				continue
+			}
+			kind := NodeExternal
+			if to.IsStdlibNode() {
+				kind = NodeStdlib
+			} else if !to.IsExternalNode() {
+				continue
+			}
+			path := to.ExternalPath()
+			g.AddNode(&Node{
+				ID:          to,
+				Kind:        kind,
+				Name:        path,
+				Package:     path,
+				PackageName: path,
+			})
+		}
+	}
+}
+
+// nodeKindOf は名前付き型の種類に対応するノード種別を返す
+func nodeKindOf(kind types.TypeKind) NodeKind {
+	switch kind {
+	case types.FuncTypeKind:
+		return NodeFuncType
+	case types.CollectionTypeKind:
+		return NodeCollectionType
+	case types.AliasTypeKind:
+		return NodeAlias
+	default:
+		return NodeDefinedType
+	}
+}
@@ -1,7 +1,9 @@
 package graph
 
 import (
+	"go/token"
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/harakeishi/depsee/internal/analyzer"
@@ -256,3 +258,615 @@ func TestCountEdges(t *testing.T) {
 		t.Errorf("Expected %d edges, got %d", expectedCount, edgeCount)
 	}
 }
+
+func TestRegisterNodes_SameNamedPackages(t *testing.T) {
+	g := NewDependencyGraph()
+
+	result := &analyzer.Result{
+		Structs: []analyzer.StructInfo{
+			{Name: "Handler", Package: "handler", PackagePath: "example.com/app/api/handler", File: "api/handler/h.go"},
+			{Name: "Handler", Package: "handler", PackagePath: "example.com/app/web/handler", File: "web/handler/h.go"},
+		},
+	}
+
+	registerNodes(result, g)
+
+	if len(g.Nodes) != 2 {
+		t.Fatalf("Expected 2 nodes for same-named packages, got %d", len(g.Nodes))
+	}
+
+	node, exists := g.Nodes["example.com/app/api/handler.Handler"]
+	if !exists {
+		t.Fatal("Expected node 'example.com/app/api/handler.Handler' not found")
+	}
+	if node.Package != "example.com/app/api/handler" {
+		t.Errorf("Expected package path 'example.com/app/api/handler', got '%s'", node.Package)
+	}
+	if node.DisplayPackage() != "handler" {
+		t.Errorf("Expected display package 'handler', got '%s'", node.DisplayPackage())
+	}
+}
+
+func TestRegisterNodes_Methods(t *testing.T) {
+	g := NewDependencyGraph()
+
+	result := &analyzer.Result{
+		Structs: []analyzer.StructInfo{
+			{Name: "Repo", Package: "test", File: "test.go"},
+		},
+		Functions: []analyzer.FuncInfo{
+			{Name: "Get", Package: "test", File: "test.go"},
+		},
+		Methods: []analyzer.FuncInfo{
+			{Name: "Get", Package: "test", File: "test.go", Receiver: "Repo"},
+		},
+	}
+
+	registerNodes(result, g)
+
+	// 同名の関数とメソッドが別ノードになる
+	if len(g.Nodes) != 3 {
+		t.Fatalf("Expected 3 nodes, got %d", len(g.Nodes))
+	}
+
+	method, exists := g.Nodes["test.Repo.Get"]
+	if !exists {
+		t.Fatal("Expected method node 'test.Repo.Get' not found")
+	}
+	if method.Kind != NodeMethod {
+		t.Errorf("Expected kind NodeMethod, got %d", method.Kind)
+	}
+	if method.Receiver != "test.Repo" {
+		t.Errorf("Expected receiver 'test.Repo', got '%s'", method.Receiver)
+	}
+	if _, exists := g.Nodes["test.Get"]; !exists {
+		t.Error("Expected function node 'test.Get' not found")
+	}
+}
+
+func TestRegisterNodes_InterfaceMethods(t *testing.T) {
+	g := NewDependencyGraph()
+
+	result := &analyzer.Result{
+		Interfaces: []analyzer.InterfaceInfo{
+			{
+				Name:    "Repository",
+				Package: "test",
+				File:    "test.go",
+				Methods: []analyzer.FuncInfo{
+					{Name: "Save", Package: "test", File: "test.go", Receiver: "Repository"},
+				},
+			},
+		},
+	}
+
+	registerNodes(result, g)
+
+	// インターフェースメソッドはインターフェースをレシーバとするメソッドノードになる
+	method, exists := g.Nodes["test.Repository.Save"]
+	if !exists {
+		t.Fatal("Expected interface method node 'test.Repository.Save' not found")
+	}
+	if method.Kind != NodeMethod {
+		t.Errorf("Expected kind NodeMethod, got %d", method.Kind)
+	}
+	if method.Receiver != "test.Repository" {
+		t.Errorf("Expected receiver 'test.Repository', got '%s'", method.Receiver)
+	}
+}
+
+func TestRegisterNodes_Globals(t *testing.T) {
+	g := NewDependencyGraph()
+
+	result := &analyzer.Result{
+		Inits: []analyzer.FuncInfo{
+			{Name: "init", Package: "test", File: "a.go"},
+			{Name: "init", Package: "test", File: "b.go"},
+		},
+		Values: []analyzer.ValueInfo{
+			{Name: "DefaultClient", Package: "test", File: "a.go", Kind: types.VarKind},
+			{Name: "MaxRetry", Package: "test", File: "a.go", Kind: types.ConstKind},
+		},
+	}
+
+	registerNodes(result, g)
+
+	// 同一パッケージのinit関数は1つのノードにまとめられる
+	if len(g.Nodes) != 3 {
+		t.Fatalf("Expected 3 nodes, got %d", len(g.Nodes))
+	}
+	for id, kind := range map[types.NodeID]NodeKind{
+		"test.init":          NodeInit,
+		"test.DefaultClient": NodeVar,
+		"test.MaxRetry":      NodeConst,
+	} {
+		node, exists := g.Nodes[id]
+		if !exists {
+			t.Errorf("Expected node '%s' not found", id)
+			continue
+		}
+		if node.Kind != kind {
+			t.Errorf("Expected kind %d for '%s', got %d", kind, id, node.Kind)
+		}
+	}
+}
+
+func TestCollapseMethods(t *testing.T) {
+	g := NewDependencyGraph()
+	g.AddNode(&Node{ID: "test.Repo", Kind: NodeStruct, Name: "Repo", Package: "test"})
+	g.AddNode(&Node{ID: "test.User", Kind: NodeStruct, Name: "User", Package: "test"})
+	g.AddNode(&Node{ID: "test.Repo.Save", Kind: NodeMethod, Name: "Repo.Save", Package: "test", Receiver: "test.Repo"})
+	g.AddNode(&Node{ID: "test.Handle", Kind: NodeFunc, Name: "Handle", Package: "test"})
+
+	g.AddEdge("test.Repo.Save", "test.Repo") // レシーバ依存（自己ループになるため除外）
+	g.AddEdge("test.Repo.Save", "test.User")
+	g.AddEdge("test.Handle", "test.Repo.Save")
+
+	g.CollapseMethods()
+
+	if _, exists := g.Nodes["test.Repo.Save"]; exists {
+		t.Error("Method node should be removed after collapsing")
+	}
+	if _, exists := g.Edges["test.Repo"]["test.User"]; !exists {
+		t.Error("Expected edge test.Repo -> test.User")
+	}
+	if _, exists := g.Edges["test.Handle"]["test.Repo"]; !exists {
+		t.Error("Expected edge test.Handle -> test.Repo")
+	}
+	if _, exists := g.Edges["test.Repo"]["test.Repo"]; exists {
+		t.Error("Self loop should not be created")
+	}
+	if countEdges(g) != 2 {
+		t.Errorf("Expected 2 edges, got %d", countEdges(g))
+	}
+}
+
+func TestAddTypedEdge(t *testing.T) {
+	g := NewDependencyGraph()
+	g.AddTypedEdge("test.DB", "test.Repo", types.ImplementationDependency)
+	g.AddTypedEdge("test.Cache", "test.Repo", types.ImplementationDependency)
+	g.AddTypedEdge("test.Cache", "test.Repo", types.FieldDependency)
+
+	if countEdges(g) != 2 {
+		t.Errorf("Expected 2 edges, got %d", countEdges(g))
+	}
+	if !g.IsImplementationOnly("test.DB", "test.Repo") {
+		t.Error("Expected test.DB -> test.Repo to be implementation only")
+	}
+	if g.IsImplementationOnly("test.Cache", "test.Repo") {
+		t.Error("Expected test.Cache -> test.Repo to include field dependency")
+	}
+	depTypes := g.EdgeDependencyTypes("test.Cache", "test.Repo")
+	if len(depTypes) != 2 || depTypes[0] != types.FieldDependency || depTypes[1] != types.ImplementationDependency {
+		t.Errorf("Unexpected dependency types: %v", depTypes)
+	}
+}
+
+func TestAddDependency(t *testing.T) {
+	g := NewDependencyGraph()
+	g.AddDependency(types.DependencyInfo{From: "test.User", To: "test.Profile", Type: types.FieldDependency,
+		Position: token.Position{Filename: "user.go", Line: 5, Column: 10}})
+	g.AddDependency(types.DependencyInfo{From: "test.User", To: "test.Profile", Type: types.BodyCallDependency,
+		Position: token.Position{Filename: "user.go", Line: 12, Column: 2}})
+	g.AddDependency(types.DependencyInfo{From: "test.User", To: "test.Profile", Type: types.ImplementationDependency})
+
+	if countEdges(g) != 1 {
+		t.Errorf("Expected 1 edge, got %d", countEdges(g))
+	}
+	edge := g.Edge("test.User", "test.Profile")
+	if edge == nil {
+		t.Fatal("Expected edge test.User -> test.Profile")
+	}
+	if edge.Count != 3 {
+		t.Errorf("Expected count 3, got %d", edge.Count)
+	}
+	// 位置が不明な依存は出現回数にのみ数えられる
+	if len(edge.Positions) != 2 || edge.Positions[0].Line != 5 || edge.Positions[1].Line != 12 {
+		t.Errorf("Unexpected positions: %v", edge.Positions)
+	}
+	if label := edge.Label(); label != "field,body_call,implementation x3" {
+		t.Errorf("Unexpected label: %s", label)
+	}
+	if g.Edge("test.Profile", "test.User") != nil {
+		t.Error("Expected no edge test.Profile -> test.User")
+	}
+}
+
+func TestSortedNodesAndEdges(t *testing.T) {
+	g := NewDependencyGraph()
+	for _, id := range []types.NodeID{"b.Y", "a.X", "b.X"} {
+		g.AddNode(&Node{ID: id})
+	}
+	g.AddEdge("b.Y", "a.X")
+	g.AddEdge("a.X", "b.Y")
+	g.AddEdge("a.X", "b.X")
+
+	if ids := g.SortedNodeIDs(); !reflect.DeepEqual(ids, []types.NodeID{"a.X", "b.X", "b.Y"}) {
+		t.Errorf("SortedNodeIDs() = %v", ids)
+	}
+	var edges []string
+	for _, e := range g.SortedEdges() {
+		edges = append(edges, string(e.From)+"->"+string(e.To))
+	}
+	if expected := []string{"a.X->b.X", "a.X->b.Y", "b.Y->a.X"}; !reflect.DeepEqual(edges, expected) {
+		t.Errorf("SortedEdges() = %v, expected %v", edges, expected)
+	}
+}
+
+func TestCollapseMethods_MergesEdges(t *testing.T) {
+	g := NewDependencyGraph()
+	g.AddNode(&Node{ID: "test.Repo", Kind: NodeStruct, Name: "Repo", Package: "test"})
+	g.AddNode(&Node{ID: "test.User", Kind: NodeStruct, Name: "User", Package: "test"})
+	g.AddNode(&Node{ID: "test.Repo.Save", Kind: NodeMethod, Name: "Repo.Save", Package: "test", Receiver: "test.Repo"})
+	g.AddNode(&Node{ID: "test.Repo.Load", Kind: NodeMethod, Name: "Repo.Load", Package: "test", Receiver: "test.Repo"})
+
+	g.AddDependency(types.DependencyInfo{From: "test.Repo.Save", To: "test.User", Type: types.SignatureDependency,
+		Position: token.Position{Filename: "repo.go", Line: 20, Column: 1}})
+	g.AddDependency(types.DependencyInfo{From: "test.Repo.Load", To: "test.User", Type: types.BodyCallDependency,
+		Position: token.Position{Filename: "repo.go", Line: 10, Column: 1}})
+	g.AddDependency(types.DependencyInfo{From: "test.Repo", To: "test.User", Type: types.FieldDependency,
+		Position: token.Position{Filename: "repo.go", Line: 3, Column: 1}})
+
+	g.CollapseMethods()
+
+	edge := g.Edge("test.Repo", "test.User")
+	if edge == nil {
+		t.Fatal("Expected edge test.Repo -> test.User")
+	}
+	if edge.Count != 3 {
+		t.Errorf("Expected count 3, got %d", edge.Count)
+	}
+	if len(edge.Kinds) != 3 {
+		t.Errorf("Expected 3 dependency types, got %v", edge.DependencyTypes())
+	}
+	var lines []int
+	for _, pos := range edge.Positions {
+		lines = append(lines, pos.Line)
+	}
+	if len(lines) != 3 || lines[0] != 3 || lines[1] != 10 || lines[2] != 20 {
+		t.Errorf("Expected positions sorted by line, got %v", lines)
+	}
+}
+
+func TestBuildDependencyGraph_ExternalNodes(t *testing.T) {
+	cobra := types.NewExternalNodeID("github.com/spf13/cobra")
+	strings := types.NewStdlibNodeID("strings")
+	result := &analyzer.Result{
+		Functions: []analyzer.FuncInfo{{Name: "Run", Package: "cmd", File: "cmd.go"}},
+		Dependencies: []analyzer.DependencyInfo{
+			{From: "cmd.Run", To: cobra, Type: types.ExternalDependency},
+			{From: "cmd.Run", To: strings, Type: types.ExternalDependency},
+			{From: "cmd.Run", To: "cmd.Missing", Type: types.BodyCallDependency},
+		},
+	}
+
+	g := BuildDependencyGraph(result)
+
+	// 外部依存ノードは依存先のIDから作成され、解析対象外の通常ノードは作成されない
+	if len(g.Nodes) != 3 {
+		t.Fatalf("Expected 3 nodes, got %d", len(g.Nodes))
+	}
+	for id, kind := range map[types.NodeID]NodeKind{cobra: NodeExternal, strings: NodeStdlib} {
+		node, exists := g.Nodes[id]
+		if !exists {
+			t.Errorf("Expected node '%s' not found", id)
+			continue
+		}
+		if node.Kind != kind || !node.IsExternal() {
+			t.Errorf("Expected external kind %d for '%s', got %d", kind, id, node.Kind)
+		}
+		if node.Name != id.ExternalPath() || node.Package != id.ExternalPath() {
+			t.Errorf("Expected name and package %q for '%s', got %q and %q", id.ExternalPath(), id, node.Name, node.Package)
+		}
+	}
+}
+
+func TestBuildDependencyGraph_Modules(t *testing.T) {
+	result := &analyzer.Result{
+		Structs: []analyzer.StructInfo{
+			{Name: "Handler", Package: "example.com/api", File: "api/handler.go"},
+			{Name: "User", Package: "example.com/core/model", File: "core/model/user.go"},
+		},
+		Packages: []analyzer.PackageInfo{
+			{Name: "api", Path: "example.com/api", Module: "example.com/api"},
+			{Name: "model", Path: "example.com/core/model", Module: "example.com/core"},
+		},
+		Dependencies: []analyzer.DependencyInfo{
+			{From: "example.com/api.Handler", To: "example.com/core/model.User", Type: types.FieldDependency},
+		},
+	}
+
+	// パッケージ情報のモジュールが各ノードに設定される
+	for _, g := range []*DependencyGraph{BuildDependencyGraph(result), BuildDependencyGraphWithPackages(result, ".")} {
+		for id, module := range map[types.NodeID]string{
+			"example.com/api.Handler":     "example.com/api",
+			"example.com/core/model.User": "example.com/core",
+		} {
+			node, exists := g.Nodes[id]
+			if !exists {
+				t.Errorf("Expected node '%s' not found", id)
+				continue
+			}
+			if node.Module != module {
+				t.Errorf("Expected module %q for '%s', got %q", module, id, node.Module)
+			}
+		}
+	}
+}
+
+func TestBuildDependencyGraph_Platforms(t *testing.T) {
+	result := &analyzer.Result{
+		Structs: []analyzer.StructInfo{{Name: "File", Package: "app", File: "file.go"}},
+		Functions: []analyzer.FuncInfo{
+			{Name: "open", Package: "app", File: "file_linux.go"},
+			{Name: "open", Package: "app", File: "file_windows.go"},
+			{Name: "poll", Package: "app", File: "poll_unix.go"},
+			{Name: "wait", Package: "app", File: "poll_unix.go"},
+			{Name: "wait", Package: "app", File: "wait.go"},
+		},
+		Platforms: []string{"linux/amd64", "darwin/arm64", "windows/amd64"},
+		FilePlatforms: map[string][]string{
+			"file_linux.go":   {"linux/amd64"},
+			"file_windows.go": {"windows/amd64"},
+			"poll_unix.go":    {"darwin/arm64", "linux/amd64"},
+		},
+	}
+
+	g := BuildDependencyGraph(result)
+
+	// 宣言されているファイルのプラットフォームを合わせ、全てのプラットフォームに存在しないノードのみ設定する
+	expected := map[types.NodeID][]string{
+		"app.File": nil,
+		"app.open": {"linux/amd64", "windows/amd64"}, // linuxとwindowsで宣言されているがdarwinには存在しない
+		"app.poll": {"linux/amd64", "darwin/arm64"},
+		"app.wait": nil, // 全てのプラットフォームでビルドされるファイルでも宣言されている
+	}
+	for id, platforms := range expected {
+		node, exists := g.Nodes[id]
+		if !exists {
+			t.Errorf("Expected node '%s' not found", id)
+			continue
+		}
+		if !reflect.DeepEqual(node.Platforms, platforms) {
+			t.Errorf("Expected platforms %v for '%s', got %v", platforms, id, node.Platforms)
+		}
+	}
+
+	// 複数のプラットフォームを解析していない場合は設定しない
+	result.Platforms = nil
+	if node := BuildDependencyGraph(result).Nodes["app.poll"]; node.Platforms != nil {
+		t.Errorf("Expected no platforms, got %v", node.Platforms)
+	}
+}
+
+func TestBuildDependencyGraph_PlatformEdges(t *testing.T) {
+	at := func(file string, line int) token.Position {
+		return token.Position{Filename: file, Line: line, Column: 1}
+	}
+	result := &analyzer.Result{
+		Structs: []analyzer.StructInfo{{Name: "File", Package: "app", File: "file.go"}},
+		Functions: []analyzer.FuncInfo{
+			{Name: "open", Package: "app", File: "file_linux.go"},
+			{Name: "open", Package: "app", File: "file_windows.go"},
+			{Name: "poll", Package: "app", File: "poll_unix.go"},
+			{Name: "Read", Package: "app", File: "file.go"},
+		},
+		Dependencies: []analyzer.DependencyInfo{
+			// open は linux と windows のファイルでそれぞれ宣言されている
+			{From: "app.open", To: "app.File", Type: types.SignatureDependency, Position: at("file_linux.go", 3)},
+			{From: "app.open", To: "app.File", Type: types.SignatureDependency, Position: at("file_windows.go", 3)},
+			{From: "app.open", To: "app.poll", Type: types.BodyCallDependency, Position: at("file_linux.go", 4)},
+			{From: "app.open", To: "app.poll", Type: types.BodyCallDependency, Position: at("file_linux.go", 5)},
+			{From: "app.open", To: "app.poll", Type: types.BodyCallDependency, Position: at("file_windows.go", 4)},
+			{From: "app.poll", To: "app.File", Type: types.SignatureDependency, Position: at("poll_unix.go", 3)},
+			{From: "app.Read", To: "app.open", Type: types.BodyCallDependency, Position: at("file.go", 5)},
+		},
+		Platforms: []string{"linux/amd64", "darwin/arm64", "windows/amd64"},
+		FilePlatforms: map[string][]string{
+			"file_linux.go":   {"linux/amd64"},
+			"file_windows.go": {"windows/amd64"},
+			"poll_unix.go":    {"darwin/arm64", "linux/amd64"},
+		},
+	}
+
+	g := BuildDependencyGraph(result)
+
+	// プラットフォームごとの宣言のコピーから生じる依存は、出現回数が最も多いプラットフォームの分だけ数える
+	// 他のプラットフォームのコピーの出現位置も記録する
+	expected := []struct {
+		from, to  types.NodeID
+		count     int
+		positions int
+		platforms []string
+	}{
+		{"app.open", "app.File", 1, 2, []string{"linux/amd64", "windows/amd64"}},
+		{"app.open", "app.poll", 2, 3, []string{"linux/amd64", "windows/amd64"}},
+		{"app.poll", "app.File", 1, 1, []string{"linux/amd64", "darwin/arm64"}},
+		{"app.Read", "app.open", 1, 1, nil},
+	}
+	for _, e := range expected {
+		edge := g.Edge(e.from, e.to)
+		if edge == nil {
+			t.Errorf("Expected edge %s -> %s", e.from, e.to)
+			continue
+		}
+		if edge.Count != e.count || len(edge.Positions) != e.positions {
+			t.Errorf("Edge %s -> %s: expected count %d and %d positions, got %d and %d", e.from, e.to, e.count, e.positions, edge.Count, len(edge.Positions))
+		}
+		if !reflect.DeepEqual(edge.Platforms, e.platforms) {
+			t.Errorf("Edge %s -> %s: expected platforms %v, got %v", e.from, e.to, e.platforms, edge.Platforms)
+		}
+	}
+	if label := g.Edge("app.open", "app.File").Label(); label != "signature x1 [linux/amd64, windows/amd64]" {
+		t.Errorf("Unexpected label: %s", label)
+	}
+
+	// 複数のプラットフォームを解析していない場合は全ての依存を数える
+	result.Platforms = nil
+	if edge := BuildDependencyGraph(result).Edge("app.open", "app.File"); edge.Count != 2 || edge.Platforms != nil {
+		t.Errorf("Expected count 2 without platforms, got %d %v", edge.Count, edge.Platforms)
+	}
+}
+
+func TestBuildDependencyGraph_Tests(t *testing.T) {
+	result := &analyzer.Result{
+		Structs: []analyzer.StructInfo{
+			{Name: "User", Package: "app", PackagePath: "example.com/app", File: "user.go"},
+			{Name: "fakeUser", Package: "app", PackagePath: "example.com/app", File: "user_test.go"},
+		},
+		Functions: []analyzer.FuncInfo{
+			{Name: "NewUser", Package: "app", PackagePath: "example.com/app", File: "user.go"},
+			{Name: "Delete", Package: "app", PackagePath: "example.com/app", File: "user.go"},
+			{Name: "TestNewUser", Package: "app", PackagePath: "example.com/app", File: "user_test.go"},
+			{Name: "TestUser", Package: "app_test", PackagePath: "example.com/app_test", File: "user_ext_test.go"},
+		},
+		Packages: []analyzer.PackageInfo{
+			{Name: "app", Path: "example.com/app", File: "user.go"},
+			{Name: "app", Path: "example.com/app", File: "user_test.go"},
+			{Name: "app_test", Path: "example.com/app_test", File: "user_ext_test.go"},
+		},
+		Dependencies: []analyzer.DependencyInfo{
+			{From: "example.com/app.NewUser", To: "example.com/app.User", Type: types.SignatureDependency},
+			{From: "example.com/app.TestNewUser", To: "example.com/app.NewUser", Type: types.BodyCallDependency},
+			{From: "example.com/app_test.TestUser", To: "example.com/app.User", Type: types.SignatureDependency},
+			{From: "package:example.com/app_test", To: "package:example.com/app", Type: types.PackageDependency},
+		},
+	}
+
+	g := BuildDependencyGraphWithPackages(result, ".")
+
+	// テストファイルでのみ宣言されているノードと外部テストパッケージのパッケージノードがテストのノードになる
+	expected := map[types.NodeID]bool{
+		"example.com/app.User":          false,
+		"example.com/app.fakeUser":      true,
+		"example.com/app.NewUser":       false,
+		"example.com/app.TestNewUser":   true,
+		"example.com/app_test.TestUser": true,
+		"package:example.com/app":       false,
+		"package:example.com/app_test":  true,
+	}
+	for id, test := range expected {
+		node, exists := g.Nodes[id]
+		if !exists {
+			t.Errorf("Expected node '%s' not found", id)
+			continue
+		}
+		if node.Test != test {
+			t.Errorf("Expected Test=%v for '%s', got %v", test, id, node.Test)
+		}
+	}
+
+	// テストから直接参照されていない本番コードのノード（パッケージノードは除く）
+	untested := g.UntestedNodes()
+	if want := []types.NodeID{"example.com/app.Delete"}; !reflect.DeepEqual(untested, want) {
+		t.Errorf("UntestedNodes() = %v, expected %v", untested, want)
+	}
+}
+
+func TestBuildDependencyGraph_Generated(t *testing.T) {
+	result := &analyzer.Result{
+		Structs: []analyzer.StructInfo{
+			{Name: "User", Package: "pb", PackagePath: "example.com/app/pb", File: "user.pb.go"},
+			{Name: "Order", Package: "pb", PackagePath: "example.com/app/pb", File: "order.pb.go"},
+			{Name: "Service", Package: "app", PackagePath: "example.com/app", File: "service.go"},
+		},
+		Functions: []analyzer.FuncInfo{
+			// 生成コードと手書きのファイルの両方で宣言されているノードは生成コードとしない
+			{Name: "init", Package: "pb", PackagePath: "example.com/app/pb", File: "user.pb.go"},
+			{Name: "init", Package: "pb", PackagePath: "example.com/app/pb", File: "register.go"},
+		},
+		Dependencies: []analyzer.DependencyInfo{
+			{From: "example.com/app.Service", To: "example.com/app/pb.User", Type: types.FieldDependency, Position: token.Position{Filename: "service.go", Line: 4}},
+			{From: "example.com/app.Service", To: "example.com/app/pb.Order", Type: types.FieldDependency, Position: token.Position{Filename: "service.go", Line: 5}},
+			{From: "example.com/app/pb.Order", To: "example.com/app/pb.User", Type: types.FieldDependency},
+		},
+		GeneratedFiles: []string{"order.pb.go", "user.pb.go"},
+	}
+
+	g := BuildDependencyGraph(result)
+	expected := map[types.NodeID]bool{
+		"example.com/app/pb.User":  true,
+		"example.com/app/pb.Order": true,
+		"example.com/app/pb.init":  false,
+		"example.com/app.Service":  false,
+	}
+	for id, generated := range expected {
+		node, exists := g.Nodes[id]
+		if !exists {
+			t.Errorf("Expected node '%s' not found", id)
+			continue
+		}
+		if node.Generated != generated {
+			t.Errorf("Expected Generated=%v for '%s', got %v", generated, id, node.Generated)
+		}
+	}
+
+	// パッケージごとに1つのノードにまとめ、エッジを付け替える
+	g.CollapseGenerated()
+	collapsed := types.NewGeneratedNodeID("example.com/app/pb")
+	node, exists := g.Nodes[collapsed]
+	if !exists {
+		t.Fatalf("Expected node '%s' not found", collapsed)
+	}
+	if node.Kind != NodeGenerated || !node.Generated || node.Name != "2宣言" || node.PackageName != "pb" {
+		t.Errorf("Unexpected collapsed node: %+v", node)
+	}
+	for _, id := range []types.NodeID{"example.com/app/pb.User", "example.com/app/pb.Order"} {
+		if _, exists := g.Nodes[id]; exists {
+			t.Errorf("Expected node '%s' to be collapsed", id)
+		}
+	}
+	if _, exists := g.Nodes["example.com/app/pb.init"]; !exists {
+		t.Error("Expected handwritten node 'example.com/app/pb.init' to remain")
+	}
+	e := g.Edge("example.com/app.Service", collapsed)
+	if e == nil {
+		t.Fatal("Expected edge from Service to collapsed generated node")
+	}
+	if e.Count != 2 || len(e.Positions) != 2 {
+		t.Errorf("Expected merged edge with 2 occurrences, got count=%d positions=%v", e.Count, e.Positions)
+	}
+	// まとめたノード内の依存関係は自己ループとなるため除外する
+	if g.Edge(collapsed, collapsed) != nil {
+		t.Error("Expected no self loop on collapsed generated node")
+	}
+}
+
+func TestBuildDependencyGraph_Goroutines(t *testing.T) {
+	result := &analyzer.Result{
+		Functions: []analyzer.FuncInfo{
+			{Name: "Start", Package: "worker", PackagePath: "example.com/app/worker", File: "worker/worker.go"},
+			{Name: "process", Package: "worker", PackagePath: "example.com/app/worker", File: "worker/worker.go"},
+		},
+		Goroutines: []analyzer.GoroutineInfo{
+			{
+				Name:        "Start.go#1",
+				Package:     "worker",
+				PackagePath: "example.com/app/worker",
+				File:        "worker/worker.go",
+				Position:    token.Position{Filename: "worker/worker.go", Line: 12},
+				Spawner:     "example.com/app/worker.Start",
+			},
+		},
+		Dependencies: []analyzer.DependencyInfo{
+			{From: "example.com/app/worker.Start", To: "example.com/app/worker.Start.go#1", Type: types.SpawnDependency},
+			{From: "example.com/app/worker.Start.go#1", To: "example.com/app/worker.process", Type: types.BodyCallDependency},
+		},
+	}
+
+	g := BuildDependencyGraph(result)
+	node, exists := g.Nodes["example.com/app/worker.Start.go#1"]
+	if !exists {
+		t.Fatal("Expected goroutine node not found")
+	}
+	// 起動箇所はファイル名と行で表す
+	if node.Kind != NodeGoroutine || node.Name != "Start.go#1" || node.SpawnedAt != "worker.go:12" || node.PackageName != "worker" {
+		t.Errorf("Unexpected goroutine node: %+v", node)
+	}
+	if kinds := g.EdgeDependencyTypes("example.com/app/worker.Start", node.ID); len(kinds) != 1 || kinds[0] != types.SpawnDependency {
+		t.Errorf("Expected spawn edge from Start to the goroutine, got %v", kinds)
+	}
+	if g.Edge(node.ID, "example.com/app/worker.process") == nil {
+		t.Error("Expected edge from the goroutine to process")
+	}
+}
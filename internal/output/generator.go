@@ -6,7 +6,10 @@ import (
 )
 
 // Generator は出力を生成するサービス
-type Generator struct{}
+type Generator struct {
+	muteGenerated bool // 生成コードのノードを控えめなスタイルで表示する
+	edgePositions bool // 各エッジの出現位置をコメントとして出力する
+}
 
 // NewGenerator は新しいGeneratorインスタンスを作成
 func NewGenerator() OutputGenerator {
@@ -17,10 +20,20 @@ func NewGenerator() OutputGenerator {
 
 // GenerateMermaid はMermaid記法の相関図を生成
 func (g *Generator) GenerateMermaid(dependencyGraph *graph.DependencyGraph, stabilityResult *stability.Result) string {
-	return GenerateMermaid(dependencyGraph, stabilityResult)
+	return generateMermaid(dependencyGraph, stabilityResult, false, g.muteGenerated, g.edgePositions)
 }
 
 // GenerateMermaidWithOptions はオプション付きでMermaid記法の相関図を生成
 func (g *Generator) GenerateMermaidWithOptions(dependencyGraph *graph.DependencyGraph, stabilityResult *stability.Result, highlightSDPViolations bool) string {
-	return GenerateMermaidWithOptions(dependencyGraph, stabilityResult, highlightSDPViolations)
+	return generateMermaid(dependencyGraph, stabilityResult, highlightSDPViolations, g.muteGenerated, g.edgePositions)
+}
+
+// SetMuteGenerated は生成コードのノードを控えめなスタイルで表示するかどうかを設定
+func (g *Generator) SetMuteGenerated(mute bool) {
+	g.muteGenerated = mute
+}
+
+// SetEdgePositions は各エッジの出現位置をコメントとして出力するかどうかを設定
+func (g *Generator) SetEdgePositions(positions bool) {
+	g.edgePositions = positions
 }
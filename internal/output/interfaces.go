@@ -9,4 +9,8 @@ import (
 type OutputGenerator interface {
 	GenerateMermaid(dependencyGraph *graph.DependencyGraph, stabilityResult *stability.Result) string
 	GenerateMermaidWithOptions(dependencyGraph *graph.DependencyGraph, stabilityResult *stability.Result, highlightSDPViolations bool) string
+	// SetMuteGenerated は生成コードのノードを控えめなスタイルで表示するかどうかを設定する
+	SetMuteGenerated(mute bool)
+	// SetEdgePositions は各エッジの出現位置をコメントとして出力するかどうかを設定する
+	SetEdgePositions(positions bool)
 }
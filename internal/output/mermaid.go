@@ -103,6 +103,10 @@ type nodeWithStability struct {
 	Package     string
 	Instability float64
 	SafeID      string
+	Platforms   []string // ノードが一部のプラットフォームにのみ存在する場合のプラットフォーム一覧
+	Test        bool     // テストのノードかどうか
+	Muted       bool     // 生成コードのノードとして控えめなスタイルで表示するかどうか
+	SpawnedAt   string   // goroutineノードの場合の起動箇所
 }
 
 func GenerateMermaid(g *graph.DependencyGraph, stabilityResult *stability.Result) string {
@@ -111,15 +115,55 @@ func GenerateMermaid(g *graph.DependencyGraph, stabilityResult *stability.Result
 
 // GenerateMermaidWithOptions はオプション付きでMermaid記法の相関図を生成
 func GenerateMermaidWithOptions(g *graph.DependencyGraph, stabilityResult *stability.Result, highlightSDPViolations bool) string {
+	return generateMermaid(g, stabilityResult, highlightSDPViolations, false, false)
+}
+
+// generateMermaid はMermaid記法の相関図を生成
+// muteGeneratedが指定された場合は、生成コードのノードを控えめなスタイルで表示する
+// edgePositionsが指定された場合は、各エッジの出現位置もコメントとして出力する
+func generateMermaid(g *graph.DependencyGraph, stabilityResult *stability.Result, highlightSDPViolations bool, muteGenerated bool, edgePositions bool) string {
 
 	// パッケージごとにノードをグループ化（パッケージノードは除外）
+	// キーはパッケージの識別子（importパス）で、同名パッケージも別のサブグラフになる
 	packageNodes := make(map[string][]nodeWithStability)
-
-	for id, n := range g.Nodes {
+	packageNames := make(map[string]string)
+	packageModules := make(map[string]string) // パッケージの識別子 -> 所属モジュールのパス
+	// 外部依存ノードは解析対象のパッケージとは別のサブグラフにまとめる
+	var externalNodes []nodeWithStability
+	// テストのノードは本番コードとは別のテストのサブグラフに、パッケージごとにまとめる
+	testNodes := make(map[string][]nodeWithStability)
+
+	for _, id := range g.SortedNodeIDs() {
+		n := g.Nodes[id]
 		// パッケージノードは除外
 		if n.Kind == graph.NodePackage {
 			continue
 		}
+		if n.IsExternal() {
+			externalNodes = append(externalNodes, nodeWithStability{
+				ID:      id,
+				Name:    n.Name,
+				Kind:    n.Kind,
+				Package: n.Package,
+				SafeID:  sanitizeNodeID(string(id)),
+			})
+			continue
+		}
+
+		if n.Test {
+			testNodes[n.Package] = append(testNodes[n.Package], nodeWithStability{
+				ID:        id,
+				Name:      n.Name,
+				Kind:      n.Kind,
+				Package:   n.Package,
+				SafeID:    sanitizeNodeID(string(id)),
+				Platforms: n.Platforms,
+				Test:      true,
+				Muted:     muteGenerated && n.Generated,
+			})
+			packageNames[n.Package] = n.DisplayPackage()
+			continue
+		}
 
 		inst := 0.0
 		if s, ok := stabilityResult.NodeStabilities[id]; ok {
@@ -133,14 +177,22 @@ func GenerateMermaidWithOptions(g *graph.DependencyGraph, stabilityResult *stabi
 			Package:     n.Package,
 			Instability: inst,
 			SafeID:      sanitizeNodeID(string(id)),
+			Platforms:   n.Platforms,
+			SpawnedAt:   n.SpawnedAt,
+			// 畳み込んだ生成コードのノードは常に控えめなスタイルで表示する
+			Muted: n.Kind == graph.NodeGenerated || (muteGenerated && n.Generated),
 		}
 
 		packageNodes[n.Package] = append(packageNodes[n.Package], node)
+		packageNames[n.Package] = n.DisplayPackage()
+		if n.Module != "" {
+			packageModules[n.Package] = n.Module
+		}
 	}
 
-	// 各パッケージ内でノードを不安定度降順でソート
+	// 各パッケージ内でノードを不安定度降順（同じ不安定度の場合はID順）でソート
 	for pkg := range packageNodes {
-		sort.Slice(packageNodes[pkg], func(i, j int) bool {
+		sort.SliceStable(packageNodes[pkg], func(i, j int) bool {
 			return packageNodes[pkg][i].Instability > packageNodes[pkg][j].Instability
 		})
 	}
@@ -168,10 +220,10 @@ func GenerateMermaidWithOptions(g *graph.DependencyGraph, stabilityResult *stabi
 	}
 
 	// パッケージごとにサブグラフを作成
-	for _, pkg := range packages {
+	packageSubgraph := func(pkg string, indent string) string {
 		nodes := packageNodes[pkg]
 		if len(nodes) == 0 {
-			continue
+			return ""
 		}
 
 		// パッケージの不安定度を取得
@@ -182,27 +234,68 @@ func GenerateMermaidWithOptions(g *graph.DependencyGraph, stabilityResult *stabi
 
 		// サブグラフのタイトルにパッケージ名と不安定度を表示
 		safePkgName := sanitizeNodeID(pkg)
-		packageTitle := fmt.Sprintf("%s (不安定度:%.2f)", pkg, packageInstability)
-		out += fmt.Sprintf("    subgraph %s[\"%s\"]\n", safePkgName, escapeNodeLabel(packageTitle))
+		packageTitle := fmt.Sprintf("%s (不安定度:%.2f)", packageLabel(pkg, packageNames), packageInstability)
+		sub := fmt.Sprintf("%ssubgraph %s[\"%s\"]\n", indent, safePkgName, escapeNodeLabel(packageTitle))
 
 		for _, n := range nodes {
 			idMapping[n.ID] = n.SafeID
 			escapedName := escapeNodeLabel(n.Name)
+			if len(n.Platforms) > 0 {
+				// 一部のプラットフォームにのみ存在するノードは、存在するプラットフォームを表示する
+				escapedName += "<br>🖥️ " + escapeNodeLabel(strings.Join(n.Platforms, ", "))
+			}
+			if n.SpawnedAt != "" {
+				// goroutineノードは起動箇所を表示する
+				escapedName += "<br>📍 " + escapeNodeLabel(n.SpawnedAt)
+			}
 			nodeShape := getNodeShape(n.Kind)
-			out += fmt.Sprintf("        %s%s\n", n.SafeID, nodeShape(escapedName, n.Instability))
+			sub += fmt.Sprintf("%s    %s%s\n", indent, n.SafeID, nodeShape(escapedName, n.Instability))
 		}
 
-		out += "    end\n"
+		sub += indent + "end\n"
+		return sub
+	}
+
+	// 複数のモジュール（go.workのワークスペース）のノードを含む場合は、モジュールごとのサブグラフの中にパッケージのサブグラフを作成する
+	modules := moduleOrder(packages, packageModules)
+	if len(modules) > 1 {
+		for _, module := range modules {
+			out += fmt.Sprintf("    subgraph %s[\"%s\"]\n", sanitizeNodeID("module_"+module), escapeNodeLabel("🧩 module: "+module))
+			for _, pkg := range packages {
+				if packageModules[pkg] == module {
+					out += packageSubgraph(pkg, "        ")
+				}
+			}
+			out += "    end\n"
+		}
+		// モジュールが不明なパッケージはモジュールのサブグラフの外に出力する
+		for _, pkg := range packages {
+			if packageModules[pkg] == "" {
+				out += packageSubgraph(pkg, "    ")
+			}
+		}
+	} else {
+		for _, pkg := range packages {
+			out += packageSubgraph(pkg, "    ")
+		}
 	}
 
+	// テストのノードのサブグラフを作成
+	out += testSubgraph(g, testNodes, packageNames, idMapping)
+
+	// 外部依存ノードのサブグラフを作成
+	out += externalSubgraphs(externalNodes, stabilityResult, idMapping)
+
 	// エッジ定義（パッケージノード間のエッジは除外）
 	var violationEdgeIndices []int
 	edgeIndex := 0
 
-	for from, tos := range g.Edges {
-		// パッケージノードからのエッジは除外
-		fromNode := g.Nodes[from]
-		if fromNode == nil || fromNode.Kind == graph.NodePackage {
+	// 依存元・依存先のID順に出力し、同じ入力からは常に同じ出力を得る
+	for _, e := range g.SortedEdges() {
+		from, to := e.From, e.To
+		// パッケージノードからのエッジ・パッケージノードへのエッジは除外
+		fromNode, toNode := g.Nodes[from], g.Nodes[to]
+		if fromNode == nil || fromNode.Kind == graph.NodePackage || toNode == nil || toNode.Kind == graph.NodePackage {
 			continue
 		}
 
@@ -210,30 +303,31 @@ func GenerateMermaidWithOptions(g *graph.DependencyGraph, stabilityResult *stabi
 		if safeFromID == "" {
 			safeFromID = sanitizeNodeID(string(from))
 		}
+		safeToID := idMapping[to]
+		if safeToID == "" {
+			safeToID = sanitizeNodeID(string(to))
+		}
 
-		for to := range tos {
-			// パッケージノードへのエッジは除外
-			toNode := g.Nodes[to]
-			if toNode == nil || toNode.Kind == graph.NodePackage {
-				continue
-			}
-
-			safeToID := idMapping[to]
-			if safeToID == "" {
-				safeToID = sanitizeNodeID(string(to))
-			}
-
-			out += fmt.Sprintf("    %s --> %s\n", safeFromID, safeToID)
-
-			// SDP違反のエッジかチェック
-			if highlightSDPViolations && sdpViolationEdges != nil {
-				edgeKey := fmt.Sprintf("%s->%s", from, to)
-				if sdpViolationEdges[edgeKey] {
-					violationEdgeIndices = append(violationEdgeIndices, edgeIndex)
-				}
+		// インターフェース実装のみのエッジは点線で描画
+		arrow := "-->"
+		if e.IsImplementationOnly() {
+			arrow = "-.->"
+		}
+		// 一部のプラットフォームにのみ存在するエッジは、存在するプラットフォームを表示する
+		if len(e.Platforms) > 0 {
+			arrow += fmt.Sprintf("|\"🖥️ %s\"|", escapeNodeLabel(strings.Join(e.Platforms, ", ")))
+		}
+		out += fmt.Sprintf("    %s %s %s\n", safeFromID, arrow, safeToID)
+		out += edgeComment(e, edgePositions)
+
+		// SDP違反のエッジかチェック
+		if highlightSDPViolations && sdpViolationEdges != nil {
+			edgeKey := fmt.Sprintf("%s->%s", from, to)
+			if sdpViolationEdges[edgeKey] {
+				violationEdgeIndices = append(violationEdgeIndices, edgeIndex)
 			}
-			edgeIndex++
 		}
+		edgeIndex++
 	}
 
 	// スタイル定義を追加
@@ -241,6 +335,10 @@ func GenerateMermaidWithOptions(g *graph.DependencyGraph, stabilityResult *stabi
 
 	// ノードにスタイルクラスを適用
 	out += applyNodeStyles(packageNodes)
+	out += applyNodeStyles(testNodes)
+	if len(externalNodes) > 0 {
+		out += applyNodeStyles(map[string][]nodeWithStability{"": externalNodes})
+	}
 
 	// SDP違反のエッジに赤色のスタイルを適用
 	if highlightSDPViolations && len(violationEdgeIndices) > 0 {
@@ -253,6 +351,167 @@ func GenerateMermaidWithOptions(g *graph.DependencyGraph, stabilityResult *stabi
 	return out
 }
 
+// testSubgraph はテストのノードを本番コードとは別のサブグラフにパッケージごとにまとめて出力する
+// テストのノードは不安定度の算出に含めないため、代わりに参照している本番コードのノード数を表示する
+func testSubgraph(g *graph.DependencyGraph, testNodes map[string][]nodeWithStability, packageNames map[string]string, idMapping map[types.NodeID]string) string {
+	if len(testNodes) == 0 {
+		return ""
+	}
+	var packages []string
+	for pkg := range testNodes {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+
+	out := "    subgraph test_layer[\"🧪 テスト\"]\n"
+	for _, pkg := range packages {
+		nodes := testNodes[pkg]
+		sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+		out += fmt.Sprintf("        subgraph %s[\"%s\"]\n", sanitizeNodeID("test_"+pkg), escapeNodeLabel(packageLabel(pkg, packageNames)))
+		for _, n := range nodes {
+			idMapping[n.ID] = n.SafeID
+			name := escapeNodeLabel(n.Name)
+			if len(n.Platforms) > 0 {
+				name += "<br>🖥️ " + escapeNodeLabel(strings.Join(n.Platforms, ", "))
+			}
+			out += fmt.Sprintf("            %s([🧪 test: %s<br>参照先:%d])\n", n.SafeID, name, productionReferences(g, n.ID))
+		}
+		out += "        end\n"
+	}
+	out += "    end\n"
+	out += "    style test_layer fill:#fffde7,stroke:#f9a825,stroke-width:2px,stroke-dasharray:5 5\n"
+	return out
+}
+
+// productionReferences はノードが直接参照している本番コードのノード（テスト・パッケージ・外部依存ノード以外）の数を返す
+func productionReferences(g *graph.DependencyGraph, id types.NodeID) int {
+	count := 0
+	for to := range g.Edges[id] {
+		if n := g.Nodes[to]; n != nil && !n.Test && !n.IsExternal() && n.Kind != graph.NodePackage {
+			count++
+		}
+	}
+	return count
+}
+
+// moduleOrder はパッケージが属するモジュールのパスを重複なくソートして返す（モジュールが不明なパッケージは除く）
+func moduleOrder(packages []string, packageModules map[string]string) []string {
+	seen := make(map[string]bool)
+	var modules []string
+	for _, pkg := range packages {
+		if module := packageModules[pkg]; module != "" && !seen[module] {
+			seen[module] = true
+			modules = append(modules, module)
+		}
+	}
+	sort.Strings(modules)
+	return modules
+}
+
+// externalSubgraphs はサードパーティモジュールと標準ライブラリの外部依存ノードをそれぞれのサブグラフとして出力する
+// 外部依存ノードには不安定度の代わりに、依存している解析対象のノード数・パッケージ数を表示する
+func externalSubgraphs(nodes []nodeWithStability, stabilityResult *stability.Result, idMapping map[types.NodeID]string) string {
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].Package < nodes[j].Package })
+
+	groups := []struct {
+		id    string
+		title string
+		kind  graph.NodeKind
+		style string
+	}{
+		{"external_modules", "外部モジュール", graph.NodeExternal, "fill:#fafafa,stroke:#546e7a,stroke-width:2px,stroke-dasharray:5 5"},
+		{"external_stdlib", "標準ライブラリ", graph.NodeStdlib, "fill:#fafafa,stroke:#827717,stroke-width:2px,stroke-dasharray:5 5"},
+	}
+
+	var out string
+	for _, group := range groups {
+		var body string
+		for _, n := range nodes {
+			if n.Kind != group.kind {
+				continue
+			}
+			idMapping[n.ID] = n.SafeID
+			var fanIn stability.ExternalFanIn
+			if f, ok := stabilityResult.ExternalFanIns[n.ID]; ok {
+				fanIn = *f
+			}
+			body += fmt.Sprintf("        %s%s\n", n.SafeID, externalNodeShape(n.Kind, escapeNodeLabel(n.Name), fanIn))
+		}
+		if body == "" {
+			continue
+		}
+		out += fmt.Sprintf("    subgraph %s[\"%s\"]\n", group.id, group.title)
+		out += body
+		out += "    end\n"
+		out += fmt.Sprintf("    style %s %s\n", group.id, group.style)
+	}
+	return out
+}
+
+// externalNodeShape は外部依存ノードの形状と、被依存数を含むラベルを返す
+func externalNodeShape(kind graph.NodeKind, name string, fanIn stability.ExternalFanIn) string {
+	if kind == graph.NodeStdlib {
+		// 標準ライブラリ: 長方形 + 本アイコン
+		return fmt.Sprintf("[📖 stdlib: %s<br>被依存数:%d パッケージ数:%d]", name, fanIn.InDegree, fanIn.PackageInDegree)
+	}
+	// サードパーティモジュール: 長方形 + 地球アイコン
+	return fmt.Sprintf("[🌍 module: %s<br>被依存数:%d パッケージ数:%d]", name, fanIn.InDegree, fanIn.PackageInDegree)
+}
+
+// edgeComment はエッジを構成する依存の種類・出現回数をMermaidのコメントとして返す
+// positionsが指定された場合は出現位置も "file:line:col" 形式で1行ずつ出力し、エディタから該当行へ移動できるようにする
+// 出現位置は相関図が大きくなるため、既定では出力しない
+func edgeComment(e *graph.Edge, positions bool) string {
+	if e == nil || (len(e.Kinds) == 0 && len(e.Positions) == 0) {
+		return ""
+	}
+	out := fmt.Sprintf("    %%%% %s --> %s: %s\n", e.From, e.To, e.Label())
+	if !positions {
+		return out
+	}
+	for _, pos := range e.Positions {
+		out += fmt.Sprintf("    %%%%   %s\n", pos)
+	}
+	return out
+}
+
+// GenerateEdgePositions は各エッジの依存の種類・出現回数と出現位置（"file:line"）をMarkdownの表として返す
+// Markdownの出力ファイルで、相関図の下にエッジの出現位置を一覧するために使う
+// パッケージノードのエッジと出現位置が不明なエッジは含めない（該当するエッジがない場合は空文字）
+func GenerateEdgePositions(g *graph.DependencyGraph) string {
+	var rows string
+	for _, e := range g.SortedEdges() {
+		fromNode, toNode := g.Nodes[e.From], g.Nodes[e.To]
+		if fromNode == nil || fromNode.Kind == graph.NodePackage || toNode == nil || toNode.Kind == graph.NodePackage || len(e.Positions) == 0 {
+			continue
+		}
+		var positions []string
+		for _, pos := range e.Positions {
+			positions = append(positions, fmt.Sprintf("%s:%d", pos.Filename, pos.Line))
+		}
+		rows += fmt.Sprintf("| `%s` | `%s` | %s | %s |\n", e.From, e.To, e.Label(), strings.Join(positions, "<br>"))
+	}
+	if rows == "" {
+		return ""
+	}
+	return "| 依存元 | 依存先 | 依存の種類 | 出現位置 |\n| --- | --- | --- | --- |\n" + rows
+}
+
+// packageLabel はサブグラフに表示するパッケージ名を返す
+// 通常は短いパッケージ名を表示し、同名のパッケージが複数ある場合のみimportパスを表示する
+func packageLabel(pkg string, packageNames map[string]string) string {
+	name := packageNames[pkg]
+	if name == "" {
+		return pkg
+	}
+	for other, otherName := range packageNames {
+		if other != pkg && otherName == name {
+			return pkg
+		}
+	}
+	return name
+}
+
 // getNodeShape はノードの種類に応じた形状を返す関数を返す
 func getNodeShape(kind graph.NodeKind) func(string, float64) string {
 	switch kind {
@@ -271,6 +530,56 @@ func getNodeShape(kind graph.NodeKind) func(string, float64) string {
 		return func(name string, instability float64) string {
 			return fmt.Sprintf("(⚙️ func: %s<br>不安定度:%.2f)", name, instability)
 		}
+	case graph.NodeMethod:
+		// メソッド: スタジアム形 + メソッドアイコン
+		return func(name string, instability float64) string {
+			return fmt.Sprintf("([🔧 method: %s<br>不安定度:%.2f])", name, instability)
+		}
+	case graph.NodeDefinedType:
+		// 定義型: 非対称形 + タグアイコン
+		return func(name string, instability float64) string {
+			return fmt.Sprintf(">🏷️ type: %s<br>不安定度:%.2f]", name, instability)
+		}
+	case graph.NodeFuncType:
+		// 関数型: サブルーチン形 + 関数型アイコン
+		return func(name string, instability float64) string {
+			return fmt.Sprintf("[[λ func type: %s<br>不安定度:%.2f]]", name, instability)
+		}
+	case graph.NodeCollectionType:
+		// コレクション型: 円柱形 + コレクションアイコン
+		return func(name string, instability float64) string {
+			return fmt.Sprintf("[(📚 collection: %s<br>不安定度:%.2f)]", name, instability)
+		}
+	case graph.NodeAlias:
+		// エイリアス: 平行四辺形 + リンクアイコン
+		return func(name string, instability float64) string {
+			return fmt.Sprintf("[/🔗 alias: %s<br>不安定度:%.2f/]", name, instability)
+		}
+	case graph.NodeVar:
+		// 変数: 逆台形 + 変数アイコン
+		return func(name string, instability float64) string {
+			return fmt.Sprintf("[\\🌐 var: %s<br>不安定度:%.2f/]", name, instability)
+		}
+	case graph.NodeConst:
+		// 定数: 台形 + 定数アイコン
+		return func(name string, instability float64) string {
+			return fmt.Sprintf("[/🔒 const: %s<br>不安定度:%.2f\\]", name, instability)
+		}
+	case graph.NodeInit:
+		// init関数: 二重円 + 起動アイコン
+		return func(name string, instability float64) string {
+			return fmt.Sprintf("(((🚀 init: %s<br>不安定度:%.2f)))", name, instability)
+		}
+	case graph.NodeGenerated:
+		// 生成コード: サブルーチン形 + ロボットアイコン
+		return func(name string, instability float64) string {
+			return fmt.Sprintf("[[🤖 generated: %s<br>不安定度:%.2f]]", name, instability)
+		}
+	case graph.NodeGoroutine:
+		// goroutine: 円形 + 並行処理アイコン
+		return func(name string, instability float64) string {
+			return fmt.Sprintf("((🧵 goroutine: %s<br>不安定度:%.2f))", name, instability)
+		}
 	case graph.NodePackage:
 		// パッケージ: 六角形 + パッケージアイコン
 		return func(name string, instability float64) string {
@@ -294,8 +603,27 @@ func generateStyles() string {
     classDef interfaceStyle fill:#f3e5f5,stroke:#4a148c,stroke-width:2px
     %% 関数: 緑系（処理・動作を表現）
     classDef funcStyle fill:#e8f5e8,stroke:#1b5e20,stroke-width:2px
+    %% メソッド: 黄緑系（型に属する処理を表現）
+    classDef methodStyle fill:#f1f8e9,stroke:#33691e,stroke-width:2px
+    %% 定義型・関数型・コレクション型・エイリアス: 灰色系（型の別名・派生を表現）
+    classDef typeStyle fill:#f5f5f5,stroke:#424242,stroke-width:2px
+    %% 変数・定数: 赤系（グローバルな状態を表現）
+    classDef globalStyle fill:#ffebee,stroke:#b71c1c,stroke-width:2px
+    %% init関数: 緑系の太線（パッケージ初期化処理を表現）
+    classDef initStyle fill:#e8f5e8,stroke:#1b5e20,stroke-width:3px
+    %% goroutine: 青緑系（並行に動作する処理を表現）
+    classDef goroutineStyle fill:#e0f2f1,stroke:#004d40,stroke-width:2px
     %% パッケージ: オレンジ系（グループ化を表現）
     classDef packageStyle fill:#fff3e0,stroke:#e65100,stroke-width:3px
+    %% 外部モジュール・標準ライブラリ: 灰色系の破線（解析対象外を表現）
+    classDef externalStyle fill:#eceff1,stroke:#546e7a,stroke-width:1px,stroke-dasharray:3 3
+    classDef stdlibStyle fill:#f9fbe7,stroke:#827717,stroke-width:1px,stroke-dasharray:3 3
+    %% 一部のプラットフォームにのみ存在するノード: 破線（種類ごとのスタイルに重ねて適用）
+    classDef platformStyle stroke-dasharray:5 5
+    %% テスト: 黄色系（本番コードとは別の層を表現）
+    classDef testStyle fill:#fffde7,stroke:#f57f17,stroke-width:2px
+    %% 生成コード: 淡い灰色（種類ごとのスタイルに重ねて適用し、手書きのコードより目立たなくする）
+    classDef generatedStyle fill:#fafafa,stroke:#bdbdbd,color:#9e9e9e,stroke-width:1px
 `
 }
 
@@ -303,8 +631,14 @@ func generateStyles() string {
 func applyNodeStyles(packageNodes map[string][]nodeWithStability) string {
 	var out string
 
-	for _, nodes := range packageNodes {
-		for _, node := range nodes {
+	var packages []string
+	for pkg := range packageNodes {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+
+	for _, pkg := range packages {
+		for _, node := range packageNodes[pkg] {
 			var styleClass string
 			switch node.Kind {
 			case graph.NodeStruct:
@@ -313,12 +647,37 @@ func applyNodeStyles(packageNodes map[string][]nodeWithStability) string {
 				styleClass = "interfaceStyle"
 			case graph.NodeFunc:
 				styleClass = "funcStyle"
+			case graph.NodeMethod:
+				styleClass = "methodStyle"
+			case graph.NodeDefinedType, graph.NodeFuncType, graph.NodeCollectionType, graph.NodeAlias:
+				styleClass = "typeStyle"
+			case graph.NodeVar, graph.NodeConst:
+				styleClass = "globalStyle"
+			case graph.NodeInit:
+				styleClass = "initStyle"
+			case graph.NodeGoroutine:
+				styleClass = "goroutineStyle"
 			case graph.NodePackage:
 				styleClass = "packageStyle"
+			case graph.NodeExternal:
+				styleClass = "externalStyle"
+			case graph.NodeStdlib:
+				styleClass = "stdlibStyle"
+			case graph.NodeGenerated:
+				styleClass = "typeStyle"
 			default:
 				styleClass = "structStyle" // デフォルト
 			}
+			if node.Test {
+				styleClass = "testStyle"
+			}
 			out += fmt.Sprintf("    class %s %s\n", node.SafeID, styleClass)
+			if len(node.Platforms) > 0 {
+				out += fmt.Sprintf("    class %s platformStyle\n", node.SafeID)
+			}
+			if node.Muted {
+				out += fmt.Sprintf("    class %s generatedStyle\n", node.SafeID)
+			}
 		}
 	}
 
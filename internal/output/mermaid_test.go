@@ -1,6 +1,7 @@
 package output
 
 import (
+	"go/token"
 	"strings"
 	"testing"
 
@@ -474,3 +475,350 @@ func TestGenerateMermaidWithSDPViolations(t *testing.T) {
 	t.Logf("SDP違反ハイライトなしの出力:\n%s", resultWithoutHighlight)
 	t.Logf("SDP違反ハイライトありの出力:\n%s", resultWithHighlight)
 }
+
+func TestGenerateMermaidWithSameNamedPackages(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.AddNode(&graph.Node{ID: "example.com/app/api/handler.User", Kind: graph.NodeStruct, Name: "User", Package: "example.com/app/api/handler", PackageName: "handler"})
+	g.AddNode(&graph.Node{ID: "example.com/app/web/handler.User", Kind: graph.NodeStruct, Name: "User", Package: "example.com/app/web/handler", PackageName: "handler"})
+	g.AddNode(&graph.Node{ID: "example.com/app/model.User", Kind: graph.NodeStruct, Name: "User", Package: "example.com/app/model", PackageName: "model"})
+
+	result := GenerateMermaid(g, stability.NewResult())
+
+	// 同名パッケージはimportパスで区別して表示される
+	for _, expected := range []string{
+		`"example.com/app/api/handler (不安定度:0.00)"`,
+		`"example.com/app/web/handler (不安定度:0.00)"`,
+		`"model (不安定度:0.00)"`,
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("サブグラフタイトル %s が含まれていません", expected)
+		}
+	}
+
+	if strings.Count(result, "subgraph ") != 3 {
+		t.Errorf("同名パッケージが別のサブグラフになっていません:\n%s", result)
+	}
+}
+
+func TestGenerateMermaidWithImplementationEdges(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.AddNode(&graph.Node{ID: "test.Repo", Kind: graph.NodeInterface, Name: "Repo", Package: "test", PackageName: "test"})
+	g.AddNode(&graph.Node{ID: "test.DB", Kind: graph.NodeStruct, Name: "DB", Package: "test", PackageName: "test"})
+	g.AddNode(&graph.Node{ID: "test.Cache", Kind: graph.NodeStruct, Name: "Cache", Package: "test", PackageName: "test"})
+	g.AddTypedEdge("test.DB", "test.Repo", types.ImplementationDependency)
+	g.AddTypedEdge("test.Cache", "test.Repo", types.ImplementationDependency)
+	g.AddTypedEdge("test.Cache", "test.Repo", types.FieldDependency)
+
+	result := GenerateMermaid(g, stability.NewResult())
+
+	// 実装関係のみのエッジは点線、他の依存を含むエッジは実線で描画される
+	if !strings.Contains(result, "test_DB -.-> test_Repo") {
+		t.Errorf("実装関係のエッジが点線になっていません:\n%s", result)
+	}
+	if !strings.Contains(result, "test_Cache --> test_Repo") {
+		t.Errorf("フィールド依存を含むエッジが実線になっていません:\n%s", result)
+	}
+}
+
+func TestGenerateMermaidWithNamedTypes(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.AddNode(&graph.Node{ID: "test.Status", Kind: graph.NodeDefinedType, Name: "Status", Package: "test", PackageName: "test"})
+	g.AddNode(&graph.Node{ID: "test.HandlerFunc", Kind: graph.NodeFuncType, Name: "HandlerFunc", Package: "test", PackageName: "test"})
+	g.AddNode(&graph.Node{ID: "test.IDs", Kind: graph.NodeCollectionType, Name: "IDs", Package: "test", PackageName: "test"})
+	g.AddNode(&graph.Node{ID: "test.Account", Kind: graph.NodeAlias, Name: "Account", Package: "test", PackageName: "test"})
+
+	result := GenerateMermaid(g, stability.NewResult())
+
+	for _, expected := range []string{
+		"test_Status>🏷️ type: Status<br>不安定度:0.00]",
+		"test_HandlerFunc[[λ func type: HandlerFunc<br>不安定度:0.00]]",
+		"test_IDs[(📚 collection: IDs<br>不安定度:0.00)]",
+		"test_Account[/🔗 alias: Account<br>不安定度:0.00/]",
+		"class test_Status typeStyle",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("出力に %s が含まれていません:\n%s", expected, result)
+		}
+	}
+}
+
+func TestGenerateMermaidWithGlobals(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.AddNode(&graph.Node{ID: "test.init", Kind: graph.NodeInit, Name: "init", Package: "test", PackageName: "test"})
+	g.AddNode(&graph.Node{ID: "test.registry", Kind: graph.NodeVar, Name: "registry", Package: "test", PackageName: "test"})
+	g.AddNode(&graph.Node{ID: "test.MaxRetry", Kind: graph.NodeConst, Name: "MaxRetry", Package: "test", PackageName: "test"})
+	g.AddTypedEdge("test.init", "test.registry", types.WriteDependency)
+
+	result := GenerateMermaid(g, stability.NewResult())
+
+	for _, expected := range []string{
+		"test_init(((🚀 init: init<br>不安定度:0.00)))",
+		"test_registry[\\🌐 var: registry<br>不安定度:0.00/]",
+		"test_MaxRetry[/🔒 const: MaxRetry<br>不安定度:0.00\\]",
+		"test_init --> test_registry",
+		"class test_registry globalStyle",
+		"class test_init initStyle",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("出力に %s が含まれていません:\n%s", expected, result)
+		}
+	}
+}
+
+func TestGenerateMermaidWithEdgePositions(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.AddNode(&graph.Node{ID: "test.User", Kind: graph.NodeStruct, Name: "User", Package: "test", PackageName: "test"})
+	g.AddNode(&graph.Node{ID: "test.Profile", Kind: graph.NodeStruct, Name: "Profile", Package: "test", PackageName: "test"})
+	g.AddDependency(types.DependencyInfo{From: "test.User", To: "test.Profile", Type: types.FieldDependency,
+		Position: token.Position{Filename: "user.go", Line: 5, Column: 10}})
+	g.AddDependency(types.DependencyInfo{From: "test.User", To: "test.Profile", Type: types.FieldDependency,
+		Position: token.Position{Filename: "user.go", Line: 6, Column: 10}})
+
+	result := GenerateMermaid(g, stability.NewResult())
+
+	// 既定ではエッジの直後に依存の種類と出現回数だけがコメントとして出力される
+	if expected := "test_User --> test_Profile\n    %% test.User --> test.Profile: field x2\n"; !strings.Contains(result, expected) {
+		t.Errorf("出力に %q が含まれていません:\n%s", expected, result)
+	}
+	if strings.Contains(result, "user.go") {
+		t.Errorf("指定していないのに出現位置が出力されています:\n%s", result)
+	}
+
+	// 指定した場合は出現位置もコメントとして出力される
+	generator := NewGenerator()
+	generator.SetEdgePositions(true)
+	result = generator.GenerateMermaid(g, stability.NewResult())
+	for _, expected := range []string{
+		"test_User --> test_Profile\n    %% test.User --> test.Profile: field x2\n",
+		"    %%   user.go:5:10\n",
+		"    %%   user.go:6:10\n",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("出力に %q が含まれていません:\n%s", expected, result)
+		}
+	}
+}
+
+func TestGenerateEdgePositions(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.AddNode(&graph.Node{ID: "test.User", Kind: graph.NodeStruct, Name: "User", Package: "test", PackageName: "test"})
+	g.AddNode(&graph.Node{ID: "test.Profile", Kind: graph.NodeStruct, Name: "Profile", Package: "test", PackageName: "test"})
+	g.AddNode(&graph.Node{ID: "test.Admin", Kind: graph.NodeStruct, Name: "Admin", Package: "test", PackageName: "test"})
+	g.AddDependency(types.DependencyInfo{From: "test.User", To: "test.Profile", Type: types.FieldDependency,
+		Position: token.Position{Filename: "user.go", Line: 5, Column: 10}})
+	g.AddDependency(types.DependencyInfo{From: "test.User", To: "test.Profile", Type: types.FieldDependency,
+		Position: token.Position{Filename: "user.go", Line: 6, Column: 10}})
+	g.AddTypedEdge("test.Admin", "test.User", types.EmbeddingDependency)
+
+	// 出現位置のあるエッジだけがfile:lineの表として出力される
+	expected := "| 依存元 | 依存先 | 依存の種類 | 出現位置 |\n" +
+		"| --- | --- | --- | --- |\n" +
+		"| `test.User` | `test.Profile` | field x2 | user.go:5<br>user.go:6 |\n"
+	if result := GenerateEdgePositions(g); result != expected {
+		t.Errorf("GenerateEdgePositions() = %q, expected %q", result, expected)
+	}
+
+	if result := GenerateEdgePositions(graph.NewDependencyGraph()); result != "" {
+		t.Errorf("出現位置がない場合は空文字列を返すべきです: %q", result)
+	}
+}
+
+func TestGenerateMermaidWithExternalNodes(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	cobra := types.NewExternalNodeID("github.com/spf13/cobra")
+	strs := types.NewStdlibNodeID("strings")
+	g.AddNode(&graph.Node{ID: "cmd.Run", Kind: graph.NodeFunc, Name: "Run", Package: "cmd", PackageName: "cmd"})
+	g.AddNode(&graph.Node{ID: cobra, Kind: graph.NodeExternal, Name: "github.com/spf13/cobra", Package: "github.com/spf13/cobra", PackageName: "github.com/spf13/cobra"})
+	g.AddNode(&graph.Node{ID: strs, Kind: graph.NodeStdlib, Name: "strings", Package: "strings", PackageName: "strings"})
+	g.AddTypedEdge("cmd.Run", cobra, types.ExternalDependency)
+	g.AddTypedEdge("cmd.Run", strs, types.ExternalDependency)
+
+	result := GenerateMermaid(g, stability.NewAnalyzer().Analyze(g))
+
+	for _, expected := range []string{
+		"subgraph external_modules[\"外部モジュール\"]",
+		"external_github_com_spf13_cobra[🌍 module: github.com/spf13/cobra<br>被依存数:1 パッケージ数:1]",
+		"style external_modules ",
+		"subgraph external_stdlib[\"標準ライブラリ\"]",
+		"stdlib_strings[📖 stdlib: strings<br>被依存数:1 パッケージ数:1]",
+		"cmd_Run --> external_github_com_spf13_cobra",
+		"cmd_Run --> stdlib_strings",
+		"class external_github_com_spf13_cobra externalStyle",
+		"class stdlib_strings stdlibStyle",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("出力に %s が含まれていません:\n%s", expected, result)
+		}
+	}
+	// 外部依存ノードはパッケージのサブグラフを作らない
+	if strings.Contains(result, "subgraph github_com_spf13_cobra") || strings.Contains(result, "subgraph strings") {
+		t.Errorf("外部依存ノードのパッケージサブグラフが出力されています:\n%s", result)
+	}
+}
+
+func TestGenerateMermaidWithModules(t *testing.T) {
+	build := func(coreModule string) string {
+		g := graph.NewDependencyGraph()
+		g.AddNode(&graph.Node{ID: "example.com/api.Handler", Kind: graph.NodeStruct, Name: "Handler", Package: "example.com/api", PackageName: "api", Module: "example.com/api"})
+		g.AddNode(&graph.Node{ID: "example.com/core/model.User", Kind: graph.NodeStruct, Name: "User", Package: "example.com/core/model", PackageName: "model", Module: coreModule})
+		g.AddTypedEdge("example.com/api.Handler", "example.com/core/model.User", types.FieldDependency)
+		return GenerateMermaid(g, stability.NewAnalyzer().Analyze(g))
+	}
+
+	// 複数のモジュールを含む場合はモジュール、パッケージの順に入れ子のサブグラフにする
+	result := build("example.com/core")
+	for _, expected := range []string{
+		"    subgraph module_example_com_api[\"🧩 module: example.com/api\"]\n        subgraph example_com_api[",
+		"    subgraph module_example_com_core[\"🧩 module: example.com/core\"]\n        subgraph example_com_core_model[",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("出力に %q が含まれていません:\n%s", expected, result)
+		}
+	}
+
+	// 単一のモジュールの場合はモジュールのサブグラフを作らない
+	if result := build("example.com/api"); strings.Contains(result, "subgraph module_") {
+		t.Errorf("単一モジュールでモジュールのサブグラフが出力されています:\n%s", result)
+	}
+}
+
+func TestGenerateMermaidWithPlatforms(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.AddNode(&graph.Node{ID: "app.poll", Kind: graph.NodeFunc, Name: "poll", Package: "app", PackageName: "app", Platforms: []string{"linux/amd64", "darwin/arm64"}})
+	g.AddNode(&graph.Node{ID: "app.File", Kind: graph.NodeStruct, Name: "File", Package: "app", PackageName: "app"})
+	g.AddTypedEdge("app.poll", "app.File", types.SignatureDependency)
+	g.Edge("app.poll", "app.File").Platforms = []string{"linux/amd64", "darwin/arm64"}
+
+	result := GenerateMermaid(g, stability.NewAnalyzer().Analyze(g))
+
+	for _, expected := range []string{
+		"app_poll(⚙️ func: poll<br>🖥️ linux/amd64, darwin/arm64<br>不安定度:1.00)",
+		"app_File[📦 struct: File<br>不安定度:0.00]",
+		"app_poll -->|\"🖥️ linux/amd64, darwin/arm64\"| app_File",
+		"class app_poll funcStyle\n    class app_poll platformStyle",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("出力に %q が含まれていません:\n%s", expected, result)
+		}
+	}
+	if strings.Contains(result, "class app_File platformStyle") {
+		t.Errorf("全プラットフォームに存在するノードにplatformStyleが適用されています:\n%s", result)
+	}
+}
+
+func TestGenerateMermaidWithTests(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.AddNode(&graph.Node{ID: "app.NewUser", Kind: graph.NodeFunc, Name: "NewUser", Package: "app", PackageName: "app"})
+	g.AddNode(&graph.Node{ID: "app.User", Kind: graph.NodeStruct, Name: "User", Package: "app", PackageName: "app"})
+	g.AddNode(&graph.Node{ID: "app.TestNewUser", Kind: graph.NodeFunc, Name: "TestNewUser", Package: "app", PackageName: "app", Test: true})
+	g.AddNode(&graph.Node{ID: "app_test.helper", Kind: graph.NodeFunc, Name: "helper", Package: "app_test", PackageName: "app_test", Test: true})
+	g.AddTypedEdge("app.NewUser", "app.User", types.SignatureDependency)
+	g.AddTypedEdge("app.TestNewUser", "app.NewUser", types.BodyCallDependency)
+	g.AddTypedEdge("app.TestNewUser", "app.User", types.BodyCallDependency)
+	g.AddTypedEdge("app.TestNewUser", "app_test.helper", types.BodyCallDependency)
+
+	result := GenerateMermaid(g, stability.NewAnalyzer().Analyze(g))
+
+	// テストのノードは本番コードのパッケージとは別のテストのサブグラフに、パッケージごとにまとめる
+	for _, expected := range []string{
+		"    subgraph test_layer[\"🧪 テスト\"]\n        subgraph test_app[\"app\"]\n            app_TestNewUser([🧪 test: TestNewUser<br>参照先:2])\n        end\n",
+		"        subgraph test_app_test[\"app_test\"]\n            app_test_helper([🧪 test: helper<br>参照先:0])\n",
+		"style test_layer ",
+		"app_TestNewUser --> app_NewUser",
+		"class app_TestNewUser testStyle",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("出力に %q が含まれていません:\n%s", expected, result)
+		}
+	}
+	if strings.Contains(result, "subgraph app_test[") || strings.Contains(result, "class app_TestNewUser funcStyle") {
+		t.Errorf("テストのノードが本番コードと同じ扱いで出力されています:\n%s", result)
+	}
+}
+
+func TestGenerateMermaidWithGenerated(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.AddNode(&graph.Node{ID: "app.Service", Kind: graph.NodeStruct, Name: "Service", Package: "app", PackageName: "app"})
+	g.AddNode(&graph.Node{ID: "app.UserRequest", Kind: graph.NodeStruct, Name: "UserRequest", Package: "app", PackageName: "app", Generated: true})
+	g.AddNode(&graph.Node{ID: "generated:pb", Kind: graph.NodeGenerated, Name: "3宣言", Package: "pb", PackageName: "pb", Generated: true})
+	g.AddTypedEdge("app.Service", "app.UserRequest", types.FieldDependency)
+	g.AddTypedEdge("app.Service", "generated:pb", types.FieldDependency)
+	s := stability.NewAnalyzer().Analyze(g)
+
+	// まとめた生成コードのノードは常に控えめなスタイルで表示する
+	result := GenerateMermaid(g, s)
+	for _, expected := range []string{
+		"generated_pb[[🤖 generated: 3宣言<br>不安定度:0.00]]",
+		"class generated_pb generatedStyle",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("出力に %q が含まれていません:\n%s", expected, result)
+		}
+	}
+	if strings.Contains(result, "class app_UserRequest generatedStyle") {
+		t.Errorf("muteを指定していないのに生成コードのノードにgeneratedStyleが適用されています:\n%s", result)
+	}
+
+	generator := NewGenerator()
+	generator.SetMuteGenerated(true)
+	result = generator.GenerateMermaid(g, s)
+	if expected := "class app_UserRequest structStyle\n    class app_UserRequest generatedStyle"; !strings.Contains(result, expected) {
+		t.Errorf("出力に %q が含まれていません:\n%s", expected, result)
+	}
+	if strings.Contains(result, "class app_Service generatedStyle") {
+		t.Errorf("手書きのノードにgeneratedStyleが適用されています:\n%s", result)
+	}
+}
+
+func TestGenerateMermaidWithGoroutine(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.AddNode(&graph.Node{ID: "worker.Start", Kind: graph.NodeFunc, Name: "Start", Package: "worker", PackageName: "worker"})
+	g.AddNode(&graph.Node{ID: "worker.Start.go#1", Kind: graph.NodeGoroutine, Name: "Start.go#1", Package: "worker", PackageName: "worker", SpawnedAt: "worker.go:12"})
+	g.AddTypedEdge("worker.Start", "worker.Start.go#1", types.SpawnDependency)
+	s := stability.NewAnalyzer().Analyze(g)
+
+	// goroutineノードは起動箇所とともに表示する
+	result := GenerateMermaid(g, s)
+	for _, expected := range []string{
+		"worker_Start_go_1((🧵 goroutine: Start.go#1<br>📍 worker.go:12<br>不安定度:0.00))",
+		"class worker_Start_go_1 goroutineStyle",
+		"worker_Start --> worker_Start_go_1",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("出力に %q が含まれていません:\n%s", expected, result)
+		}
+	}
+}
+
+func TestGenerateMermaidIsDeterministic(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.AddNode(&graph.Node{ID: "ext:github.com/lib/pq", Kind: graph.NodeExternal, Name: "github.com/lib/pq", Package: "github.com/lib/pq"})
+	g.AddNode(&graph.Node{ID: "ext:github.com/google/uuid", Kind: graph.NodeExternal, Name: "github.com/google/uuid", Package: "github.com/google/uuid"})
+	for _, pkg := range []string{"app", "model", "store"} {
+		for _, name := range []string{"A", "B", "C", "D"} {
+			id := types.NodeID(pkg + "." + name)
+			g.AddNode(&graph.Node{ID: id, Kind: graph.NodeStruct, Name: name, Package: pkg, PackageName: pkg})
+		}
+	}
+	// 不安定度が同じノード・複数のエッジを含むグラフ
+	for _, from := range []types.NodeID{"app.A", "app.B", "app.C", "app.D"} {
+		for _, to := range []types.NodeID{"model.A", "model.B", "store.C", "store.D", "ext:github.com/lib/pq", "ext:github.com/google/uuid"} {
+			g.AddTypedEdge(from, to, types.FieldDependency)
+		}
+	}
+	s := stability.NewAnalyzer().Analyze(g)
+
+	first := GenerateMermaidWithOptions(g, s, true)
+	for i := 0; i < 20; i++ {
+		if result := GenerateMermaidWithOptions(g, s, true); result != first {
+			t.Fatalf("同じグラフから異なる出力が生成されました:\n%s\n---\n%s", first, result)
+		}
+	}
+
+	// ノード・エッジはID順に出力する
+	if strings.Index(first, "app_A -->") > strings.Index(first, "app_B -->") ||
+		strings.Index(first, "app_A --> model_A") > strings.Index(first, "app_A --> model_B") ||
+		strings.Index(first, "    app_A[") > strings.Index(first, "    app_B[") {
+		t.Errorf("ノード・エッジがID順に出力されていません:\n%s", first)
+	}
+}
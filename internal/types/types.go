@@ -4,21 +4,57 @@ package types
 
 import (
 	"go/token"
+	"strings"
 )
 
 // NodeID はグラフのノードを一意に識別するIDです。
-// 通常は "package.Name" 形式の文字列で構成されます。
-// パッケージノードの場合は "package:name" 形式を使用します。
+// 通常は "importパス.Name" 形式の文字列で構成されます（例: "github.com/example/repo/model.User"）。
+// パッケージノードの場合は "package:importパス" 形式を使用します。
+// 外部依存ノードの場合は "external:モジュールパス"（標準ライブラリは "stdlib:importパス"）形式を使用します。
+// 生成コードをパッケージごとにまとめたノードの場合は "generated:importパス" 形式を使用します。
+// importパスが判明しない場合はimportパスの代わりにパッケージ名を使用します。
 type NodeID string
 
 // NewNodeID は通常のノード（構造体、インターフェース、関数）のIDを生成します。
-func NewNodeID(packageName, name string) NodeID {
-	return NodeID(packageName + "." + name)
+// packagePathには PackageID で得られるパッケージ識別子を指定します。
+func NewNodeID(packagePath, name string) NodeID {
+	return NodeID(packagePath + "." + name)
+}
+
+// NewMethodNodeID はメソッドノードのIDを生成します。
+// "importパス.レシーバ型名.メソッド名" 形式となり、同名の関数や他の型の同名メソッドと衝突しません。
+func NewMethodNodeID(packagePath, receiver, name string) NodeID {
+	return NewNodeID(packagePath, receiver+"."+name)
 }
 
 // NewPackageNodeID はパッケージノードのIDを生成します。
-func NewPackageNodeID(packageName string) NodeID {
-	return NodeID("package:" + packageName)
+func NewPackageNodeID(packagePath string) NodeID {
+	return NodeID("package:" + packagePath)
+}
+
+// NewExternalNodeID はサードパーティモジュールをまとめた外部依存ノードのIDを生成します。
+// 同じモジュールのパッケージへの参照は全て1つのノードにまとめられます。
+func NewExternalNodeID(modulePath string) NodeID {
+	return NodeID("external:" + modulePath)
+}
+
+// NewStdlibNodeID は標準ライブラリのパッケージを表す外部依存ノードのIDを生成します。
+func NewStdlibNodeID(importPath string) NodeID {
+	return NodeID("stdlib:" + importPath)
+}
+
+// NewGeneratedNodeID はパッケージ内の生成コードのノードを1つにまとめたノードのIDを生成します。
+func NewGeneratedNodeID(packageID string) NodeID {
+	return NodeID("generated:" + packageID)
+}
+
+// PackageID はノードIDの修飾に使用するパッケージ識別子を返します。
+// 同名パッケージの衝突を避けるためimportパスを優先し、不明な場合はパッケージ名を返します。
+func PackageID(path, name string) string {
+	if path != "" {
+		return path
+	}
+	return name
 }
 
 // IsPackageNode はNodeIDがパッケージノードのIDかどうかを判定します。
@@ -26,6 +62,28 @@ func (id NodeID) IsPackageNode() bool {
 	return len(id) > 8 && id[:8] == "package:"
 }
 
+// IsExternalNode はNodeIDがサードパーティモジュールの外部依存ノードのIDかどうかを判定します。
+func (id NodeID) IsExternalNode() bool {
+	return strings.HasPrefix(string(id), "external:") && len(id) > len("external:")
+}
+
+// IsStdlibNode はNodeIDが標準ライブラリの外部依存ノードのIDかどうかを判定します。
+func (id NodeID) IsStdlibNode() bool {
+	return strings.HasPrefix(string(id), "stdlib:") && len(id) > len("stdlib:")
+}
+
+// ExternalPath は外部依存ノードのIDからモジュールパス（標準ライブラリの場合はimportパス）を返します。
+// 外部依存ノードでない場合は空文字を返します。
+func (id NodeID) ExternalPath() string {
+	switch {
+	case id.IsExternalNode():
+		return strings.TrimPrefix(string(id), "external:")
+	case id.IsStdlibNode():
+		return strings.TrimPrefix(string(id), "stdlib:")
+	}
+	return ""
+}
+
 // String はNodeIDを文字列として返します。
 func (id NodeID) String() string {
 	return string(id)
@@ -45,6 +103,36 @@ const (
 	CrossPackageDependency
 	// PackageDependency はimport文によるパッケージ間依存関係です
 	PackageDependency
+	// ReceiverDependency はメソッドからレシーバ型への依存関係です
+	ReceiverDependency
+	// ImplementationDependency は型からその型が実装するインターフェースへの依存関係です
+	ImplementationDependency
+	// UnderlyingDependency は定義型・エイリアスからその定義に使われている型への依存関係です
+	UnderlyingDependency
+	// ConstraintDependency はジェネリックな宣言から型パラメータの制約に使われている型への依存関係です
+	// 型制約インターフェースからその型要素（例: ~int | MyInt）への依存関係も含みます
+	ConstraintDependency
+	// ReadDependency は関数本体や変数の初期化式からパッケージレベルの変数・定数を読み取る依存関係です
+	ReadDependency
+	// WriteDependency は関数本体からパッケージレベルの変数へ書き込む依存関係です
+	WriteDependency
+	// ExternalDependency は宣言からサードパーティモジュール・標準ライブラリのパッケージへの参照による依存関係です
+	ExternalDependency
+	// FuncValueDependency は関数・メソッドを呼び出さずに値として参照する依存関係です
+	// コールバックとしての受け渡し、変数・マップ・構造体リテラルのフィールドへの格納、戻り値等を含みます
+	FuncValueDependency
+	// InstantiationDependency は関数本体で型の値を生成する依存関係です
+	// 複合リテラル（T{...}）、new(T)、make([]T, n) を含みます
+	InstantiationDependency
+	// TypeUsageDependency は関数本体で型を値の生成以外に使用する依存関係です
+	// 型アサーション（x.(T)）、型スイッチのcase、型変換（T(x)）を含みます
+	TypeUsageDependency
+	// SpawnDependency は関数からgoroutineとして起動する関数リテラルのノードへの依存関係です
+	// goroutineノードを作成する場合のみ抽出されます
+	SpawnDependency
+	// EmbeddingDependency は構造体・インターフェースから埋め込んでいる型への依存関係です
+	// 埋め込んだ型のフィールドやメソッドは昇格するため、通常のフィールドより強い依存として扱います
+	EmbeddingDependency
 )
 
 // String はDependencyTypeを文字列として返します。
@@ -60,54 +148,228 @@ func (t DependencyType) String() string {
 		return "cross_package"
 	case PackageDependency:
 		return "package"
+	case ReceiverDependency:
+		return "receiver"
+	case ImplementationDependency:
+		return "implementation"
+	case UnderlyingDependency:
+		return "underlying"
+	case ConstraintDependency:
+		return "constraint"
+	case ReadDependency:
+		return "read"
+	case WriteDependency:
+		return "write"
+	case ExternalDependency:
+		return "external"
+	case FuncValueDependency:
+		return "func_value"
+	case InstantiationDependency:
+		return "instantiation"
+	case TypeUsageDependency:
+		return "type_usage"
+	case SpawnDependency:
+		return "spawn"
+	case EmbeddingDependency:
+		return "embedding"
 	default:
 		return "unknown"
 	}
 }
 
+// ParseDependencyType はString()が返す名前からDependencyTypeを求めます。
+// 該当する種類がない場合はfalseを返します。
+func ParseDependencyType(name string) (DependencyType, bool) {
+	for t := FieldDependency; t <= EmbeddingDependency; t++ {
+		if t.String() == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
 // DependencyInfo は依存関係情報を表す構造体です。
-// 依存元ノード、依存先ノード、依存関係の種類を定義します。
+// 依存元ノード、依存先ノード、依存関係の種類と、依存を生じさせているソース上の位置を定義します。
 type DependencyInfo struct {
-	From NodeID         // 依存元のノードID
-	To   NodeID         // 依存先のノードID
-	Type DependencyType // 依存関係の種類
+	From     NodeID         // 依存元のノードID
+	To       NodeID         // 依存先のノードID
+	Type     DependencyType // 依存関係の種類
+	Position token.Position // 依存を生じさせている箇所（不明な場合はゼロ値）
 }
 
 // StructInfo は構造体の情報を表します。
 // 構造体の基本情報（名前、パッケージ、ファイル位置等）とフィールド、
 // およびメソッドの情報を含みます。
 type StructInfo struct {
-	Name     string         // 構造体名
-	Package  string         // 所属パッケージ名
-	File     string         // 定義されているファイルパス
-	Position token.Position // ファイル内での位置情報
-	Fields   []FieldInfo    // 構造体が持つフィールドの一覧
-	Methods  []FuncInfo     // 構造体に関連付けられたメソッドの一覧
+	Name        string         // 構造体名
+	Package     string         // 所属パッケージ名
+	PackagePath string         // 所属パッケージのimportパス（不明な場合は空文字）
+	File        string         // 定義されているファイルパス
+	Position    token.Position // ファイル内での位置情報
+	TypeParams  []FieldInfo    // 型パラメータの一覧（Typeは制約）
+	Fields      []FieldInfo    // 構造体が持つフィールドの一覧
+	Methods     []FuncInfo     // 構造体に関連付けられたメソッドの一覧
+}
+
+// NodeID は構造体ノードのIDを返します。
+func (s StructInfo) NodeID() NodeID {
+	return NewNodeID(PackageID(s.PackagePath, s.Package), s.Name)
 }
 
 // InterfaceInfo はインターフェースの情報を表します。
 // インターフェースの基本情報（名前、パッケージ、ファイル位置等）と
 // 定義されているメソッドの情報を含みます。
 type InterfaceInfo struct {
-	Name     string         // インターフェース名
-	Package  string         // 所属パッケージ名
-	File     string         // 定義されているファイルパス
-	Position token.Position // ファイル内での位置情報
-	Methods  []FuncInfo     // インターフェースで定義されているメソッドの一覧
+	Name        string         // インターフェース名
+	Package     string         // 所属パッケージ名
+	PackagePath string         // 所属パッケージのimportパス（不明な場合は空文字）
+	File        string         // 定義されているファイルパス
+	Position    token.Position // ファイル内での位置情報
+	TypeParams  []FieldInfo    // 型パラメータの一覧（Typeは制約）
+	Methods     []FuncInfo     // インターフェースで定義されているメソッドの一覧
+	Embeds      []string       // 埋め込まれているインターフェースの型名一覧（型制約の型要素も含む）
+}
+
+// NodeID はインターフェースノードのIDを返します。
+func (i InterfaceInfo) NodeID() NodeID {
+	return NewNodeID(PackageID(i.PackagePath, i.Package), i.Name)
+}
+
+// TypeKind は構造体・インターフェース以外の名前付き型の種類を表す列挙型です。
+type TypeKind int
+
+const (
+	// DefinedTypeKind は基本型や他の名前付き型を基底とする定義型です（例: type Status int）
+	DefinedTypeKind TypeKind = iota
+	// FuncTypeKind は関数型です（例: type HandlerFunc func(w Writer)）
+	FuncTypeKind
+	// CollectionTypeKind はスライス・配列・マップ・チャネル型です（例: type IDs []ID）
+	CollectionTypeKind
+	// AliasTypeKind は型エイリアスです（例: type X = Y）
+	AliasTypeKind
+)
+
+// String はTypeKindを文字列として返します。
+func (k TypeKind) String() string {
+	switch k {
+	case DefinedTypeKind:
+		return "defined"
+	case FuncTypeKind:
+		return "func_type"
+	case CollectionTypeKind:
+		return "collection"
+	case AliasTypeKind:
+		return "alias"
+	default:
+		return "unknown"
+	}
+}
+
+// TypeInfo は構造体・インターフェース以外の名前付き型の情報を表します。
+// 定義型、関数型、スライス・マップ等のコレクション型、型エイリアスを扱います。
+type TypeInfo struct {
+	Name        string         // 型名
+	Package     string         // 所属パッケージ名
+	PackagePath string         // 所属パッケージのimportパス（不明な場合は空文字）
+	File        string         // 定義されているファイルパス
+	Position    token.Position // ファイル内での位置情報
+	Kind        TypeKind       // 型の種類
+	TypeParams  []FieldInfo    // 型パラメータの一覧（Typeは制約）
+	Underlying  string         // 定義に使われている型の文字列表現（例: "int", "[]ID"）
+}
+
+// NodeID は名前付き型ノードのIDを返します。
+func (t TypeInfo) NodeID() NodeID {
+	return NewNodeID(PackageID(t.PackagePath, t.Package), t.Name)
+}
+
+// ValueKind はパッケージレベルの値宣言の種類を表す列挙型です。
+type ValueKind int
+
+const (
+	// VarKind はパッケージレベルの変数です（例: var DefaultClient = NewClient()）
+	VarKind ValueKind = iota
+	// ConstKind はパッケージレベルの定数です（例: const MaxRetry = 3）
+	ConstKind
+)
+
+// String はValueKindを文字列として返します。
+func (k ValueKind) String() string {
+	switch k {
+	case VarKind:
+		return "var"
+	case ConstKind:
+		return "const"
+	default:
+		return "unknown"
+	}
+}
+
+// ValueInfo はパッケージレベルの変数・定数の情報を表します。
+type ValueInfo struct {
+	Name        string         // 変数・定数名
+	Package     string         // 所属パッケージ名
+	PackagePath string         // 所属パッケージのimportパス（不明な場合は空文字）
+	File        string         // 定義されているファイルパス
+	Position    token.Position // ファイル内での位置情報
+	Kind        ValueKind      // 変数か定数か
+	Type        string         // 宣言された型の文字列表現（型が省略されている場合は空文字）
+}
+
+// NodeID は変数・定数ノードのIDを返します。
+func (v ValueInfo) NodeID() NodeID {
+	return NewNodeID(PackageID(v.PackagePath, v.Package), v.Name)
 }
 
 // FuncInfo は関数・メソッドの情報を表します。
 // 関数の基本情報（名前、パッケージ、ファイル位置等）とシグネチャ、
 // および関数本体での呼び出し情報を含みます。
 type FuncInfo struct {
-	Name      string         // 関数・メソッド名
-	Package   string         // 所属パッケージ名
-	File      string         // 定義されているファイルパス
-	Position  token.Position // ファイル内での位置情報
-	Receiver  string         // レシーバ型名（メソッドの場合のみ設定される）
-	Params    []FieldInfo    // 引数の一覧
-	Results   []FieldInfo    // 戻り値の一覧
-	BodyCalls []string       // 関数本体内で呼び出している関数名の一覧
+	Name        string         // 関数・メソッド名
+	Package     string         // 所属パッケージ名
+	PackagePath string         // 所属パッケージのimportパス（不明な場合は空文字）
+	File        string         // 定義されているファイルパス
+	Position    token.Position // ファイル内での位置情報
+	Receiver    string         // レシーバ型名（メソッドの場合のみ設定され、インターフェースメソッドではインターフェース名）
+	TypeParams  []FieldInfo    // 型パラメータの一覧（Typeは制約）
+	Params      []FieldInfo    // 引数の一覧
+	Results     []FieldInfo    // 戻り値の一覧
+	BodyCalls   []string       // 関数本体内で呼び出している関数名の一覧
+}
+
+// NodeID は関数ノードのIDを返します。
+// メソッドの場合はレシーバ型名を含むメソッドノードのIDを返します。
+func (f FuncInfo) NodeID() NodeID {
+	if f.Receiver != "" {
+		return NewMethodNodeID(PackageID(f.PackagePath, f.Package), f.Receiver, f.Name)
+	}
+	return NewNodeID(PackageID(f.PackagePath, f.Package), f.Name)
+}
+
+// ReceiverNodeID はメソッドのレシーバ型ノードのIDを返します。
+// 関数の場合は空文字を返します。
+func (f FuncInfo) ReceiverNodeID() NodeID {
+	if f.Receiver == "" {
+		return ""
+	}
+	return NewNodeID(PackageID(f.PackagePath, f.Package), f.Receiver)
+}
+
+// GoroutineInfo はgoroutineとして起動される関数リテラル（go func() { ... }()）の情報を表します。
+// 関数リテラルの中の依存関係は通常は外側の関数・メソッドのものとして扱いますが、
+// goroutineノードを作成する場合はこのノードのものとして扱います。
+type GoroutineInfo struct {
+	Name        string         // 表示名（"起動元の関数名.go#出現順"、メソッドの場合は "Type.Method.go#出現順"）
+	Package     string         // 所属パッケージ名
+	PackagePath string         // 所属パッケージのimportパス（不明な場合は空文字）
+	File        string         // 起動しているファイルパス
+	Position    token.Position // goステートメントの位置（起動箇所）
+	Spawner     NodeID         // 起動元のノードID（goroutineの中で起動された場合は外側のgoroutineのノードID）
+}
+
+// NodeID はgoroutineノードのIDを返します。
+func (g GoroutineInfo) NodeID() NodeID {
+	return NewNodeID(PackageID(g.PackagePath, g.Package), g.Name)
 }
 
 // FieldInfo はフィールドの情報を表します。
@@ -122,7 +384,8 @@ type FieldInfo struct {
 // import文の情報を含みます。
 type PackageInfo struct {
 	Name     string         // パッケージ名
-	Path     string         // パッケージパス（現在未使用）
+	Path     string         // パッケージのimportパス（go.modが見つからない場合は空文字）
+	Module   string         // パッケージが属するモジュールのパス（go.modが見つからない場合は空文字）
 	File     string         // パッケージ宣言があるファイルパス
 	Position token.Position // ファイル内での位置情報
 	Imports  []ImportInfo   // パッケージがimportしているパッケージの一覧
@@ -141,13 +404,23 @@ type ImportInfo struct {
 type Result struct {
 	Structs      []StructInfo     // 抽出された構造体の一覧
 	Interfaces   []InterfaceInfo  // 抽出されたインターフェースの一覧
+	Types        []TypeInfo       // 抽出された構造体・インターフェース以外の名前付き型の一覧
 	Functions    []FuncInfo       // 抽出された関数の一覧
+	Methods      []FuncInfo       // 抽出されたメソッドの一覧（レシーバ型の種類を問わない）
+	Inits        []FuncInfo       // 抽出されたinit関数の一覧（同一パッケージのinit関数は1つのノードにまとめられる）
+	Values       []ValueInfo      // 抽出されたパッケージレベルの変数・定数の一覧（IncludeGlobalsオプション指定時のみ）
+	Goroutines   []GoroutineInfo  // 抽出されたgoroutineとして起動される関数リテラルの一覧（GoroutineNodesオプション指定時のみ）
 	Packages     []PackageInfo    // 解析対象パッケージの一覧
 	Dependencies []DependencyInfo // 抽出された依存関係の一覧
+
+	Platforms     []string            // 解析したプラットフォーム（GOOS/GOARCH）の一覧（複数のプラットフォームを解析した場合のみ）
+	FilePlatforms map[string][]string // 一部のプラットフォームでのみビルド対象となるファイルのパス -> そのプラットフォームの一覧
+
+	GeneratedFiles []string // 生成コード（"// Code generated ... DO NOT EDIT." のヘッダを持つファイル）のパスの一覧
 }
 
 // CreateNodeMap は解析結果から全ノードの存在チェック用マップを作成します。
-// 構造体、インターフェース、関数の全てのノードIDを登録し、
+// 構造体、インターフェース、名前付き型、関数、メソッド（インターフェースメソッドを含む）、init関数、変数・定数、goroutineの全てのノードIDを登録し、
 // 依存先ノードの存在確認に使用されます。
 // このメソッドは主に依存関係抽出で使用されます。
 func (r *Result) CreateNodeMap() map[NodeID]struct{} {
@@ -155,20 +428,45 @@ func (r *Result) CreateNodeMap() map[NodeID]struct{} {
 
 	// 構造体ノード登録
 	for _, s := range r.Structs {
-		id := NewNodeID(s.Package, s.Name)
-		nodeMap[id] = struct{}{}
+		nodeMap[s.NodeID()] = struct{}{}
 	}
 
-	// インターフェースノード登録
+	// インターフェースノードとインターフェースメソッドノード登録
 	for _, i := range r.Interfaces {
-		id := NewNodeID(i.Package, i.Name)
-		nodeMap[id] = struct{}{}
+		nodeMap[i.NodeID()] = struct{}{}
+		for _, m := range i.Methods {
+			nodeMap[m.NodeID()] = struct{}{}
+		}
+	}
+
+	// 名前付き型ノード登録
+	for _, t := range r.Types {
+		nodeMap[t.NodeID()] = struct{}{}
 	}
 
 	// 関数ノード登録
 	for _, f := range r.Functions {
-		id := NewNodeID(f.Package, f.Name)
-		nodeMap[id] = struct{}{}
+		nodeMap[f.NodeID()] = struct{}{}
+	}
+
+	// init関数ノード登録
+	for _, f := range r.Inits {
+		nodeMap[f.NodeID()] = struct{}{}
+	}
+
+	// 変数・定数ノード登録
+	for _, v := range r.Values {
+		nodeMap[v.NodeID()] = struct{}{}
+	}
+
+	// goroutineノード登録
+	for _, g := range r.Goroutines {
+		nodeMap[g.NodeID()] = struct{}{}
+	}
+
+	// メソッドノード登録
+	for _, m := range r.Methods {
+		nodeMap[m.NodeID()] = struct{}{}
 	}
 
 	return nodeMap
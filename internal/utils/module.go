@@ -0,0 +1,338 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Module はgo.modから読み取ったモジュール情報
+type Module struct {
+	Path      string        // モジュールパス（例: github.com/example/repo）
+	Dir       string        // go.modが存在するディレクトリの絶対パス
+	Replaces  []LocalModule // ローカルディレクトリを指すreplaceディレクティブの一覧（go.workのreplaceを含む）
+	Requires  []string      // requireディレクティブで指定されたモジュールパスの一覧
+	Workspace []LocalModule // go.workで同じワークスペースに含まれる他のモジュールの一覧（ワークスペース外の場合は空）
+}
+
+// LocalModule はローカルディレクトリに存在する別モジュール（replaceの置き換え先やgo.workのメンバー）
+type LocalModule struct {
+	Path string // モジュールパス
+	Dir  string // モジュールのディレクトリの絶対パス
+}
+
+// ImportKind はimportパスの分類を表す列挙型
+type ImportKind int
+
+const (
+	// StandardImport は標準ライブラリのパッケージ
+	StandardImport ImportKind = iota
+	// SameModuleImport はimportしているファイルと同じモジュールのパッケージ
+	SameModuleImport
+	// LocalModuleImport はreplaceディレクティブでローカルディレクトリに置き換えられた、またはgo.workで同じワークスペースに含まれる別モジュールのパッケージ
+	LocalModuleImport
+	// ThirdPartyImport はサードパーティモジュールのパッケージ
+	ThirdPartyImport
+)
+
+// String はImportKindを文字列として返す
+func (k ImportKind) String() string {
+	switch k {
+	case StandardImport:
+		return "standard"
+	case SameModuleImport:
+		return "same_module"
+	case LocalModuleImport:
+		return "local_module"
+	case ThirdPartyImport:
+		return "third_party"
+	default:
+		return "unknown"
+	}
+}
+
+// IsLocal はリポジトリ内のコード（同じモジュールまたはローカルの別モジュール）かどうかを返す
+func (k ImportKind) IsLocal() bool {
+	return k == SameModuleImport || k == LocalModuleImport
+}
+
+var (
+	// ディレクトリからモジュール情報へのキャッシュ
+	moduleCache   = make(map[string]*Module)
+	moduleCacheMu sync.Mutex
+)
+
+// FindModule は指定ディレクトリから親方向にgo.modを探索し、モジュール情報を返す
+// go.modが見つからない場合はnilを返す
+func FindModule(dir string) *Module {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+
+	moduleCacheMu.Lock()
+	defer moduleCacheMu.Unlock()
+
+	if mod, ok := moduleCache[absDir]; ok {
+		return mod
+	}
+
+	var mod *Module
+	for current := absDir; ; current = filepath.Dir(current) {
+		data, err := os.ReadFile(filepath.Join(current, "go.mod"))
+		if err == nil {
+			if modulePath := ParseModulePath(data); modulePath != "" {
+				mod = &Module{
+					Path:     modulePath,
+					Dir:      current,
+					Replaces: parseLocalReplaces(data, current),
+					Requires: parseRequires(data),
+				}
+				joinWorkspace(mod)
+			}
+			break
+		}
+		if filepath.Dir(current) == current {
+			break
+		}
+	}
+
+	moduleCache[absDir] = mod
+	return mod
+}
+
+// ClearModuleCache はFindModule・FindWorkspaceのキャッシュを破棄する
+// go.mod・go.workが変更された可能性がある場合（監視モードでの再解析等）に使用する
+func ClearModuleCache() {
+	moduleCacheMu.Lock()
+	defer moduleCacheMu.Unlock()
+	moduleCache = make(map[string]*Module)
+	workspaceCache = make(map[string]*Workspace)
+}
+
+// ParseModulePath はgo.modの内容からmoduleディレクティブのモジュールパスを抽出
+func ParseModulePath(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		if idx := strings.Index(line, "//"); idx != -1 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "module" {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(fields[1]); err == nil {
+			return unquoted
+		}
+		return fields[1]
+	}
+	return ""
+}
+
+// parseLocalReplaces はgo.modの内容から、ローカルディレクトリを指すreplaceディレクティブを抽出する
+// 単一行の形式（replace a => ../a）とブロック形式（replace ( ... )）の両方に対応する
+// 置き換え先が相対パスの場合はgo.modのディレクトリdirを基準に絶対パスへ変換する
+func parseLocalReplaces(data []byte, dir string) []LocalModule {
+	var replaces []LocalModule
+	inBlock := false
+	for _, line := range strings.Split(string(data), "\n") {
+		if idx := strings.Index(line, "//"); idx != -1 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case inBlock && fields[0] == ")":
+			inBlock = false
+			continue
+		case !inBlock && fields[0] == "replace":
+			if len(fields) == 2 && fields[1] == "(" {
+				inBlock = true
+				continue
+			}
+			fields = fields[1:]
+		case !inBlock:
+			continue
+		}
+
+		// old [version] => new [version]
+		arrow := slices.Index(fields, "=>")
+		if arrow < 1 || arrow+1 >= len(fields) {
+			continue
+		}
+		oldPath, newPath := unquote(fields[0]), unquote(fields[arrow+1])
+		if !isLocalReplacement(newPath) {
+			continue
+		}
+		if !filepath.IsAbs(newPath) {
+			newPath = filepath.Join(dir, filepath.FromSlash(newPath))
+		}
+		replaces = append(replaces, LocalModule{Path: oldPath, Dir: filepath.Clean(newPath)})
+	}
+	return replaces
+}
+
+// parseRequires はgo.modの内容から、requireディレクティブで指定されたモジュールパスを抽出する
+// 単一行の形式（require a v1.0.0）とブロック形式（require ( ... )）の両方に対応する
+func parseRequires(data []byte) []string {
+	var requires []string
+	inBlock := false
+	for _, line := range strings.Split(string(data), "\n") {
+		if idx := strings.Index(line, "//"); idx != -1 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case inBlock && fields[0] == ")":
+			inBlock = false
+			continue
+		case !inBlock && fields[0] == "require":
+			if len(fields) == 2 && fields[1] == "(" {
+				inBlock = true
+				continue
+			}
+			fields = fields[1:]
+		case !inBlock:
+			continue
+		}
+		// path version
+		if len(fields) == 2 {
+			requires = append(requires, unquote(fields[0]))
+		}
+	}
+	return requires
+}
+
+// isLocalReplacement はreplaceの置き換え先がファイルシステム上のパスかどうかを判定する
+// goコマンドと同じく、./ ../ で始まるパスと絶対パスをローカルとみなす
+func isLocalReplacement(path string) bool {
+	return strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../") || filepath.IsAbs(path) ||
+		path == "." || path == ".."
+}
+
+func unquote(s string) string {
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return s
+}
+
+// Classify はimportパスを、このモジュールから見た種類に分類する
+// mがnil（go.modが見つからない）の場合はモジュールを判別できないため、標準ライブラリ以外を同じモジュールとして扱う
+func (m *Module) Classify(importPath string) ImportKind {
+	if strings.HasPrefix(importPath, ".") {
+		return SameModuleImport // 相対importは常に同じモジュール
+	}
+	if m != nil {
+		if hasPathPrefix(importPath, m.Path) {
+			return SameModuleImport
+		}
+		for _, r := range slices.Concat(m.Workspace, m.Replaces) {
+			if hasPathPrefix(importPath, r.Path) {
+				return LocalModuleImport
+			}
+		}
+	}
+	if IsStandardLibrary(importPath) {
+		return StandardImport
+	}
+	if m == nil {
+		return SameModuleImport
+	}
+	return ThirdPartyImport
+}
+
+// Restrict はreplaceの置き換え先とgo.workのメンバーのうち、ディレクトリがanalyzedに含まれるモジュールだけを残したコピーを返す
+// 解析対象でない別モジュール（除外したディレクトリや解析対象外のreplaceの置き換え先）の宣言はノードにならないため、
+// Restrictしたモジュールでは、そのimportをサードパーティのモジュールとして分類し、外部依存ノードにモジュール単位でまとめる
+func (m *Module) Restrict(analyzed map[string]bool) *Module {
+	if m == nil {
+		return nil
+	}
+	restricted := *m
+	restricted.Requires = slices.Clone(m.Requires)
+	keep := func(modules []LocalModule) []LocalModule {
+		var kept []LocalModule
+		for _, local := range modules {
+			if analyzed[local.Dir] {
+				kept = append(kept, local)
+			} else {
+				restricted.Requires = append(restricted.Requires, local.Path)
+			}
+		}
+		return kept
+	}
+	restricted.Replaces = keep(m.Replaces)
+	restricted.Workspace = keep(m.Workspace)
+	return &restricted
+}
+
+// ClassifyImport はdirにあるファイルからimportされたimportパスを分類する
+func ClassifyImport(dir, importPath string) ImportKind {
+	return FindModule(dir).Classify(importPath)
+}
+
+// hasPathPrefix はimportパスがモジュールパスprefixのモジュールに含まれるかを判定する
+func hasPathPrefix(importPath, prefix string) bool {
+	return importPath == prefix || strings.HasPrefix(importPath, prefix+"/")
+}
+
+// ModulePathOf はサードパーティのimportパスが属するモジュールのパスを返す
+// go.modのrequireディレクティブのうち最も長く一致するモジュールパスを返し、
+// 一致するものがない場合はホスティングサービスの慣習（github.com/owner/repo等）から推定する
+// 推定もできない場合はimportパスをそのまま返す
+func (m *Module) ModulePathOf(importPath string) string {
+	best := ""
+	if m != nil {
+		for _, r := range m.Requires {
+			if hasPathPrefix(importPath, r) && len(r) > len(best) {
+				best = r
+			}
+		}
+	}
+	if best != "" {
+		return best
+	}
+	parts := strings.Split(importPath, "/")
+	switch parts[0] {
+	case "github.com", "gitlab.com", "bitbucket.org":
+		if len(parts) >= 3 {
+			return strings.Join(parts[:3], "/")
+		}
+	}
+	return importPath
+}
+
+// ImportPath はモジュール内のディレクトリに対応するimportパスを返す
+// ディレクトリがモジュール外の場合はfalseを返す
+func (m *Module) ImportPath(dir string) (string, bool) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(m.Dir, absDir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	if rel == "." {
+		return m.Path, true
+	}
+	return m.Path + "/" + filepath.ToSlash(rel), true
+}
+
+// ImportPathOf はGoファイルが属するパッケージのimportパスを返す
+// go.modが見つからない場合やモジュール外のファイルの場合は空文字を返す
+func ImportPathOf(filePath string) string {
+	dir := filepath.Dir(filePath)
+	if mod := FindModule(dir); mod != nil {
+		if importPath, ok := mod.ImportPath(dir); ok {
+			return importPath
+		}
+	}
+	return ""
+}
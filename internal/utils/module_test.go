@@ -0,0 +1,212 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseModulePath(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"通常のmodule", "module github.com/user/repo\n\ngo 1.23\n", "github.com/user/repo"},
+		{"クォート付き", "module \"example.com/quoted\"\n", "example.com/quoted"},
+		{"コメント付き", "// header\nmodule example.com/mod // comment\n", "example.com/mod"},
+		{"moduleなし", "go 1.23\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := ParseModulePath([]byte(tt.content)); result != tt.expected {
+				t.Errorf("ParseModulePath() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestImportPathOf(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n"), 0644); err != nil {
+		t.Fatalf("go.mod作成失敗: %v", err)
+	}
+
+	tests := []struct {
+		file     string
+		expected string
+	}{
+		{filepath.Join(root, "main.go"), "example.com/app"},
+		{filepath.Join(root, "internal", "handler", "user.go"), "example.com/app/internal/handler"},
+		{filepath.Join(root, "api", "handler", "user.go"), "example.com/app/api/handler"},
+	}
+
+	for _, tt := range tests {
+		if result := ImportPathOf(tt.file); result != tt.expected {
+			t.Errorf("ImportPathOf(%s) = %q, expected %q", tt.file, result, tt.expected)
+		}
+	}
+
+	// go.modが存在しない場合は空文字
+	if result := ImportPathOf(filepath.Join(t.TempDir(), "x.go")); result != "" {
+		t.Errorf("go.modなしのImportPathOf() = %q, expected empty", result)
+	}
+}
+
+func TestParseLocalReplaces(t *testing.T) {
+	dir := filepath.Join(string(filepath.Separator), "repo", "app")
+	content := `module example.com/app
+
+require example.com/lib v1.0.0
+
+replace example.com/lib => ../lib // ローカル
+replace example.com/remote => example.com/fork v1.2.0
+
+replace (
+	example.com/tools v1.0.0 => ./tools
+	"example.com/quoted" => "/abs/quoted"
+	example.com/other => github.com/other/other v0.1.0
+)
+`
+	expected := []LocalModule{
+		{Path: "example.com/lib", Dir: filepath.Join(string(filepath.Separator), "repo", "lib")},
+		{Path: "example.com/tools", Dir: filepath.Join(dir, "tools")},
+		{Path: "example.com/quoted", Dir: filepath.Join(string(filepath.Separator), "abs", "quoted")},
+	}
+
+	result := parseLocalReplaces([]byte(content), dir)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("parseLocalReplaces() = %+v, expected %+v", result, expected)
+	}
+}
+
+func TestModule_Classify(t *testing.T) {
+	module := &Module{
+		Path:     "example.com/app",
+		Dir:      "/repo/app",
+		Replaces: []LocalModule{{Path: "example.com/lib", Dir: "/repo/lib"}},
+	}
+
+	tests := []struct {
+		importPath string
+		expected   ImportKind
+	}{
+		{"fmt", StandardImport},
+		{"net/http", StandardImport},
+		{"example.com/app", SameModuleImport},
+		{"example.com/app/internal/model", SameModuleImport},
+		{"./local", SameModuleImport},
+		{"example.com/lib/util", LocalModuleImport},
+		{"example.com/library", ThirdPartyImport}, // モジュールパスの前方一致はパス要素単位
+		{"example.com/application", ThirdPartyImport},
+		{"github.com/spf13/cobra", ThirdPartyImport},
+	}
+	for _, tt := range tests {
+		if result := module.Classify(tt.importPath); result != tt.expected {
+			t.Errorf("Classify(%q) = %v, expected %v", tt.importPath, result, tt.expected)
+		}
+	}
+
+	// go.modがない場合は標準ライブラリ以外を同じモジュールとして扱う
+	var noModule *Module
+	if result := noModule.Classify("github.com/spf13/cobra"); result != SameModuleImport {
+		t.Errorf("go.modなしのClassify() = %v, expected %v", result, SameModuleImport)
+	}
+	if result := noModule.Classify("fmt"); result != StandardImport {
+		t.Errorf("go.modなしのClassify(fmt) = %v, expected %v", result, StandardImport)
+	}
+}
+
+func TestModule_Restrict(t *testing.T) {
+	module := &Module{
+		Path:      "example.com/app",
+		Dir:       "/repo/app",
+		Replaces:  []LocalModule{{Path: "example.com/lib", Dir: "/repo/lib"}},
+		Requires:  []string{"example.com/lib"},
+		Workspace: []LocalModule{{Path: "example.com/core", Dir: "/repo/core"}, {Path: "example.com/tools", Dir: "/repo/tools"}},
+	}
+
+	// 解析対象のモジュールだけがローカルのまま残り、それ以外はサードパーティとして扱う
+	restricted := module.Restrict(map[string]bool{"/repo/app": true, "/repo/core": true})
+	tests := map[string]ImportKind{
+		"example.com/app/model":  SameModuleImport,
+		"example.com/core/model": LocalModuleImport,
+		"example.com/lib/util":   ThirdPartyImport,
+		"example.com/tools/gen":  ThirdPartyImport,
+	}
+	for importPath, expected := range tests {
+		if result := restricted.Classify(importPath); result != expected {
+			t.Errorf("Classify(%q) = %v, expected %v", importPath, result, expected)
+		}
+	}
+	// 外部依存ノードは解析対象外のモジュール単位でまとめる
+	if result := restricted.ModulePathOf("example.com/tools/gen/internal"); result != "example.com/tools" {
+		t.Errorf("ModulePathOf() = %q, expected %q", result, "example.com/tools")
+	}
+	// 元のモジュール情報は変更しない
+	if len(module.Workspace) != 2 || len(module.Requires) != 1 || module.Classify("example.com/lib/util") != LocalModuleImport {
+		t.Errorf("元のモジュール情報が変更されています: %+v", module)
+	}
+
+	var noModule *Module
+	if noModule.Restrict(nil) != nil {
+		t.Error("go.modなしのRestrict() はnilを返すべきです")
+	}
+}
+
+func TestClassifyImport(t *testing.T) {
+	root := t.TempDir()
+	content := "module example.com/app\n\nreplace example.com/lib => ../lib\n"
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte(content), 0644); err != nil {
+		t.Fatalf("go.mod作成失敗: %v", err)
+	}
+	ClearModuleCache()
+
+	dir := filepath.Join(root, "internal", "handler")
+	tests := map[string]ImportKind{
+		"example.com/app/model": SameModuleImport,
+		"example.com/lib/util":  LocalModuleImport,
+		"github.com/x/y":        ThirdPartyImport,
+		"strings":               StandardImport,
+	}
+	for importPath, expected := range tests {
+		if result := ClassifyImport(dir, importPath); result != expected {
+			t.Errorf("ClassifyImport(%q) = %v, expected %v", importPath, result, expected)
+		}
+	}
+}
+
+func TestModule_ModulePathOf(t *testing.T) {
+	content := `module example.com/app
+
+require github.com/spf13/cobra v1.9.1
+
+require (
+	golang.org/x/tools v0.30.0 // indirect
+	golang.org/x/tools/gopls v0.18.0
+	"gopkg.in/yaml.v3" v3.0.1
+)
+`
+	module := &Module{Path: "example.com/app", Requires: parseRequires([]byte(content))}
+	expectedRequires := []string{"github.com/spf13/cobra", "golang.org/x/tools", "golang.org/x/tools/gopls", "gopkg.in/yaml.v3"}
+	if !reflect.DeepEqual(module.Requires, expectedRequires) {
+		t.Fatalf("parseRequires() = %v, expected %v", module.Requires, expectedRequires)
+	}
+
+	tests := map[string]string{
+		"github.com/spf13/cobra":             "github.com/spf13/cobra",
+		"github.com/spf13/cobra/doc":         "github.com/spf13/cobra",
+		"golang.org/x/tools/go/packages":     "golang.org/x/tools",
+		"golang.org/x/tools/gopls/internal":  "golang.org/x/tools/gopls", // 最も長く一致するモジュール
+		"gopkg.in/yaml.v3":                   "gopkg.in/yaml.v3",
+		"github.com/stretchr/testify/assert": "github.com/stretchr/testify", // requireにない場合はホスティングサービスの慣習で推定
+		"example.org/unknown/pkg":            "example.org/unknown/pkg",
+	}
+	for importPath, expected := range tests {
+		if result := module.ModulePathOf(importPath); result != expected {
+			t.Errorf("ModulePathOf(%q) = %q, expected %q", importPath, result, expected)
+		}
+	}
+}
@@ -138,14 +138,3 @@ func ExtractPackageName(importPath string) string {
 	parts := strings.Split(importPath, "/")
 	return parts[len(parts)-1]
 }
-
-// IsLocalPackage は指定されたimportパスが同リポジトリ内のパッケージかどうかを判定
-func IsLocalPackage(importPath string) bool {
-	// 相対パスは常にローカルパッケージ
-	if strings.HasPrefix(importPath, ".") {
-		return true
-	}
-
-	// 標準ライブラリの判定を改善
-	return !IsStandardLibrary(importPath)
-}
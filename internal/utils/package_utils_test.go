@@ -69,42 +69,6 @@ func TestExtractPackageName(t *testing.T) {
 	}
 }
 
-func TestIsLocalPackage(t *testing.T) {
-	tests := []struct {
-		importPath string
-		expected   bool
-	}{
-		// 標準ライブラリ（ローカルではない）
-		{"fmt", false},
-		{"time", false},
-		{"net/http", false},
-		{"encoding/json", false},
-
-		// 外部パッケージ（ローカル）
-		{"github.com/user/repo", true},
-		{"example.com/pkg", true},
-
-		// 相対パス（ローカル）
-		{"./relative", true},
-		{"../relative", true},
-
-		// エッジケース
-		{"", true}, // 標準ライブラリではないのでローカル扱い
-		{".", true},
-
-		// 単一パッケージ名（標準ライブラリではないのでローカル扱い）
-		{"mypackage", true},
-		{"customlib", true},
-	}
-
-	for _, test := range tests {
-		result := IsLocalPackage(test.importPath)
-		if result != test.expected {
-			t.Errorf("IsLocalPackage(%s) = %v, expected %v", test.importPath, result, test.expected)
-		}
-	}
-}
-
 func TestGetStandardLibrariesFallback(t *testing.T) {
 	fallbackLibs := getStandardLibrariesFallback()
 
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Workers は並列処理のワーカー数を返す
+// 0以下が指定された場合はGOMAXPROCSを使用する
+func Workers(n int) int {
+	if n <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return n
+}
+
+// ParallelFor は0からn-1までの各iについてfn(i)を最大workers個のゴルーチンで実行する
+// 結果をインデックスで書き分けることで、実行順序に依存しない決定的な結果を得られる
+// workersが1以下の場合は呼び出し元のゴルーチンで順番に実行する
+func ParallelFor(n, workers int, fn func(i int)) {
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+}
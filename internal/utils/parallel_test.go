@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWorkers(t *testing.T) {
+	if got := Workers(0); got != runtime.GOMAXPROCS(0) {
+		t.Errorf("Workers(0) = %d, expected GOMAXPROCS %d", got, runtime.GOMAXPROCS(0))
+	}
+	if got := Workers(3); got != 3 {
+		t.Errorf("Workers(3) = %d, expected 3", got)
+	}
+}
+
+func TestParallelFor(t *testing.T) {
+	for _, workers := range []int{1, 4, 100} {
+		results := make([]int, 50)
+		var running, maxRunning int32
+		ParallelFor(len(results), workers, func(i int) {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			results[i] = i * i
+			atomic.AddInt32(&running, -1)
+		})
+
+		for i, r := range results {
+			if r != i*i {
+				t.Errorf("workers=%d: results[%d] = %d, expected %d", workers, i, r, i*i)
+			}
+		}
+		if int(maxRunning) > workers {
+			t.Errorf("workers=%d: %d goroutines ran concurrently", workers, maxRunning)
+		}
+	}
+}
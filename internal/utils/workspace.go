@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Workspace はgo.workから読み取ったワークスペース情報
+type Workspace struct {
+	Dir      string        // go.workが存在するディレクトリの絶対パス
+	Modules  []LocalModule // useディレクティブで指定されたモジュールの一覧（go.modが読めないディレクトリは除く）
+	Replaces []LocalModule // ローカルディレクトリを指すreplaceディレクティブの一覧
+}
+
+// ディレクトリからワークスペース情報へのキャッシュ（moduleCacheMuで保護する）
+var workspaceCache = make(map[string]*Workspace)
+
+// FindWorkspace は指定ディレクトリから親方向にgo.workを探索し、ワークスペース情報を返す
+// goコマンドと同じく環境変数GOWORKを優先し、offの場合やgo.workが見つからない場合はnilを返す
+func FindWorkspace(dir string) *Workspace {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+
+	moduleCacheMu.Lock()
+	defer moduleCacheMu.Unlock()
+	return findWorkspace(absDir)
+}
+
+// findWorkspace はFindWorkspaceの本体（moduleCacheMuを保持した状態で呼び出す）
+func findWorkspace(absDir string) *Workspace {
+	if ws, ok := workspaceCache[absDir]; ok {
+		return ws
+	}
+
+	var ws *Workspace
+	switch gowork := os.Getenv("GOWORK"); {
+	case gowork == "off":
+	case gowork != "":
+		ws = readWorkspace(gowork)
+	default:
+		for current := absDir; ; current = filepath.Dir(current) {
+			if ws = readWorkspace(filepath.Join(current, "go.work")); ws != nil {
+				break
+			}
+			if filepath.Dir(current) == current {
+				break
+			}
+		}
+	}
+
+	workspaceCache[absDir] = ws
+	return ws
+}
+
+// readWorkspace はgo.workを読み込む。読み込めない場合はnilを返す
+func readWorkspace(path string) *Workspace {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil
+	}
+
+	ws := &Workspace{Dir: dir, Replaces: parseLocalReplaces(data, dir)}
+	for _, use := range parseUses(data, dir) {
+		modData, err := os.ReadFile(filepath.Join(use, "go.mod"))
+		if err != nil {
+			continue
+		}
+		if modulePath := ParseModulePath(modData); modulePath != "" {
+			ws.Modules = append(ws.Modules, LocalModule{Path: modulePath, Dir: use})
+		}
+	}
+	return ws
+}
+
+// parseUses はgo.workの内容から、useディレクティブで指定されたディレクトリを抽出する
+// 単一行の形式（use ./a）とブロック形式（use ( ... )）の両方に対応し、dirを基準に絶対パスへ変換する
+func parseUses(data []byte, dir string) []string {
+	var uses []string
+	inBlock := false
+	for _, line := range strings.Split(string(data), "\n") {
+		if idx := strings.Index(line, "//"); idx != -1 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case inBlock && fields[0] == ")":
+			inBlock = false
+			continue
+		case !inBlock && fields[0] == "use":
+			if len(fields) == 2 && fields[1] == "(" {
+				inBlock = true
+				continue
+			}
+			fields = fields[1:]
+		case !inBlock:
+			continue
+		}
+		if len(fields) != 1 {
+			continue
+		}
+		use := filepath.FromSlash(unquote(fields[0]))
+		if !filepath.IsAbs(use) {
+			use = filepath.Join(dir, use)
+		}
+		uses = append(uses, filepath.Clean(use))
+	}
+	return uses
+}
+
+// Contains はdirのモジュールがワークスペースのメンバーかどうかを判定する
+func (w *Workspace) Contains(dir string) bool {
+	return slices.ContainsFunc(w.Modules, func(m LocalModule) bool { return m.Dir == dir })
+}
+
+// joinWorkspace はモジュールがgo.workのメンバーの場合、他のメンバーとgo.workのreplaceをモジュール情報に加える
+// メンバーでないモジュールは、go.workがあってもワークスペースの外として扱う（moduleCacheMuを保持した状態で呼び出す）
+func joinWorkspace(mod *Module) {
+	ws := findWorkspace(mod.Dir)
+	if ws == nil || !ws.Contains(mod.Dir) {
+		return
+	}
+	for _, member := range ws.Modules {
+		if member.Dir != mod.Dir {
+			mod.Workspace = append(mod.Workspace, member)
+		}
+	}
+	// go.workのreplaceはgo.modのreplaceより優先される
+	mod.Replaces = append(slices.Clone(ws.Replaces), mod.Replaces...)
+}
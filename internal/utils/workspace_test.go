@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseUses(t *testing.T) {
+	dir := filepath.Join(string(filepath.Separator), "repo", "ws")
+	content := `go 1.23
+
+use ./api // API
+use (
+	./core
+	"../shared"
+)
+`
+	expected := []string{
+		filepath.Join(dir, "api"),
+		filepath.Join(dir, "core"),
+		filepath.Join(string(filepath.Separator), "repo", "shared"),
+	}
+	if result := parseUses([]byte(content), dir); !reflect.DeepEqual(result, expected) {
+		t.Errorf("parseUses() = %v, expected %v", result, expected)
+	}
+}
+
+func TestFindModule_Workspace(t *testing.T) {
+	t.Setenv("GOWORK", "")
+	root := t.TempDir()
+	ws := filepath.Join(root, "ws")
+	writeTestFile(t, filepath.Join(ws, "go.work"), "go 1.23\n\nuse (\n\t./api\n\t../shared\n)\n\nreplace example.com/lib => ../lib\n")
+	writeTestFile(t, filepath.Join(ws, "api", "go.mod"), "module example.com/api\n")
+	writeTestFile(t, filepath.Join(root, "shared", "go.mod"), "module example.com/shared\n")
+	writeTestFile(t, filepath.Join(ws, "tools", "go.mod"), "module example.com/tools\n") // useされていないモジュール
+	ClearModuleCache()
+	t.Cleanup(ClearModuleCache)
+
+	workspace := FindWorkspace(filepath.Join(ws, "api", "handler"))
+	if workspace == nil {
+		t.Fatal("go.workが見つかりません")
+	}
+	expectedModules := []LocalModule{
+		{Path: "example.com/api", Dir: filepath.Join(ws, "api")},
+		{Path: "example.com/shared", Dir: filepath.Join(root, "shared")},
+	}
+	if !reflect.DeepEqual(workspace.Modules, expectedModules) {
+		t.Errorf("Modules = %+v, expected %+v", workspace.Modules, expectedModules)
+	}
+
+	// メンバーのモジュールは他のメンバーとgo.workのreplaceをローカルとして扱う
+	api := FindModule(filepath.Join(ws, "api"))
+	if api == nil {
+		t.Fatal("apiモジュールが見つかりません")
+	}
+	for importPath, expected := range map[string]ImportKind{
+		"example.com/api/model":   SameModuleImport,
+		"example.com/shared/util": LocalModuleImport,
+		"example.com/lib":         LocalModuleImport,
+		"example.com/tools/gen":   ThirdPartyImport,
+	} {
+		if result := api.Classify(importPath); result != expected {
+			t.Errorf("api.Classify(%q) = %v, expected %v", importPath, result, expected)
+		}
+	}
+
+	// useされていないモジュールはワークスペースの外として扱う
+	tools := FindModule(filepath.Join(ws, "tools"))
+	if len(tools.Workspace) != 0 || tools.Classify("example.com/shared") != ThirdPartyImport {
+		t.Errorf("ワークスペース外のモジュールにメンバーが設定されています: %+v", tools.Workspace)
+	}
+
+	// GOWORK=offの場合はgo.workを無視する
+	t.Setenv("GOWORK", "off")
+	ClearModuleCache()
+	if FindWorkspace(ws) != nil || FindModule(filepath.Join(ws, "api")).Classify("example.com/shared") != ThirdPartyImport {
+		t.Error("GOWORK=offでgo.workが使われています")
+	}
+}
@@ -0,0 +1,159 @@
+package watch
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FileState はファイルの変更検知に使用する状態
+type FileState struct {
+	ModTime time.Time
+	Size    int64
+}
+
+// Snapshot はある時点での監視対象ファイルの状態（パスごと）
+type Snapshot map[string]FileState
+
+// IsWatched は解析結果に影響するファイル（.goファイル、go.mod、go.work）かどうかを判定する
+// テストファイル（_test.go）はtestsがtrueの場合のみ対象とする
+func IsWatched(path string, tests bool) bool {
+	name := filepath.Base(path)
+	if name == "go.mod" || name == "go.work" {
+		return true
+	}
+	return strings.HasSuffix(name, ".go") && (tests || !strings.HasSuffix(name, "_test.go"))
+}
+
+// Scan はdir配下の監視対象ファイルの状態を取得する（testsの意味はIsWatchedと同じ）
+// 隠しディレクトリ（.gitや.depsee等）は探索しない
+func Scan(dir string, tests bool) (Snapshot, error) {
+	snapshot := make(Snapshot)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil // 探索中に削除されたファイル等は無視して続行
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !IsWatched(path, tests) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		snapshot[path] = FileState{ModTime: info.ModTime(), Size: info.Size()}
+		return nil
+	})
+	return snapshot, err
+}
+
+// Diff はsからnextまでに追加・変更・削除されたファイルのパスをソートして返す
+func (s Snapshot) Diff(next Snapshot) []string {
+	var changed []string
+	for path, state := range next {
+		if prev, ok := s[path]; !ok || !prev.ModTime.Equal(state.ModTime) || prev.Size != state.Size {
+			changed = append(changed, path)
+		}
+	}
+	for path := range s {
+		if _, ok := next[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Poller は一定間隔でディレクトリを走査し、監視対象ファイルの変更を検知する
+// ファイルシステムの通知機構は使用しない
+type Poller struct {
+	roots    func() []string // 走査するディレクトリの一覧（走査のたびに求め直す）
+	interval time.Duration
+	tests    bool // テストファイルも監視するかどうか
+	snapshot Snapshot
+}
+
+// NewPoller はdirを監視するPollerを作成する（testsがtrueの場合はテストファイルも監視する）
+// 作成時点のファイルの状態が最初の比較対象となる
+func NewPoller(dir string, interval time.Duration, tests bool) (*Poller, error) {
+	return NewPollerWithRoots(func() []string { return []string{dir} }, interval, tests)
+}
+
+// NewPollerWithRoots はrootsが返す全てのディレクトリを監視するPollerを作成する
+// go.workのメンバーのように監視するディレクトリが変わりうる場合に使用し、rootsは走査のたびに呼び出す
+// rootsの最初の要素は監視対象のディレクトリで、これが存在しない場合のみエラーとする
+func NewPollerWithRoots(roots func() []string, interval time.Duration, tests bool) (*Poller, error) {
+	p := &Poller{roots: roots, interval: interval, tests: tests}
+	snapshot, err := p.scan()
+	if err != nil {
+		return nil, err
+	}
+	p.snapshot = snapshot
+	return p, nil
+}
+
+// Poll は前回の走査以降に追加・変更・削除されたファイルのパスを返す
+func (p *Poller) Poll() ([]string, error) {
+	next, err := p.scan()
+	if err != nil {
+		return nil, err
+	}
+	changed := p.snapshot.Diff(next)
+	p.snapshot = next
+	return changed, nil
+}
+
+// scan は全てのディレクトリを走査し、監視対象ファイルの状態を1つにまとめる
+// 最初のディレクトリ以外の走査に失敗した場合（削除されたワークスペースのメンバー等）は無視する
+func (p *Poller) scan() (Snapshot, error) {
+	snapshot := make(Snapshot)
+	for i, dir := range p.roots() {
+		s, err := Scan(dir, p.tests)
+		if err != nil {
+			if i == 0 {
+				return nil, err
+			}
+			continue
+		}
+		for path, state := range s {
+			snapshot[path] = state
+		}
+	}
+	return snapshot, nil
+}
+
+// Run はctxがキャンセルされるまで一定間隔で走査し、変更があればonChangeを呼び出す
+// onChangeの実行中に発生した変更は次回の走査で検知される
+func (p *Poller) Run(ctx context.Context, onChange func(changed []string)) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			changed, err := p.Poll()
+			if err != nil {
+				if os.IsNotExist(err) {
+					return err
+				}
+				continue
+			}
+			if len(changed) > 0 {
+				onChange(changed)
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIsWatched(t *testing.T) {
+	tests := map[string]bool{
+		"pkg/user.go":      true,
+		"go.mod":           true,
+		"go.work":          true,
+		"pkg/user_test.go": false,
+		"README.md":        false,
+		"go.sum":           false,
+	}
+	for path, want := range tests {
+		if got := IsWatched(path, false); got != want {
+			t.Errorf("IsWatched(%q) = %v, expected %v", path, got, want)
+		}
+	}
+	// テストも解析する場合はテストファイルも監視する
+	if !IsWatched("pkg/user_test.go", true) {
+		t.Error("IsWatched(pkg/user_test.go, true) = false, expected true")
+	}
+}
+
+func TestPoller_Poll(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "pkg", "user.go")
+	removed := filepath.Join(dir, "pkg", "removed.go")
+	writeFile(t, user, "package pkg\n")
+	writeFile(t, removed, "package pkg\n")
+
+	poller, err := NewPoller(dir, time.Millisecond, false)
+	if err != nil {
+		t.Fatalf("NewPoller() error: %v", err)
+	}
+	if changed, _ := poller.Poll(); len(changed) != 0 {
+		t.Errorf("変更がないのに %v が検知されました", changed)
+	}
+
+	added := filepath.Join(dir, "pkg", "added.go")
+	writeFile(t, user, "package pkg\n\ntype User struct{}\n")
+	writeFile(t, added, "package pkg\n")
+	if err := os.Remove(removed); err != nil {
+		t.Fatal(err)
+	}
+	// 監視対象外のファイル・隠しディレクトリの変更は無視する
+	writeFile(t, filepath.Join(dir, "pkg", "user_test.go"), "package pkg\n")
+	writeFile(t, filepath.Join(dir, "README.md"), "# readme\n")
+	writeFile(t, filepath.Join(dir, ".depsee", "cache", "x.go"), "package x\n")
+
+	changed, err := poller.Poll()
+	if err != nil {
+		t.Fatalf("Poll() error: %v", err)
+	}
+	want := []string{added, removed, user}
+	if !reflect.DeepEqual(changed, want) {
+		t.Errorf("Poll() = %v, expected %v", changed, want)
+	}
+	if changed, _ := poller.Poll(); len(changed) != 0 {
+		t.Errorf("検知済みの変更 %v が再度検知されました", changed)
+	}
+}
+
+func TestPoller_Run(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	writeFile(t, path, "package main\n")
+
+	poller, err := NewPoller(dir, 5*time.Millisecond, false)
+	if err != nil {
+		t.Fatalf("NewPoller() error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var got []string
+	writeFile(t, path, "package main\n\nfunc main() {}\n")
+	err = poller.Run(ctx, func(changed []string) {
+		got = changed
+		cancel()
+	})
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{path}) {
+		t.Errorf("Run() reported %v, expected [%s]", got, path)
+	}
+}
+
+func TestPoller_Roots(t *testing.T) {
+	dir, member := t.TempDir(), t.TempDir()
+	path := filepath.Join(member, "lib.go")
+	writeFile(t, filepath.Join(dir, "main.go"), "package main\n")
+	writeFile(t, path, "package lib\n")
+
+	// 存在しないディレクトリ（削除されたワークスペースのメンバー等）は無視する
+	missing := filepath.Join(t.TempDir(), "missing")
+	poller, err := NewPollerWithRoots(func() []string { return []string{dir, member, missing} }, time.Millisecond, false)
+	if err != nil {
+		t.Fatalf("NewPollerWithRoots() error: %v", err)
+	}
+
+	// 最初のディレクトリ以外の変更も検知する
+	writeFile(t, path, "package lib\n\ntype Lib struct{}\n")
+	changed, err := poller.Poll()
+	if err != nil {
+		t.Fatalf("Poll() error: %v", err)
+	}
+	if !reflect.DeepEqual(changed, []string{path}) {
+		t.Errorf("Poll() = %v, expected [%s]", changed, path)
+	}
+
+	// 最初のディレクトリが存在しない場合はエラーとする
+	if _, err := NewPollerWithRoots(func() []string { return []string{missing, dir} }, time.Millisecond, false); err == nil {
+		t.Error("NewPollerWithRoots() should return an error for non-existent directory")
+	}
+}
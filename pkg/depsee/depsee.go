@@ -3,13 +3,17 @@ package depsee
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/harakeishi/depsee/internal/analyzer"
 	"github.com/harakeishi/depsee/internal/analyzer/stability"
+	"github.com/harakeishi/depsee/internal/cache"
 	"github.com/harakeishi/depsee/internal/graph"
 	"github.com/harakeishi/depsee/internal/logger"
 	"github.com/harakeishi/depsee/internal/output"
+	"github.com/harakeishi/depsee/internal/types"
 )
 
 // Config は解析の設定を表します
@@ -20,10 +24,39 @@ type Config struct {
 	TargetPackages         string
 	ExcludePackages        string
 	ExcludeDirs            string
+	TypeCheck              bool
+	CollapseMethods        bool
+	IncludeGlobals         bool
+	IncludeExternal        bool   // サードパーティモジュールへの参照をモジュールごとの外部依存ノードとして表示する
+	IncludeStdlib          bool   // 標準ライブラリのパッケージへの参照も外部依存ノードとして表示する（IncludeExternalを含む）
+	ExternalInstability    bool   // 外部依存ノードを不安定度・SDP違反の算出に含める
+	IncludeTests           bool   // テストファイルと外部テストパッケージを別の層として解析し、テストから参照されていないノードを報告する
+	GOOS                   string // ビルド制約の評価に使うGOOS（空の場合は環境変数GOOSまたはホストの値）
+	GOARCH                 string // ビルド制約の評価に使うGOARCH（空の場合は環境変数GOARCHまたはホストの値）
+	Tags                   string // ビルド制約の評価で有効とする追加のビルドタグ（カンマ区切り）
+	Platforms              string // 解析するプラットフォームのGOOS/GOARCHをカンマ区切りで指定し、一部のプラットフォームにのみ存在するノードを区別する
+	Generated              string // 生成コードの扱い（GeneratedKeep・GeneratedExclude・GeneratedCollapse・GeneratedMute。空の場合はGeneratedKeep）
+	GoroutineNodes         bool   // ループを含むgoroutineの関数リテラルを起動元の関数とは別のノードとして表示する
+	Weighted               bool
+	Weights                string
+	Workers                int
+	NoCache                bool
+	CacheDir               string // 解析キャッシュの保存先（空の場合はユーザーのキャッシュディレクトリのdepsee配下）
+	Output                 string // Mermaid相関図の出力先ファイル（.mdの場合はコードブロックで囲む。空の場合は標準出力のみ）
+	EdgePositions          bool   // 各エッジの出現位置を出力する（Mermaid相関図ではコメント、Markdownの出力ファイルでは相関図の下の表）
+	Version                string
 	LogLevel               string
 	LogFormat              string
 }
 
+// 生成コード（"// Code generated ... DO NOT EDIT." のヘッダを持つファイル）の扱い
+const (
+	GeneratedKeep     = "keep"     // 手書きのコードと同様に表示する
+	GeneratedExclude  = "exclude"  // 解析対象から除外する
+	GeneratedCollapse = "collapse" // パッケージごとに1つのノードにまとめる
+	GeneratedMute     = "mute"     // Mermaid相関図で控えめなスタイルで表示する
+)
+
 // Depsee はメインのアプリケーションロジックを表します
 type Depsee struct {
 	analyzer          analyzer.Analyzer
@@ -74,19 +107,42 @@ func (d *Depsee) Analyze(config Config) error {
 
 	d.logger.Info("解析開始", "target_dir", config.TargetDir)
 
-	// 解析実行
-	var err error
-
 	// フィルタリング設定をパース :FIXME: cobraの機能でパースできるか確認する
 	targetPackagesList := parseTargetPackages(config.TargetPackages)
 	excludePackagesList := parseTargetPackages(config.ExcludePackages)
 	excludeDirsList := parseTargetPackages(config.ExcludeDirs)
+	platforms, err := analyzer.ParsePlatforms(config.Platforms)
+	if err != nil {
+		return fmt.Errorf("プラットフォームの解析失敗: %w", err)
+	}
+	switch config.Generated {
+	case "", GeneratedKeep, GeneratedExclude, GeneratedCollapse, GeneratedMute:
+	default:
+		return fmt.Errorf("生成コードの扱いが不正です（%s・%s・%s・%sのいずれかを指定してください）: %s",
+			GeneratedKeep, GeneratedExclude, GeneratedCollapse, GeneratedMute, config.Generated)
+	}
 	filters := analyzer.Filters{
 		TargetPackages:  targetPackagesList,
 		ExcludePackages: excludePackagesList,
 		ExcludeDirs:     excludeDirsList,
 	}
 	d.analyzer.SetFilters(filters)
+	d.analyzer.SetOptions(analyzer.Options{
+		TypeCheck:        config.TypeCheck,
+		IncludeGlobals:   config.IncludeGlobals,
+		IncludeExternal:  config.IncludeExternal || config.IncludeStdlib,
+		IncludeStdlib:    config.IncludeStdlib,
+		IncludeTests:     config.IncludeTests,
+		GOOS:             config.GOOS,
+		GOARCH:           config.GOARCH,
+		BuildTags:        parseTargetPackages(config.Tags),
+		Platforms:        platforms,
+		ExcludeGenerated: config.Generated == GeneratedExclude,
+		GoroutineNodes:   config.GoroutineNodes,
+		Workers:          config.Workers,
+		CacheDir:         cacheDir(config),
+		Version:          config.Version,
+	})
 	
 	// ファイルリストアップ
 	if err := d.analyzer.ListTartgetFiles(config.TargetDir); err != nil {
@@ -114,11 +170,32 @@ func (d *Depsee) Analyze(config Config) error {
 		d.logger.Info("通常の依存グラフ構築", "include_package_deps", config.IncludePackageDeps)
 		dependencyGraph = d.grapher.BuildDependencyGraph(result)
 	}
+	if config.CollapseMethods {
+		// メソッドをレシーバ型に畳み込んだ粗い粒度のグラフにする
+		dependencyGraph.CollapseMethods()
+	}
+	if config.Generated == GeneratedCollapse {
+		// 生成コードのノードをパッケージごとに1つのノードにまとめる
+		dependencyGraph.CollapseGenerated()
+	}
 	d.displayGraph(dependencyGraph)
 
 	// 不安定度算出
+	if config.Weighted || config.Weights != "" {
+		// 依存の種類ごとの重みと出現回数による重み付き不安定度も算出する
+		weights, err := stability.ParseWeights(config.Weights)
+		if err != nil {
+			return fmt.Errorf("重みの解析失敗: %w", err)
+		}
+		d.stabilityAnalyzer.SetWeights(weights)
+	}
+	// 外部依存ノードは指定された場合のみ不安定度の算出に含める
+	d.stabilityAnalyzer.SetIncludeExternal(config.ExternalInstability)
 	stabilityResult := d.stabilityAnalyzer.Analyze(dependencyGraph)
 	d.displayStability(stabilityResult)
+	if config.IncludeTests {
+		d.displayUntested(dependencyGraph)
+	}
 
 	// SDP違反の表示
 	if len(stabilityResult.SDPViolations) > 0 {
@@ -129,26 +206,112 @@ func (d *Depsee) Analyze(config Config) error {
 				"from_instability", violation.FromInstability,
 				"to", violation.To,
 				"to_instability", violation.ToInstability,
-				"severity", violation.ViolationSeverity)
+				"severity", violation.ViolationSeverity,
+				"positions", violation.Positions)
 		}
 	} else {
 		d.logger.Info("SDP違反なし")
 	}
 
 	// Mermaid記法の相関図出力
-	var mermaid string
-	if config.HighlightSDPViolations {
-		// SDP違反ハイライト機能を使用
-		mermaid = d.outputter.GenerateMermaidWithOptions(dependencyGraph, stabilityResult, true)
-	} else {
-		mermaid = d.outputter.GenerateMermaid(dependencyGraph, stabilityResult)
+	d.outputter.SetMuteGenerated(config.Generated == GeneratedMute)
+	generate := func(edgePositions bool) string {
+		d.outputter.SetEdgePositions(edgePositions)
+		if config.HighlightSDPViolations {
+			// SDP違反ハイライト機能を使用
+			return d.outputter.GenerateMermaidWithOptions(dependencyGraph, stabilityResult, true)
+		}
+		return d.outputter.GenerateMermaid(dependencyGraph, stabilityResult)
 	}
+	mermaid := generate(config.EdgePositions)
 	fmt.Println("[info] Mermaid相関図:")
 	fmt.Println(mermaid)
 
+	if config.Output != "" {
+		// Markdownの出力ファイルでは相関図を簡潔に保ち、出現位置は相関図の下の表にまとめる
+		content, positions := mermaid, ""
+		if config.EdgePositions && isMarkdown(config.Output) {
+			content = generate(false)
+			positions = output.GenerateEdgePositions(dependencyGraph)
+		}
+		if err := writeOutput(config.Output, content, positions); err != nil {
+			d.logger.Error("出力ファイル書き込み失敗", "error", err, "output", config.Output)
+			return fmt.Errorf("出力ファイル書き込み失敗: %w", err)
+		}
+		d.logger.Info("出力ファイル書き込み完了", "output", config.Output)
+	}
+
 	return nil
 }
 
+// writeOutput はMermaid相関図をファイルに書き込みます。
+// 拡張子が.mdまたは.markdownの場合はMarkdownプレビューで表示できるようにmermaidコードブロックで囲み、
+// positions（エッジの出現位置の表）が空でなければコードブロックの下に続けます。
+// プレビュー中のファイルが途中まで書かれた状態にならないよう、一時ファイルに書き込んでから置き換えます。
+// 内容が変わらない場合は、監視中の再解析でプレビューが再読み込みされないようにファイルを書き換えません。
+func writeOutput(path string, mermaid string, positions string) error {
+	content := mermaid
+	if isMarkdown(path) {
+		content = "```mermaid\n" + strings.TrimRight(mermaid, "\n") + "\n```\n"
+		if positions != "" {
+			content += "\n" + positions
+		}
+	}
+	if existing, err := os.ReadFile(path); err == nil && string(existing) == content {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// isMarkdown は出力ファイルがMarkdown（拡張子が.mdまたは.markdown）かどうかを返します
+func isMarkdown(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// cacheDir は解析キャッシュの保存先を返します（キャッシュを使用しない場合は空文字）
+// 保存先が指定されていない場合は、解析対象ディレクトリを汚さないようにユーザーのキャッシュディレクトリ（os.UserCacheDir）の
+// depsee配下に、解析対象ディレクトリの絶対パスのハッシュで分けて保存します
+func cacheDir(config Config) string {
+	if config.NoCache {
+		return ""
+	}
+	if config.CacheDir != "" {
+		return config.CacheDir
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		logger.Warn("キャッシュの保存先が見つからないため、キャッシュを使用しません", "error", err)
+		return ""
+	}
+	target := config.TargetDir
+	if abs, err := filepath.Abs(target); err == nil {
+		target = abs
+	}
+	return filepath.Join(base, "depsee", cache.Hash(target))
+}
+
 // parseTargetPackages はカンマ区切りの文字列をパッケージ名のスライスに変換します
 func parseTargetPackages(targetPackages string) []string {
 	if targetPackages == "" {
@@ -171,29 +334,94 @@ func parseTargetPackages(targetPackages string) []string {
 // displayGraph は依存グラフを表示
 func (d *Depsee) displayGraph(g *graph.DependencyGraph) {
 	fmt.Println("[info] 依存グラフ ノード:")
-	for _, n := range g.Nodes {
-		fmt.Printf("  - %s (%s)\n", n.ID, n.Name)
+	for _, id := range g.SortedNodeIDs() {
+		n := g.Nodes[id]
+		line := fmt.Sprintf("  - %s (%s)", n.ID, n.Name)
+		if len(n.Platforms) > 0 {
+			line += fmt.Sprintf(" [%s]", strings.Join(n.Platforms, ", "))
+		}
+		if n.Generated {
+			line += " [generated]"
+		}
+		if n.SpawnedAt != "" {
+			line += " [spawned at " + n.SpawnedAt + "]"
+		}
+		fmt.Println(line)
 	}
 
 	fmt.Println("[info] 依存グラフ エッジ:")
-	for from, tos := range g.Edges {
-		for to := range tos {
-			fmt.Printf("  %s --> %s\n", from, to)
+	for _, edge := range g.SortedEdges() {
+		fmt.Printf("  %s --> %s (%s)\n", edge.From, edge.To, edge.Label())
+		for _, pos := range edge.Positions {
+			fmt.Printf("      %s\n", pos)
 		}
 	}
 }
 
+// displayUntested はテストから直接参照されていない本番コードのノードを表示
+func (d *Depsee) displayUntested(g *graph.DependencyGraph) {
+	production := 0
+	for _, n := range g.Nodes {
+		if !n.Test && !n.IsExternal() && n.Kind != graph.NodePackage {
+			production++
+		}
+	}
+	untested := g.UntestedNodes()
+	fmt.Printf("[info] テストから参照されていないノード (%d/%d):\n", len(untested), production)
+	for _, id := range untested {
+		fmt.Printf("  - %s\n", id)
+	}
+}
+
 // displayStability は不安定度を表示
 func (d *Depsee) displayStability(stabilityResult *stability.Result) {
+	weighted := stabilityResult.Weights != nil
+	if weighted {
+		fmt.Printf("[info] 重み: %s\n", stabilityResult.Weights)
+	}
+
 	fmt.Println("[info] ノード不安定度:")
-	for id, s := range stabilityResult.NodeStabilities {
-		fmt.Printf("  %s: 依存数=%d, 非依存数=%d, 不安定度=%.2f\n", id, s.OutDegree, s.InDegree, s.Instability)
+	nodeIDs := make([]types.NodeID, 0, len(stabilityResult.NodeStabilities))
+	for id := range stabilityResult.NodeStabilities {
+		nodeIDs = append(nodeIDs, id)
+	}
+	sort.Slice(nodeIDs, func(i, j int) bool { return nodeIDs[i] < nodeIDs[j] })
+	for _, id := range nodeIDs {
+		s := stabilityResult.NodeStabilities[id]
+		fmt.Printf("  %s: 依存数=%d, 非依存数=%d, 不安定度=%.2f", id, s.OutDegree, s.InDegree, s.Instability)
+		if weighted {
+			fmt.Printf(" / 重み付き依存数=%.2f, 重み付き非依存数=%.2f, 重み付き不安定度=%.2f", s.WeightedOutDegree, s.WeightedInDegree, s.WeightedInstability)
+		}
+		fmt.Println()
 	}
 
 	if len(stabilityResult.PackageStabilities) > 0 {
 		fmt.Println("[info] パッケージ不安定度:")
-		for pkg, s := range stabilityResult.PackageStabilities {
-			fmt.Printf("  %s: 依存数=%d, 非依存数=%d, 不安定度=%.2f\n", pkg, s.OutDegree, s.InDegree, s.Instability)
+		packages := make([]string, 0, len(stabilityResult.PackageStabilities))
+		for pkg := range stabilityResult.PackageStabilities {
+			packages = append(packages, pkg)
+		}
+		sort.Strings(packages)
+		for _, pkg := range packages {
+			s := stabilityResult.PackageStabilities[pkg]
+			fmt.Printf("  %s: 依存数=%d, 非依存数=%d, 不安定度=%.2f", pkg, s.OutDegree, s.InDegree, s.Instability)
+			if weighted {
+				fmt.Printf(" / 重み付き依存数=%.2f, 重み付き非依存数=%.2f, 重み付き不安定度=%.2f", s.WeightedOutDegree, s.WeightedInDegree, s.WeightedInstability)
+			}
+			fmt.Println()
+		}
+	}
+
+	if len(stabilityResult.ExternalFanIns) > 0 {
+		fmt.Println("[info] 外部依存 被依存数:")
+		externalIDs := make([]types.NodeID, 0, len(stabilityResult.ExternalFanIns))
+		for id := range stabilityResult.ExternalFanIns {
+			externalIDs = append(externalIDs, id)
+		}
+		sort.Slice(externalIDs, func(i, j int) bool { return externalIDs[i] < externalIDs[j] })
+		for _, id := range externalIDs {
+			f := stabilityResult.ExternalFanIns[id]
+			fmt.Printf("  %s: 被依存数=%d, パッケージ数=%d, 参照数=%d\n", f.Path, f.InDegree, f.PackageInDegree, f.Count)
 		}
 	}
 }
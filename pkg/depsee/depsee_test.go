@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/harakeishi/depsee/internal/analyzer"
 	"github.com/harakeishi/depsee/internal/analyzer/stability"
@@ -51,6 +52,19 @@ func TestAnalyze_NonExistentDirectory(t *testing.T) {
 	}
 }
 
+func TestAnalyze_InvalidGeneratedMode(t *testing.T) {
+	app := New()
+	config := Config{
+		TargetDir: t.TempDir(),
+		Generated: "hide",
+		NoCache:   true,
+	}
+
+	if err := app.Analyze(config); err == nil {
+		t.Error("Analyze() should return an error for unknown generated mode")
+	}
+}
+
 func TestAnalyze_ValidDirectory(t *testing.T) {
 	// テストデータディレクトリのパス
 	testDataDir := "../../testdata/sample"
@@ -129,3 +143,104 @@ func TestConfig(t *testing.T) {
 		t.Errorf("Expected LogFormat to be 'json', got '%s'", config.LogFormat)
 	}
 }
+
+func TestWriteOutput(t *testing.T) {
+	dir := t.TempDir()
+	mermaid := "graph TD\n    A --> B\n"
+
+	tests := []struct {
+		name      string
+		file      string
+		positions string
+		want      string
+	}{
+		{"mermaid", "deps.mmd", "", mermaid},
+		{"markdown", "deps.md", "", "```mermaid\ngraph TD\n    A --> B\n```\n"},
+		{"markdown upper case", "DEPS.MARKDOWN", "", "```mermaid\ngraph TD\n    A --> B\n```\n"},
+		{"markdown with positions", "positions.md", "| A | B |\n", "```mermaid\ngraph TD\n    A --> B\n```\n\n| A | B |\n"},
+		{"mermaid ignores positions", "positions.mmd", "| A | B |\n", mermaid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			if err := writeOutput(path, mermaid, tt.positions); err != nil {
+				t.Fatalf("writeOutput() error: %v", err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("writeOutput() wrote %q, expected %q", got, tt.want)
+			}
+		})
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(tests) {
+		t.Errorf("一時ファイルが残っています: %v", entries)
+	}
+}
+
+func TestWriteOutput_Unchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deps.md")
+	mermaid := "graph TD\n    A --> B\n"
+	if err := writeOutput(path, mermaid, ""); err != nil {
+		t.Fatalf("writeOutput() error: %v", err)
+	}
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// 内容が変わらない場合はファイルを書き換えない
+	if err := writeOutput(path, mermaid, ""); err != nil {
+		t.Fatalf("writeOutput() error: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || !info.ModTime().Equal(old) {
+		t.Error("内容が変わらないのにファイルが書き換えられています")
+	}
+
+	if err := writeOutput(path, "graph TD\n    A --> C\n", ""); err != nil {
+		t.Fatalf("writeOutput() error: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.ModTime().Equal(old) {
+		t.Error("内容が変わったのにファイルが書き換えられていません")
+	}
+}
+
+func TestCacheDir(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", base)
+	t.Setenv("LocalAppData", base)
+	userCache, err := os.UserCacheDir()
+	if err != nil {
+		t.Skipf("ユーザーのキャッシュディレクトリがありません: %v", err)
+	}
+
+	if dir := cacheDir(Config{TargetDir: ".", NoCache: true}); dir != "" {
+		t.Errorf("--no-cache 指定時の保存先 = %q, expected empty", dir)
+	}
+	if dir := cacheDir(Config{TargetDir: ".", CacheDir: "custom"}); dir != "custom" {
+		t.Errorf("指定した保存先 = %q, expected custom", dir)
+	}
+
+	// 保存先を指定しない場合は解析対象ディレクトリの外に、解析対象ごとに分けて保存する
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := cacheDir(Config{TargetDir: "."})
+	if filepath.Dir(dir) != filepath.Join(userCache, "depsee") {
+		t.Errorf("既定の保存先 = %q, expected under %q", dir, filepath.Join(userCache, "depsee"))
+	}
+	if abs := cacheDir(Config{TargetDir: wd}); abs != dir {
+		t.Errorf("相対パスと絶対パスで保存先が異なります: %q, %q", dir, abs)
+	}
+	if other := cacheDir(Config{TargetDir: filepath.Join(wd, "testdata")}); other == dir {
+		t.Error("解析対象ディレクトリが異なっても保存先が同じです")
+	}
+}
@@ -0,0 +1,59 @@
+package depsee
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/harakeishi/depsee/internal/analyzer"
+	"github.com/harakeishi/depsee/internal/watch"
+)
+
+// DefaultWatchInterval は監視モードでファイルの変更を確認する既定の間隔です
+const DefaultWatchInterval = time.Second
+
+// Watch は解析を実行した後、解析対象ディレクトリをinterval間隔で走査し、
+// .goファイル（IncludeTests指定時はテストファイルを含む）やgo.mod・go.workが変更されるたびに再解析して相関図と出力ファイルを更新します。
+// ctxがキャンセルされるまで戻りません。
+// 同じ解析器を使い続けるため変更されていないファイルは再パースされず、
+// 解析キャッシュにより変更の影響を受けるパッケージだけが再解析されます。
+// キャッシュを使用しない設定の場合は、監視中だけ有効な一時キャッシュを使用します。
+func (d *Depsee) Watch(ctx context.Context, config Config, interval time.Duration) error {
+	if _, err := os.Stat(config.TargetDir); err != nil {
+		return fmt.Errorf("ディレクトリが存在しません: %s", config.TargetDir)
+	}
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	if config.NoCache {
+		dir, err := os.MkdirTemp("", "depsee-watch-")
+		if err != nil {
+			return fmt.Errorf("一時キャッシュ作成失敗: %w", err)
+		}
+		defer os.RemoveAll(dir)
+		config.NoCache = false
+		config.CacheDir = dir
+	}
+
+	// 初回の解析中に行われた変更も検知できるよう、解析前の状態を比較対象にする
+	// 解析対象と同じく、解析対象ディレクトリの外にあるgo.workのメンバーも監視する
+	roots := func() []string { return analyzer.TargetRoots(config.TargetDir) }
+	poller, err := watch.NewPollerWithRoots(roots, interval, config.IncludeTests)
+	if err != nil {
+		return fmt.Errorf("ディレクトリ走査失敗: %w", err)
+	}
+
+	// 編集途中のコードで解析に失敗しても監視は続ける
+	if err := d.Analyze(config); err != nil {
+		d.logger.Error("解析失敗", "error", err, "target_dir", config.TargetDir)
+	}
+	d.logger.Info("ファイルの変更を監視中", "target_dir", config.TargetDir, "interval", interval)
+
+	return poller.Run(ctx, func(changed []string) {
+		d.logger.Info("ファイルの変更を検知", "files", changed)
+		if err := d.Analyze(config); err != nil {
+			d.logger.Error("再解析失敗", "error", err, "target_dir", config.TargetDir)
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package depsee
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitForOutput は出力ファイルにwantが含まれるまで待ちます
+func waitForOutput(t *testing.T, path, want string) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if data, err := os.ReadFile(path); err == nil && strings.Contains(string(data), want) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("%s に %q が出力されませんでした", path, want)
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(t.TempDir(), "deps.md")
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.21\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "user.go"), []byte("package app\n\ntype User struct{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- New().Watch(ctx, Config{TargetDir: dir, Output: output, NoCache: true}, 10*time.Millisecond)
+	}()
+
+	waitForOutput(t, output, "User")
+
+	// ファイルを追加すると再解析して出力を更新する
+	if err := os.WriteFile(filepath.Join(dir, "order.go"), []byte("package app\n\ntype Order struct {\n\tUser *User\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitForOutput(t, output, "Order")
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Watch() error: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Watch() がキャンセル後に終了しません")
+	}
+	// キャッシュを使用しない設定では解析対象ディレクトリにキャッシュを作成しない
+	if _, err := os.Stat(filepath.Join(dir, ".depsee")); !os.IsNotExist(err) {
+		t.Error("--no-cache 指定時に解析対象ディレクトリへキャッシュが作成されています")
+	}
+}
+
+func TestWatch_WorkspaceMember(t *testing.T) {
+	base := t.TempDir()
+	app, lib := filepath.Join(base, "app"), filepath.Join(base, "lib")
+	output := filepath.Join(t.TempDir(), "deps.md")
+	files := map[string]string{
+		filepath.Join(app, "go.work"): "go 1.21\n\nuse (\n\t.\n\t../lib\n)\n",
+		filepath.Join(app, "go.mod"):  "module example.com/app\n\ngo 1.21\n",
+		filepath.Join(app, "app.go"):  "package app\n\ntype App struct{}\n",
+		filepath.Join(lib, "go.mod"):  "module example.com/lib\n\ngo 1.21\n",
+		filepath.Join(lib, "lib.go"):  "package lib\n\ntype Lib struct{}\n",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- New().Watch(ctx, Config{TargetDir: app, Output: output, NoCache: true}, 10*time.Millisecond)
+	}()
+
+	waitForOutput(t, output, "Lib")
+
+	// 解析対象ディレクトリの外にあるワークスペースのメンバーの変更も検知する
+	if err := os.WriteFile(filepath.Join(lib, "extra.go"), []byte("package lib\n\ntype Extra struct{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitForOutput(t, output, "Extra")
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Watch() error: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Watch() がキャンセル後に終了しません")
+	}
+}
+
+func TestWatch_NonExistentDirectory(t *testing.T) {
+	err := New().Watch(context.Background(), Config{TargetDir: "/non/existent/directory"}, time.Millisecond)
+	if err == nil {
+		t.Error("Watch() should return an error for non-existent directory")
+	}
+}